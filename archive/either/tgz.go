@@ -0,0 +1,47 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	Common "github.com/ibm-hyper-protect/contract-go/common"
+	T "github.com/ibm-hyper-protect/contract-go/tar"
+	TE "github.com/ibm-hyper-protect/contract-go/tar/either"
+)
+
+// gunzip decompresses gzip encoded data
+func gunzip(data []byte) E.Either[error, []byte] {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return E.Left[[]byte](err)
+	}
+	defer gz.Close()
+	return E.TryCatchError(io.ReadAll(gz))
+}
+
+// Base64TgzToFileList decodes a base64 encoded tgz archive, e.g. the `archive` field of a workload, into a file list
+func Base64TgzToFileList(archive string) E.Either[error, T.FileList] {
+	return F.Pipe3(
+		archive,
+		Common.Base64DecodeE,
+		E.Chain(gunzip),
+		E.Chain(TE.Unmarshal),
+	)
+}
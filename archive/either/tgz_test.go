@@ -0,0 +1,40 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	AIOE "github.com/ibm-hyper-protect/contract-go/archive/ioeither"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase64TgzToFileList(t *testing.T) {
+	files, err := E.UnwrapError(F.Pipe1(
+		AIOE.Base64TarFolder("../../samples/hello-world"),
+		IOE.ChainEitherK(Base64TgzToFileList),
+	)())
+	require.NoError(t, err)
+
+	assert.Contains(t, files, "docker-compose.yml")
+}
+
+func TestBase64TgzToFileListInvalid(t *testing.T) {
+	assert.True(t, E.IsLeft(Base64TgzToFileList("not an archive")))
+}
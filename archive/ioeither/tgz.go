@@ -16,6 +16,7 @@ package ioeither
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"io"
 	"os"
@@ -156,6 +157,10 @@ func TarFolder[W io.Writer](src string) func(IOE.IOEither[error, W]) IOE.IOEithe
 				header := writeRel(tw)
 
 				walkFunc := func(file string, fi os.FileInfo, e error) error {
+					// the file cannot be accessed
+					if e != nil {
+						return e
+					}
 					// header
 					return E.ToError(F.Pipe1(
 						header(file, fi),
@@ -178,3 +183,13 @@ func TarFolder[W io.Writer](src string) func(IOE.IOEither[error, W]) IOE.IOEithe
 		)
 	}
 }
+
+// Base64TarFolder creates the base64 encoded tgz archive of a folder, e.g. for the `archive` field of a workload
+func Base64TarFolder(src string) IOE.IOEither[error, string] {
+	return F.Pipe3(
+		CreateBase64Writer,
+		TarFolder[*Archive.Base64Writer](src),
+		IOE.ChainEitherK((*Archive.Base64Writer).Close),
+		IOE.Map[error]((*bytes.Buffer).String),
+	)
+}
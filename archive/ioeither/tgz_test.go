@@ -34,3 +34,11 @@ func TestTgz(t *testing.T) {
 
 	assert.True(t, E.IsRight(bufE))
 }
+
+func TestBase64TarFolder(t *testing.T) {
+	archiveE := Base64TarFolder("../../samples/nginx-golang")()
+	assert.True(t, E.IsRight(archiveE))
+
+	missingE := Base64TarFolder("../../samples/does-not-exist")()
+	assert.True(t, E.IsLeft(missingE))
+}
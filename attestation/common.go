@@ -0,0 +1,54 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+const (
+	// names of the checksums in the attestation record that refer to the contract
+	ChecksumUserData             = "cidata/user-data"
+	ChecksumWorkload             = "contract:workload"
+	ChecksumEnv                  = "contract:env"
+	ChecksumAttestationPublicKey = "contract:attestationPublicKey"
+	ChecksumEnvWorkloadSignature = "contract:envWorkloadSignature"
+
+	// status of a single checksum comparison
+	StatusMatch    = "match"
+	StatusMismatch = "mismatch"
+	StatusMissing  = "missing"
+)
+
+type (
+	// Record is the parsed content of the attestation record (`se-checksums.txt`)
+	Record struct {
+		// Header contains the lines that are not checksums, e.g. the image version and the machine type
+		Header []string `json:"header" yaml:"header"`
+		// Checksums maps the name of an artifact to its hex encoded sha256 checksum
+		Checksums map[string]string `json:"checksums" yaml:"checksums"`
+	}
+
+	// Comparison is the result of comparing an expected checksum with the checksum in the attestation record
+	Comparison struct {
+		Name     string `json:"name" yaml:"name"`
+		Expected string `json:"expected" yaml:"expected"`
+		Actual   string `json:"actual,omitempty" yaml:"actual,omitempty"`
+		Status   string `json:"status" yaml:"status"`
+	}
+
+	// Verification is the result of verifying an attestation record against a contract
+	Verification struct {
+		Valid       bool         `json:"valid" yaml:"valid"`
+		Header      []string     `json:"header" yaml:"header"`
+		Comparisons []Comparison `json:"comparisons" yaml:"comparisons"`
+	}
+)
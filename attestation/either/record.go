@@ -0,0 +1,145 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	O "github.com/IBM/fp-go/option"
+	P "github.com/IBM/fp-go/predicate"
+	R "github.com/IBM/fp-go/record"
+	S "github.com/IBM/fp-go/string"
+	AT "github.com/ibm-hyper-protect/contract-go/attestation"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+)
+
+var (
+	// checksumLine matches a line of the form `<sha256> <name>`
+	checksumLine = regexp.MustCompile(`^([0-9a-fA-F]{64})\s+(\S+)$`)
+
+	// sectionChecksums maps the sections of an encrypted contract to the names of their checksums
+	sectionChecksums = map[string]string{
+		SC.KeyWorkload:             AT.ChecksumWorkload,
+		SC.KeyEnv:                  AT.ChecksumEnv,
+		SC.KeyAttestationPublicKey: AT.ChecksumAttestationPublicKey,
+		SC.KeyEnvWorkloadSignature: AT.ChecksumEnvWorkloadSignature,
+	}
+
+	// parseEncryptedContract parses the user data into its sections
+	parseEncryptedContract = Y.Parse[SC.EncryptedContract]
+)
+
+// Sha256 computes the hex encoded sha256 checksum of some data
+func Sha256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseRecord parses the plaintext of an attestation record. Lines of the form `<sha256> <name>` are checksums,
+// all other non empty lines are kept as header information.
+func ParseRecord(data []byte) E.Either[error, *AT.Record] {
+	record := &AT.Record{
+		Header:    A.Empty[string](),
+		Checksums: make(map[string]string),
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if match := checksumLine.FindStringSubmatch(line); match != nil {
+			record.Checksums[match[2]] = strings.ToLower(match[1])
+		} else {
+			record.Header = append(record.Header, line)
+		}
+	}
+	if len(record.Checksums) == 0 {
+		return E.Left[*AT.Record](fmt.Errorf("the attestation record does not contain any checksums"))
+	}
+	return E.Of[error](record)
+}
+
+// expectedFromContract computes the checksums of the sections of the encrypted contract
+func expectedFromContract(contract SC.EncryptedContract) map[string]string {
+	expected := make(map[string]string)
+	for section, name := range sectionChecksums {
+		if value, ok := contract[section]; ok {
+			expected[name] = Sha256([]byte(value))
+		}
+	}
+	return expected
+}
+
+// ExpectedChecksums computes the checksums that HPCR records for a contract, given the user data that has been
+// passed to the instance. The sections are hashed in their encrypted form, exactly as they appear in the user data.
+func ExpectedChecksums(userData []byte) E.Either[error, map[string]string] {
+	return F.Pipe1(
+		parseEncryptedContract(userData),
+		E.Map[error](func(contract SC.EncryptedContract) map[string]string {
+			expected := expectedFromContract(contract)
+			expected[AT.ChecksumUserData] = Sha256(userData)
+			return expected
+		}),
+	)
+}
+
+// compare compares the expected checksum with the record
+func compare(record *AT.Record) func(name, expected string) AT.Comparison {
+	return func(name, expected string) AT.Comparison {
+		return F.Pipe2(
+			record.Checksums,
+			R.Lookup[string](name),
+			O.Fold(
+				F.Constant(AT.Comparison{Name: name, Expected: expected, Status: AT.StatusMissing}),
+				func(actual string) AT.Comparison {
+					status := AT.StatusMismatch
+					if actual == expected {
+						status = AT.StatusMatch
+					}
+					return AT.Comparison{Name: name, Expected: expected, Actual: actual, Status: status}
+				},
+			),
+		)
+	}
+}
+
+// isMatch tests if a comparison succeeded
+func isMatch(cmp AT.Comparison) bool {
+	return cmp.Status == AT.StatusMatch
+}
+
+// VerifyRecord compares the expected checksums against the attestation record. The verification is valid if all
+// expected checksums are present in the record and match.
+func VerifyRecord(expected map[string]string) func(*AT.Record) *AT.Verification {
+	return func(record *AT.Record) *AT.Verification {
+		comparisons := F.Pipe1(
+			expected,
+			R.CollectOrd[string, AT.Comparison](S.Ord)(compare(record)),
+		)
+		return &AT.Verification{
+			Valid:       !A.Any(P.Not(isMatch))(comparisons),
+			Header:      record.Header,
+			Comparisons: comparisons,
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"fmt"
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	AT "github.com/ibm-hyper-protect/contract-go/attestation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userData = `workload: hyper-protect-basic.d29ya2xvYWQ=.d29ya2xvYWQ=
+env: hyper-protect-basic.ZW52.ZW52
+envWorkloadSignature: c2lnbmF0dXJl
+`
+
+func sampleRecord(workload string) []byte {
+	return []byte(fmt.Sprintf(`23.11.0
+Machine Type/Plant/Serial: 3931/02/8A018
+Image age: 13 days since creation.
+%s  root.tar.gz
+%s  cidata/user-data
+%s  contract:workload
+%s  contract:env
+%s  contract:envWorkloadSignature
+`,
+		Sha256([]byte("root")),
+		Sha256([]byte(userData)),
+		Sha256([]byte(workload)),
+		Sha256([]byte("hyper-protect-basic.ZW52.ZW52")),
+		Sha256([]byte("c2lnbmF0dXJl")),
+	))
+}
+
+func TestParseRecord(t *testing.T) {
+	record, err := E.UnwrapError(ParseRecord(sampleRecord("workload")))
+	require.NoError(t, err)
+
+	assert.Len(t, record.Header, 3)
+	assert.Equal(t, "23.11.0", record.Header[0])
+	assert.Len(t, record.Checksums, 5)
+	assert.Equal(t, Sha256([]byte("root")), record.Checksums["root.tar.gz"])
+
+	_, err = E.UnwrapError(ParseRecord([]byte("no checksums")))
+	assert.Error(t, err)
+}
+
+func TestVerifyRecord(t *testing.T) {
+	expected, err := E.UnwrapError(ExpectedChecksums([]byte(userData)))
+	require.NoError(t, err)
+	assert.Len(t, expected, 4)
+
+	// a matching record
+	record, err := E.UnwrapError(ParseRecord(sampleRecord("hyper-protect-basic.d29ya2xvYWQ=.d29ya2xvYWQ=")))
+	require.NoError(t, err)
+
+	ver := VerifyRecord(expected)(record)
+	assert.True(t, ver.Valid)
+	assert.Len(t, ver.Comparisons, 4)
+
+	// a record for a different workload
+	record, err = E.UnwrapError(ParseRecord(sampleRecord("another workload")))
+	require.NoError(t, err)
+
+	ver = VerifyRecord(expected)(record)
+	assert.False(t, ver.Valid)
+	for _, cmp := range ver.Comparisons {
+		if cmp.Name == AT.ChecksumWorkload {
+			assert.Equal(t, AT.StatusMismatch, cmp.Status)
+		} else {
+			assert.Equal(t, AT.StatusMatch, cmp.Status)
+		}
+	}
+
+	// a checksum that is absent from the record
+	delete(record.Checksums, AT.ChecksumEnv)
+	ver = VerifyRecord(expected)(record)
+	assert.Contains(t, ver.Comparisons, AT.Comparison{Name: AT.ChecksumEnv, Expected: expected[AT.ChecksumEnv], Status: AT.StatusMissing})
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"strings"
+
+	F "github.com/IBM/fp-go/function"
+	I "github.com/IBM/fp-go/identity"
+	IOE "github.com/IBM/fp-go/ioeither"
+	AT "github.com/ibm-hyper-protect/contract-go/attestation"
+	ATE "github.com/ibm-hyper-protect/contract-go/attestation/either"
+	EC "github.com/ibm-hyper-protect/contract-go/encrypt/common"
+)
+
+type (
+	// Decrypter decrypts a `hyper-protect-basic` token
+	Decrypter = func(string) IOE.IOEither[error, []byte]
+)
+
+// DecryptRecord returns a function that decrypts and parses an attestation record (`se-checksums.txt.enc`). Records
+// that are not encrypted are parsed as they are.
+//
+// - decrypter decrypts the record using the attestation private key, it is only evaluated for encrypted records
+func DecryptRecord(decrypter IOE.IOEither[error, Decrypter]) func([]byte) IOE.IOEither[error, *AT.Record] {
+	return func(data []byte) IOE.IOEither[error, *AT.Record] {
+		token := strings.TrimSpace(string(data))
+		if !EC.IsHyperProtectBasic(token) {
+			return IOE.FromEither(ATE.ParseRecord(data))
+		}
+		return F.Pipe2(
+			decrypter,
+			IOE.Chain(I.Ap[IOE.IOEither[error, []byte]](token)),
+			IOE.ChainEitherK(ATE.ParseRecord),
+		)
+	}
+}
+
+// VerifyRecord returns a function that verifies an attestation record against the user data that has been passed
+// to the instance, i.e. the encrypted contract
+//
+// - decrypter decrypts the record using the attestation private key, it is only evaluated for encrypted records
+func VerifyRecord(decrypter IOE.IOEither[error, Decrypter]) func(userData []byte) func([]byte) IOE.IOEither[error, *AT.Verification] {
+	decrypt := DecryptRecord(decrypter)
+	return func(userData []byte) func([]byte) IOE.IOEither[error, *AT.Verification] {
+		expectedIOE := IOE.FromEither(ATE.ExpectedChecksums(userData))
+		return func(record []byte) IOE.IOEither[error, *AT.Verification] {
+			return F.Pipe2(
+				expectedIOE,
+				IOE.Map[error](ATE.VerifyRecord),
+				IOE.Ap[*AT.Verification](decrypt(record)),
+			)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"fmt"
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	IOE "github.com/IBM/fp-go/ioeither"
+	AT "github.com/ibm-hyper-protect/contract-go/attestation"
+	ATE "github.com/ibm-hyper-protect/contract-go/attestation/either"
+	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecryptRecord(t *testing.T) {
+	privKey, err := E.UnwrapError(Encrypt.CryptoPrivateKey())
+	require.NoError(t, err)
+	pubKey, err := E.UnwrapError(Encrypt.CryptoPublicKey(privKey))
+	require.NoError(t, err)
+
+	userData := []byte("workload: hyper-protect-basic.a.b\n")
+	record := []byte(fmt.Sprintf("%s  %s\n%s  %s\n",
+		ATE.Sha256(userData), AT.ChecksumUserData,
+		ATE.Sha256([]byte("hyper-protect-basic.a.b")), AT.ChecksumWorkload,
+	))
+
+	token, err := E.UnwrapError(Encrypt.CryptoEncryptBasic(pubKey)(record)())
+	require.NoError(t, err)
+
+	verify := VerifyRecord(IOE.Of[error](Encrypt.CryptoDecryptBasic(privKey)))(userData)
+
+	// encrypted record
+	ver, err := E.UnwrapError(verify([]byte(token))())
+	require.NoError(t, err)
+	assert.True(t, ver.Valid)
+
+	// plaintext records do not require the decrypter
+	noDecrypter := VerifyRecord(IOE.Left[Decrypter](fmt.Errorf("no key")))(userData)
+	ver, err = E.UnwrapError(noDecrypter(record)())
+	require.NoError(t, err)
+	assert.True(t, ver.Valid)
+
+	// encrypted records do
+	_, err = E.UnwrapError(noDecrypter([]byte(token))())
+	assert.Error(t, err)
+}
@@ -15,7 +15,11 @@
 package certificates
 
 import (
+	"fmt"
+	"time"
+
 	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
 	F "github.com/IBM/fp-go/function"
 	I "github.com/IBM/fp-go/identity"
 	O "github.com/IBM/fp-go/option"
@@ -30,9 +34,65 @@ type (
 	Version = *semver.Version
 	// VersionCert is the pair out of version and certificate
 	VersionCert = T.Tuple2[Version, string]
+
+	// CacheEntry is a certificate stored in the local certificate cache
+	CacheEntry struct {
+		Certificate string    `json:"certificate"` // PEM encoded certificate
+		FetchedAt   time.Time `json:"fetchedAt"`   // time of the download
+		Sha256      string    `json:"sha256"`      // hex encoded SHA256 checksum of the certificate
+		Url         string    `json:"url"`         // source URL of the certificate
+	}
+
+	// CacheIndex is the index of the local certificate cache, keyed by the version string
+	CacheIndex = map[string]CacheEntry
+
+	// CacheConfig controls how the local certificate cache is used when downloading certificates
+	CacheConfig struct {
+		Dir     string        // directory of the cache
+		TTL     time.Duration // maximum age of a cached certificate before it is downloaded again
+		Refresh bool          // download all requested certificates, even if they are fresh
+	}
+
+	// DownloadConfig controls the resilience of certificate downloads
+	DownloadConfig struct {
+		Concurrency    int           // maximum number of parallel downloads
+		Retries        int           // number of retries of a download after a transient error
+		Backoff        time.Duration // delay before the first retry, doubled for every further retry
+		RequestTimeout time.Duration // timeout of a single request, zero for no timeout
+		Timeout        time.Duration // timeout of all downloads including retries, zero for no timeout
+	}
+
+	// DiscoveryConfig controls the discovery of available certificate versions
+	DiscoveryConfig struct {
+		Start     Version // first version to probe
+		MaxMisses int     // number of consecutive missing patch versions after which a minor version is exhausted
+	}
+
+	// DownloadResult is the outcome of the download of the certificate of a single version
+	DownloadResult = T.Tuple2[Version, E.Either[error, string]]
+)
+
+const (
+	// CacheIndexFile is the name of the index file in the cache directory
+	CacheIndexFile = "index.json"
 )
 
 var (
+	// DefaultDiscoveryConfig probes all versions starting at 1.0.0
+	DefaultDiscoveryConfig = DiscoveryConfig{
+		Start:     semver.MustParse("1.0.0"),
+		MaxMisses: 5,
+	}
+
+	// DefaultDownloadConfig is the default configuration for certificate downloads
+	DefaultDownloadConfig = DownloadConfig{
+		Concurrency:    4,
+		Retries:        3,
+		Backoff:        500 * time.Millisecond,
+		RequestTimeout: 30 * time.Second,
+		Timeout:        5 * time.Minute,
+	}
+
 	GetVersion = T.First[Version, string]
 
 	OrdVersion = ord.MakeOrd(Version.Compare, Version.Equal)
@@ -45,11 +105,31 @@ func checkCertContraintPredicate(cstr *semver.Constraints) func(VersionCert) boo
 	return P.ContraMap(GetVersion)(cstr.Check)
 }
 
+// FilterCertsBySpec keeps the versions that match the specification
+func FilterCertsBySpec(spec *semver.Constraints) func(img []VersionCert) []VersionCert {
+	return A.Filter(checkCertContraintPredicate(spec))
+}
+
 // SelectCertBySpec selects the latest version that matches the specification
 func SelectCertBySpec(spec *semver.Constraints) func(img []VersionCert) O.Option[VersionCert] {
 	return F.Flow3(
-		A.Filter(checkCertContraintPredicate(spec)),
+		FilterCertsBySpec(spec),
 		I.Map(SortCertByVersion),
 		A.Head[VersionCert],
 	)
 }
+
+// PartitionDownloads splits download results into the errors of failed downloads and the downloaded certificates
+func PartitionDownloads(results []DownloadResult) T.Tuple2[[]error, []VersionCert] {
+	var errs []error
+	var certs []VersionCert
+	for _, result := range results {
+		cert, err := E.UnwrapError(result.F2)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("version [%s]: %w", result.F1, err))
+			continue
+		}
+		certs = append(certs, T.MakeTuple2(result.F1, cert))
+	}
+	return T.MakeTuple2(errs, certs)
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	M "github.com/IBM/fp-go/monoid"
+	R "github.com/IBM/fp-go/record"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+)
+
+var (
+	// CertificatesFromIndex returns the certificates of a cache index keyed by version, the checksums of all entries
+	// are verified
+	CertificatesFromIndex = E.TraverseRecord[string](F.Flow2(
+		ValidateCacheEntry,
+		E.Map[error](func(entry C.CacheEntry) string {
+			return entry.Certificate
+		}),
+	))
+)
+
+// Checksum returns the hex encoded SHA256 checksum of a certificate
+func Checksum(cert string) string {
+	sum := sha256.Sum256([]byte(cert))
+	return hex.EncodeToString(sum[:])
+}
+
+// MakeCacheEntry creates a cache entry for a certificate downloaded from a URL at the given time
+func MakeCacheEntry(url string, fetchedAt time.Time) func(cert string) C.CacheEntry {
+	return func(cert string) C.CacheEntry {
+		return C.CacheEntry{
+			Certificate: cert,
+			FetchedAt:   fetchedAt.UTC(),
+			Sha256:      Checksum(cert),
+			Url:         url,
+		}
+	}
+}
+
+// IsFresh tests if a cache entry is younger than the TTL at the given time
+func IsFresh(ttl time.Duration, now time.Time) func(C.CacheEntry) bool {
+	return func(entry C.CacheEntry) bool {
+		return now.Sub(entry.FetchedAt) < ttl
+	}
+}
+
+// ValidateCacheEntry verifies that the certificate of a cache entry matches its checksum
+func ValidateCacheEntry(entry C.CacheEntry) E.Either[error, C.CacheEntry] {
+	if Checksum(entry.Certificate) != entry.Sha256 {
+		return E.Left[C.CacheEntry](fmt.Errorf("the checksum of the cached certificate from [%s] does not match", entry.Url))
+	}
+	return E.Of[error](entry)
+}
+
+// MergeCertificates combines certificate maps, entries of later maps take precedence
+func MergeCertificates(certs ...map[string]string) map[string]string {
+	return M.ConcatAll(R.UnionLastMonoid[string, string]())(certs)
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"testing"
+	"time"
+
+	E "github.com/IBM/fp-go/either"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheEntry(t *testing.T) {
+	now := time.Date(2023, 5, 17, 10, 0, 0, 0, time.UTC)
+	entry := MakeCacheEntry("https://example.com/cert", now)("cert")
+
+	assert.True(t, IsFresh(time.Hour, now.Add(time.Minute))(entry))
+	assert.False(t, IsFresh(time.Hour, now.Add(2*time.Hour))(entry))
+
+	assert.Equal(t, E.Of[error](entry), ValidateCacheEntry(entry))
+	entry.Certificate = "tampered"
+	assert.True(t, E.IsLeft(ValidateCacheEntry(entry)))
+}
+
+func TestCertificatesFromIndex(t *testing.T) {
+	now := time.Now()
+	index := C.CacheIndex{
+		"1.0.10": MakeCacheEntry("https://example.com/10", now)("cert 10"),
+		"1.0.11": MakeCacheEntry("https://example.com/11", now)("cert 11"),
+	}
+
+	assert.Equal(t, E.Of[error](map[string]string{"1.0.10": "cert 10", "1.0.11": "cert 11"}), CertificatesFromIndex(index))
+}
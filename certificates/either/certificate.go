@@ -91,6 +91,17 @@ func ResolveUrl(tmp *template.Template) Resolver {
 	}
 }
 
+// ListCertificates parses a map out of string and certificate into the list of certificates, the latest version
+// comes first
+func ListCertificates(certs map[string]string) E.Either[error, []C.VersionCert] {
+	return F.Pipe3(
+		certs,
+		R.ToEntries[string, string],
+		E.TraverseArray(parseEntry),
+		E.Map[error](C.SortCertByVersion),
+	)
+}
+
 // CertificateFromSpec selects the best matching certificate from a map out of string and certificate
 func CertificateFromSpec(spec *semver.Constraints) func(certs map[string]string) E.Either[error, C.VersionCert] {
 	return F.Flow4(
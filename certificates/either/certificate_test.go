@@ -17,6 +17,7 @@ package either
 import (
 	"testing"
 
+	A "github.com/IBM/fp-go/array"
 	E "github.com/IBM/fp-go/either"
 	F "github.com/IBM/fp-go/function"
 	C "github.com/ibm-hyper-protect/contract-go/certificates"
@@ -43,6 +44,25 @@ func TestCertificateFromSpec(t *testing.T) {
 	assert.Equal(t, E.Of[error]("1.0.11"), result)
 }
 
+func TestListCertificates(t *testing.T) {
+	certs := map[string]string{
+		"1.0.10": "cert 1.0.10",
+		"1.0.11": "cert 1.0.11",
+		"1.0.9":  "cert 1.0.9",
+	}
+	result := F.Pipe1(
+		ListCertificates(certs),
+		E.Map[error](A.Map(F.Flow2(
+			C.GetVersion,
+			C.Version.String,
+		))),
+	)
+
+	assert.Equal(t, E.Of[error](A.From("1.0.11", "1.0.10", "1.0.9")), result)
+
+	assert.True(t, E.IsLeft(ListCertificates(map[string]string{"latest": "cert"})))
+}
+
 func TestResolver(t *testing.T) {
 	resolver := ParseResolver(DefaultTemplate)
 	version := ParseVersion("1.0.11")
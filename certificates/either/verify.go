@@ -0,0 +1,283 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	S "github.com/IBM/fp-go/string"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+)
+
+const (
+	typeCertificate = "CERTIFICATE"
+	typeCRL         = "X509 CRL"
+)
+
+// ParseCertificates parses all PEM encoded certificates of a bundle
+func ParseCertificates(bundle []byte) E.Either[error, []*x509.Certificate] {
+	var blocks [][]byte
+	for rest := bundle; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == typeCertificate {
+			blocks = append(blocks, block.Bytes)
+		}
+	}
+	return F.Pipe1(
+		blocks,
+		E.TraverseArray(E.Eitherize1(x509.ParseCertificate)),
+	)
+}
+
+// ParseCertificate parses the first PEM encoded certificate
+func ParseCertificate(data []byte) E.Either[error, *x509.Certificate] {
+	return F.Pipe2(
+		data,
+		ParseCertificates,
+		E.Chain(F.Flow2(
+			A.Head[*x509.Certificate],
+			E.FromOption[*x509.Certificate](func() error {
+				return fmt.Errorf("unable to find a [%s] block in the PEM data", typeCertificate)
+			}),
+		)),
+	)
+}
+
+// ParseRevocationList parses a PEM or DER encoded certificate revocation list
+func ParseRevocationList(data []byte) E.Either[error, *x509.RevocationList] {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != typeCRL {
+			return E.Left[*x509.RevocationList](fmt.Errorf("expected a [%s] block but got [%s]", typeCRL, block.Type))
+		}
+		data = block.Bytes
+	}
+	return E.TryCatchError(x509.ParseRevocationList(data))
+}
+
+// certPools splits a bundle into self-signed roots and intermediates
+func certPools(bundle []*x509.Certificate) (*x509.CertPool, *x509.CertPool) {
+	roots, intermediates := x509.NewCertPool(), x509.NewCertPool()
+	for _, cert := range bundle {
+		if bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil {
+			roots.AddCert(cert)
+		} else {
+			intermediates.AddCert(cert)
+		}
+	}
+	return roots, intermediates
+}
+
+// verificationTime returns the configured time of the verification or the current time
+func verificationTime(cfg *C.VerifyConfig) time.Time {
+	if cfg.Time.IsZero() {
+		return time.Now()
+	}
+	return cfg.Time
+}
+
+// clampToValidity moves a time into the validity window of a certificate, so the chain can be built independently of
+// the expiry of the leaf
+func clampToValidity(cert *x509.Certificate, t time.Time) time.Time {
+	if t.Before(cert.NotBefore) {
+		return cert.NotBefore
+	}
+	if t.After(cert.NotAfter) {
+		return cert.NotAfter
+	}
+	return t
+}
+
+// checkIssuers verifies the validity window of the issuers of a chain
+func checkIssuers(issuers []*x509.Certificate, at time.Time) []string {
+	var problems []string
+	for _, issuer := range issuers {
+		if at.Before(issuer.NotBefore) {
+			problems = append(problems, fmt.Sprintf("the issuer [%s] is not valid before %s", issuer.Subject, issuer.NotBefore.UTC().Format(time.RFC3339)))
+		} else if at.After(issuer.NotAfter) {
+			problems = append(problems, fmt.Sprintf("the issuer [%s] expired at %s", issuer.Subject, issuer.NotAfter.UTC().Format(time.RFC3339)))
+		}
+	}
+	return problems
+}
+
+// checkChain verifies at the given time that the certificate chains to a root of the bundle and returns the issuer of
+// the certificate. The validity window of the certificate itself is reported by [checkValidity], so the chain of an
+// expired certificate is still verified
+func checkChain(cert *x509.Certificate, bundle []*x509.Certificate, at time.Time) (*x509.Certificate, []string) {
+	roots, intermediates := certPools(bundle)
+	verify := func(t time.Time) ([][]*x509.Certificate, error) {
+		return cert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   t,
+			KeyUsages:     A.Of(x509.ExtKeyUsageAny),
+		})
+	}
+	chains, err := verify(at)
+	var problems []string
+	var invalid x509.CertificateInvalidError
+	if errors.As(err, &invalid) && invalid.Cert == cert && invalid.Reason == x509.Expired {
+		// build the chain within the validity of the certificate and check the issuers at the time of the verification
+		chains, err = verify(clampToValidity(cert, at))
+		if err == nil {
+			problems = checkIssuers(chains[0][1:], at)
+		}
+	}
+	if err != nil {
+		return nil, A.Of(fmt.Sprintf("the certificate does not chain to a trusted root: %v", err))
+	}
+	if len(chains[0]) < 2 {
+		return nil, A.Of("the certificate must not be a trusted root itself")
+	}
+	return chains[0][1], problems
+}
+
+// checkValidity verifies the validity window of the certificate
+func checkValidity(cert *x509.Certificate, at time.Time) []string {
+	if at.Before(cert.NotBefore) {
+		return A.Of(fmt.Sprintf("the certificate is not valid before %s", cert.NotBefore.UTC().Format(time.RFC3339)))
+	}
+	if at.After(cert.NotAfter) {
+		return A.Of(fmt.Sprintf("the certificate expired at %s", cert.NotAfter.UTC().Format(time.RFC3339)))
+	}
+	return nil
+}
+
+// checkKey verifies type and size of the public key and returns the key size
+func checkKey(cert *x509.Certificate, minKeySize int) (int, []string) {
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return 0, A.Of(fmt.Sprintf("the public key must be an RSA key but is [%T]", cert.PublicKey))
+	}
+	size := key.N.BitLen()
+	if size < minKeySize {
+		return size, A.Of(fmt.Sprintf("the RSA key has %d bits but at least %d bits are required", size, minKeySize))
+	}
+	return size, nil
+}
+
+// checkSubject verifies that the subject denotes an HPCR contract encryption certificate
+func checkSubject(cert *x509.Certificate) []string {
+	var problems []string
+	if !A.Any(S.Equals(cert.Subject.CommonName))(C.HpcrEncryptionCommonNames) {
+		problems = append(problems, fmt.Sprintf("the common name [%s] does not denote an HPCR encryption certificate, expected one of %v", cert.Subject.CommonName, C.HpcrEncryptionCommonNames))
+	}
+	if !A.Any(func(org string) bool {
+		return strings.HasPrefix(org, C.HpcrOrganizationPrefix)
+	})(cert.Subject.Organization) {
+		problems = append(problems, fmt.Sprintf("the organization %v does not start with [%s]", cert.Subject.Organization, C.HpcrOrganizationPrefix))
+	}
+	return problems
+}
+
+// checkRevocation verifies the certificate against the revocation list of its issuer
+func checkRevocation(cert, issuer *x509.Certificate, crl *x509.RevocationList, at time.Time) []string {
+	if issuer == nil {
+		return A.Of("the revocation list cannot be checked without a trusted issuer")
+	}
+	if err := crl.CheckSignatureFrom(issuer); err != nil {
+		return A.Of(fmt.Sprintf("the revocation list is not signed by the issuer of the certificate: %v", err))
+	}
+	var problems []string
+	if !crl.NextUpdate.IsZero() && at.After(crl.NextUpdate) {
+		problems = append(problems, fmt.Sprintf("the revocation list is outdated since %s", crl.NextUpdate.UTC().Format(time.RFC3339)))
+	}
+	for _, revoked := range crl.RevokedCertificateEntries {
+		if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			problems = append(problems, fmt.Sprintf("the certificate has been revoked at %s", revoked.RevocationTime.UTC().Format(time.RFC3339)))
+		}
+	}
+	return problems
+}
+
+// VerifyCertificate verifies an encryption certificate offline. The certificate must chain to a root of the bundle,
+// be within its validity window, carry an RSA key of sufficient size, denote an HPCR encryption certificate and, if a
+// revocation list is given, must not have been revoked. All problems are reported in the result.
+func VerifyCertificate(cfg *C.VerifyConfig) func(cert *x509.Certificate) E.Either[error, *C.CertificateVerification] {
+	return func(cert *x509.Certificate) E.Either[error, *C.CertificateVerification] {
+		return F.Pipe1(
+			ParseCertificates(cfg.Bundle),
+			E.Chain(func(bundle []*x509.Certificate) E.Either[error, *C.CertificateVerification] {
+				at := verificationTime(cfg)
+				issuer, chainProblems := checkChain(cert, bundle, at)
+				keySize, keyProblems := checkKey(cert, cfg.MinKeySize)
+
+				result := &C.CertificateVerification{
+					Subject:   cert.Subject.String(),
+					Issuer:    cert.Issuer.String(),
+					NotBefore: cert.NotBefore.UTC(),
+					NotAfter:  cert.NotAfter.UTC(),
+					KeySize:   keySize,
+					Problems: A.Flatten(A.From(
+						chainProblems,
+						checkValidity(cert, at),
+						keyProblems,
+						checkSubject(cert),
+					)),
+				}
+				if len(cfg.CRL) == 0 {
+					return E.Of[error](result)
+				}
+				return F.Pipe1(
+					ParseRevocationList(cfg.CRL),
+					E.Map[error](func(crl *x509.RevocationList) *C.CertificateVerification {
+						result.Problems = append(result.Problems, checkRevocation(cert, issuer, crl, at)...)
+						return result
+					}),
+				)
+			}),
+		)
+	}
+}
+
+// VerifyCertificatePEM parses a PEM encoded certificate and verifies it as described by [VerifyCertificate]
+func VerifyCertificatePEM(cfg *C.VerifyConfig) func(data []byte) E.Either[error, *C.CertificateVerification] {
+	return F.Flow2(
+		ParseCertificate,
+		E.Chain(VerifyCertificate(cfg)),
+	)
+}
+
+// ValidCertificate verifies a PEM encoded certificate and returns it unchanged if it is valid, otherwise the error lists
+// all problems
+func ValidCertificate(cfg *C.VerifyConfig) func(data []byte) E.Either[error, []byte] {
+	verify := VerifyCertificatePEM(cfg)
+	return func(data []byte) E.Either[error, []byte] {
+		return F.Pipe2(
+			data,
+			verify,
+			E.Chain(func(result *C.CertificateVerification) E.Either[error, []byte] {
+				if err := result.ToError(); err != nil {
+					return E.Left[[]byte](err)
+				}
+				return E.Of[error](data)
+			}),
+		)
+	}
+}
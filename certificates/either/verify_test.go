@@ -0,0 +1,227 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	E "github.com/IBM/fp-go/either"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+	D "github.com/ibm-hyper-protect/contract-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testNow    = time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	testSerial = int64(1)
+)
+
+// testCert is a certificate together with its key
+type testCert struct {
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+	pem  []byte
+}
+
+func makeTestCert(t *testing.T, template *x509.Certificate, parent *testCert, bits int) *testCert {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+
+	testSerial++
+	template.SerialNumber = big.NewInt(testSerial)
+	if template.NotBefore.IsZero() {
+		template.NotBefore = testNow.AddDate(-1, 0, 0)
+		template.NotAfter = testNow.AddDate(1, 0, 0)
+	}
+
+	issuer, signer := template, key
+	if parent != nil {
+		issuer, signer = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func makeCA(t *testing.T, name string, parent *testCert) *testCert {
+	return makeTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name, Organization: []string{"Test CA"}},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, parent, 2048)
+}
+
+func hpcrTemplate() *x509.Certificate {
+	return &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   C.HpcrEncryptionCommonName,
+			Organization: []string{"IBM Deutschland R&D GmbH"},
+		},
+		KeyUsage: x509.KeyUsageKeyEncipherment,
+	}
+}
+
+func makeCRL(t *testing.T, issuer *testCert, revoked ...*testCert) []byte {
+	template := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: testNow.AddDate(0, -1, 0),
+		NextUpdate: testNow.AddDate(0, 1, 0),
+	}
+	for _, cert := range revoked {
+		template.RevokedCertificateEntries = append(template.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   cert.cert.SerialNumber,
+			RevocationTime: testNow.AddDate(0, -1, 0),
+		})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, template, issuer.cert, issuer.key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+}
+
+func TestVerifyCertificate(t *testing.T) {
+	root := makeCA(t, "Test Root", nil)
+	intermediate := makeCA(t, "Test Intermediate", root)
+	leaf := makeTestCert(t, hpcrTemplate(), intermediate, 2048)
+
+	bundle := append(append([]byte{}, intermediate.pem...), root.pem...)
+	cfg := &C.VerifyConfig{Bundle: bundle, Time: testNow, MinKeySize: 2048}
+
+	verify := func(cfg *C.VerifyConfig, cert *testCert) *C.CertificateVerification {
+		result, err := E.UnwrapError(VerifyCertificatePEM(cfg)(cert.pem))
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		result := verify(cfg, leaf)
+		assert.True(t, result.Valid(), result.Problems)
+		assert.Equal(t, 2048, result.KeySize)
+		assert.Equal(t, E.Of[error](leaf.pem), ValidCertificate(cfg)(leaf.pem))
+	})
+
+	t.Run("untrusted chain", func(t *testing.T) {
+		result := verify(&C.VerifyConfig{Bundle: root.pem, Time: testNow, MinKeySize: 2048}, leaf)
+		assert.Len(t, result.Problems, 1)
+		assert.Contains(t, result.Problems[0], "trusted root")
+		assert.True(t, E.IsLeft(ValidCertificate(&C.VerifyConfig{Bundle: root.pem, Time: testNow})(leaf.pem)))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		template := hpcrTemplate()
+		template.NotBefore = testNow.AddDate(0, -2, 0)
+		template.NotAfter = testNow.AddDate(0, -1, 0)
+		expired := makeTestCert(t, template, intermediate, 2048)
+
+		result := verify(cfg, expired)
+		// the chain itself is still fine
+		assert.Len(t, result.Problems, 1)
+		assert.Contains(t, result.Problems[0], "the certificate expired")
+	})
+
+	t.Run("expired issuer", func(t *testing.T) {
+		template := &x509.Certificate{
+			Subject:               pkix.Name{CommonName: "Expired Intermediate", Organization: []string{"Test CA"}},
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+			NotBefore:             testNow.AddDate(-2, 0, 0),
+			NotAfter:              testNow.AddDate(0, -1, 0),
+		}
+		expiredIntermediate := makeTestCert(t, template, root, 2048)
+		validLeaf := makeTestCert(t, hpcrTemplate(), expiredIntermediate, 2048)
+		expiredLeaf := makeTestCert(t, func() *x509.Certificate {
+			template := hpcrTemplate()
+			template.NotBefore = testNow.AddDate(-1, 0, 0)
+			template.NotAfter = testNow.AddDate(0, -2, 0)
+			return template
+		}(), expiredIntermediate, 2048)
+		issuerBundle := append(append([]byte{}, expiredIntermediate.pem...), root.pem...)
+
+		// the leaf is valid but its issuer is not
+		result := verify(&C.VerifyConfig{Bundle: issuerBundle, Time: testNow, MinKeySize: 2048}, validLeaf)
+		assert.Len(t, result.Problems, 1)
+		assert.Contains(t, result.Problems[0], "trusted root")
+
+		// both the leaf and its issuer expired
+		result = verify(&C.VerifyConfig{Bundle: issuerBundle, Time: testNow, MinKeySize: 2048}, expiredLeaf)
+		assert.Len(t, result.Problems, 2)
+		assert.Contains(t, result.Problems[0], "the issuer [CN=Expired Intermediate,O=Test CA] expired")
+		assert.Contains(t, result.Problems[1], "the certificate expired")
+	})
+
+	t.Run("key size", func(t *testing.T) {
+		result := verify(&C.VerifyConfig{Bundle: bundle, Time: testNow, MinKeySize: 4096}, leaf)
+		assert.Len(t, result.Problems, 1)
+		assert.Contains(t, result.Problems[0], "2048 bits")
+	})
+
+	t.Run("subject", func(t *testing.T) {
+		template := hpcrTemplate()
+		template.Subject = pkix.Name{CommonName: "Some Other Service", Organization: []string{"Mallory Inc."}}
+		other := makeTestCert(t, template, intermediate, 2048)
+
+		result := verify(cfg, other)
+		assert.Len(t, result.Problems, 2)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		result := verify(&C.VerifyConfig{Bundle: bundle, Time: testNow, MinKeySize: 2048, CRL: makeCRL(t, intermediate, leaf)}, leaf)
+		assert.Len(t, result.Problems, 1)
+		assert.Contains(t, result.Problems[0], "revoked")
+
+		result = verify(&C.VerifyConfig{Bundle: bundle, Time: testNow, MinKeySize: 2048, CRL: makeCRL(t, intermediate)}, leaf)
+		assert.True(t, result.Valid(), result.Problems)
+	})
+
+	t.Run("foreign CRL", func(t *testing.T) {
+		result := verify(&C.VerifyConfig{Bundle: bundle, Time: testNow, MinKeySize: 2048, CRL: makeCRL(t, root)}, leaf)
+		assert.Len(t, result.Problems, 1)
+		assert.Contains(t, result.Problems[0], "not signed by the issuer")
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		assert.True(t, E.IsLeft(VerifyCertificatePEM(cfg)([]byte("no certificate"))))
+		assert.True(t, E.IsLeft(VerifyCertificatePEM(&C.VerifyConfig{Bundle: bundle, CRL: []byte("no crl")})(leaf.pem)))
+	})
+}
+
+func TestCheckSubjectEmbeddedCertificates(t *testing.T) {
+	checked := make(map[string]bool)
+	for version, data := range D.Certificates {
+		// some of the early certificates cannot be parsed by the strict parser of golang
+		cert, err := E.UnwrapError(ParseCertificate([]byte(data)))
+		if err != nil {
+			t.Logf("skipping certificate [%s]: %v", version, err)
+			continue
+		}
+		assert.Empty(t, checkSubject(cert), version)
+		checked[version] = true
+	}
+	// these carry the legacy common name
+	assert.True(t, checked["1.0.2"])
+	assert.True(t, checked["1.0.5"])
+}
@@ -0,0 +1,227 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IO "github.com/IBM/fp-go/io"
+	IOE "github.com/IBM/fp-go/ioeither"
+	IOEH "github.com/IBM/fp-go/ioeither/http"
+	J "github.com/IBM/fp-go/json"
+	O "github.com/IBM/fp-go/option"
+	R "github.com/IBM/fp-go/record"
+	S "github.com/IBM/fp-go/string"
+	T "github.com/IBM/fp-go/tuple"
+	"github.com/Masterminds/semver"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+	CE "github.com/ibm-hyper-protect/contract-go/certificates/either"
+	D "github.com/ibm-hyper-protect/contract-go/data"
+)
+
+type (
+	// cachedResult is a version together with the cache entry that holds its certificate or the error of the lookup
+	cachedResult = T.Tuple2[C.Version, E.Either[error, C.CacheEntry]]
+)
+
+var (
+	// DefaultCacheDir returns the default directory of the certificate cache in the user cache directory, e.g.
+	// `$XDG_CACHE_HOME/contract-go/certificates` on Linux
+	DefaultCacheDir = F.Pipe1(
+		IOE.Eitherize0(os.UserCacheDir)(),
+		IOE.Map[error](func(dir string) string {
+			return filepath.Join(dir, "contract-go", "certificates")
+		}),
+	)
+
+	// toDownloadResult extracts the certificate from a cache lookup
+	toDownloadResult = T.Map2(F.Identity[C.Version], E.Map[error](func(entry C.CacheEntry) string {
+		return entry.Certificate
+	}))
+)
+
+// ReadCacheIndex reads the index of the certificate cache, a missing index is an empty cache
+func ReadCacheIndex(dir string) IOE.IOEither[error, C.CacheIndex] {
+	return F.Pipe1(
+		IOE.TryCatchError(func() ([]byte, error) {
+			data, err := os.ReadFile(filepath.Join(dir, C.CacheIndexFile))
+			if errors.Is(err, fs.ErrNotExist) {
+				return []byte("{}"), nil
+			}
+			return data, err
+		}),
+		IOE.ChainEitherK(J.Unmarshal[C.CacheIndex]),
+	)
+}
+
+// WriteCacheIndex writes the index of the certificate cache. The index is replaced atomically, so concurrent readers
+// never see a partial index
+func WriteCacheIndex(dir string) func(C.CacheIndex) IOE.IOEither[error, C.CacheIndex] {
+	return func(index C.CacheIndex) IOE.IOEither[error, C.CacheIndex] {
+		return F.Pipe2(
+			index,
+			J.Marshal[C.CacheIndex],
+			E.Fold(IOE.Left[C.CacheIndex, error], func(data []byte) IOE.IOEither[error, C.CacheIndex] {
+				return IOE.TryCatchError(func() (C.CacheIndex, error) {
+					if err := os.MkdirAll(dir, 0o755); err != nil {
+						return index, err
+					}
+					tmp, err := os.CreateTemp(dir, C.CacheIndexFile+".*")
+					if err != nil {
+						return index, err
+					}
+					defer os.Remove(tmp.Name())
+					_, err = tmp.Write(data)
+					if closeErr := tmp.Close(); err == nil {
+						err = closeErr
+					}
+					if err != nil {
+						return index, err
+					}
+					return index, os.Rename(tmp.Name(), filepath.Join(dir, C.CacheIndexFile))
+				})
+			}),
+		)
+	}
+}
+
+// CertificatesFromCache returns the certificates of the cache keyed by version, without network access
+func CertificatesFromCache(dir string) IOE.IOEither[error, map[string]string] {
+	return F.Pipe1(
+		ReadCacheIndex(dir),
+		IOE.ChainEitherK(CE.CertificatesFromIndex),
+	)
+}
+
+// CertificateFromSpecCached selects the best matching certificate from the cache and the built-in certificates,
+// cached certificates take precedence over built-in certificates of the same version
+func CertificateFromSpecCached(dir string) func(spec *semver.Constraints) IOE.IOEither[error, C.VersionCert] {
+	return func(spec *semver.Constraints) IOE.IOEither[error, C.VersionCert] {
+		return F.Pipe2(
+			CertificatesFromCache(dir),
+			IOE.Map[error](func(cached map[string]string) map[string]string {
+				return CE.MergeCertificates(D.Certificates, cached)
+			}),
+			IOE.ChainEitherK(CE.CertificateFromSpec(spec)),
+		)
+	}
+}
+
+// downloadVersion downloads the certificate of a single version into a new cache entry
+func downloadVersion(download func(string) IOE.IOEither[error, string], resolver CE.Resolver, now time.Time) func(C.Version) IOE.IOEither[error, C.CacheEntry] {
+	return F.Flow2(
+		resolver,
+		E.Fold(IOE.Left[C.CacheEntry, error], func(url string) IOE.IOEither[error, C.CacheEntry] {
+			return F.Pipe1(
+				download(url),
+				IOE.Map[error](CE.MakeCacheEntry(url, now)),
+			)
+		}),
+	)
+}
+
+// cachedVersion resolves a single version from the cache. Missing or stale entries are downloaded, if the download of
+// a stale entry fails the stale entry is used, so lookups keep working offline. A refresh always downloads. Entries
+// that have been downloaded from a different URL than the one of the resolver are missing.
+func cachedVersion(download func(string) IOE.IOEither[error, string], cfg *C.CacheConfig, resolver CE.Resolver, index C.CacheIndex, now time.Time) func(C.Version) IOE.IOEither[error, C.CacheEntry] {
+	fresh := CE.IsFresh(cfg.TTL, now)
+	return func(version C.Version) IOE.IOEither[error, C.CacheEntry] {
+		url := resolver(version)
+		cached := F.Pipe3(
+			index,
+			R.Lookup[C.CacheEntry](version.String()),
+			O.Filter(func(entry C.CacheEntry) bool {
+				return E.Fold(F.Constant1[error](false), S.Equals(entry.Url))(url)
+			}),
+			O.Chain(F.Flow2(
+				CE.ValidateCacheEntry,
+				E.ToOption[error, C.CacheEntry],
+			)),
+		)
+		fetched := downloadVersion(download, resolver, now)(version)
+		if cfg.Refresh {
+			return fetched
+		}
+		return F.Pipe1(
+			cached,
+			O.Fold(F.Constant(fetched), func(stale C.CacheEntry) IOE.IOEither[error, C.CacheEntry] {
+				if fresh(stale) {
+					return IOE.Of[error](stale)
+				}
+				return F.Pipe1(
+					fetched,
+					IOE.Alt(F.Constant(IOE.Of[error](stale))),
+				)
+			}),
+		)
+	}
+}
+
+// updateCacheIndex stores the successfully resolved certificates in the cache, the index is only written if it changed
+func updateCacheIndex(dir string, index C.CacheIndex) func([]cachedResult) IOE.IOEither[error, C.CacheIndex] {
+	return func(results []cachedResult) IOE.IOEither[error, C.CacheIndex] {
+		updated := R.Copy(index)
+		changed := false
+		for _, result := range results {
+			entry, err := E.UnwrapError(result.F2)
+			if err != nil {
+				continue
+			}
+			key := result.F1.String()
+			if existing, ok := updated[key]; !ok || existing != entry {
+				updated[key] = entry
+				changed = true
+			}
+		}
+		if !changed {
+			return IOE.Of[error](index)
+		}
+		return WriteCacheIndex(dir)(updated)
+	}
+}
+
+// DownloadCertificatesCached downloads the certificates for the given versions through the local certificate cache.
+// Downloads are resilient as described by [DownloadCertificatesWithConfig]. The operation only fails if the cache itself
+// cannot be read or written.
+func DownloadCertificatesCached(client IOEH.Client, dl *C.DownloadConfig) func(cfg *C.CacheConfig) func(resolver CE.Resolver) func(versions []C.Version) IOE.IOEither[error, []C.DownloadResult] {
+	download := downloadWithRetry(client, dl)
+	return func(cfg *C.CacheConfig) func(resolver CE.Resolver) func(versions []C.Version) IOE.IOEither[error, []C.DownloadResult] {
+		return func(resolver CE.Resolver) func(versions []C.Version) IOE.IOEither[error, []C.DownloadResult] {
+			return func(versions []C.Version) IOE.IOEither[error, []C.DownloadResult] {
+				return F.Pipe1(
+					IOE.SequenceT2(ReadCacheIndex(cfg.Dir), IOE.FromIO[error](IO.Now)),
+					IOE.Chain(T.Tupled2(func(index C.CacheIndex, now time.Time) IOE.IOEither[error, []C.DownloadResult] {
+						return F.Pipe3(
+							withDownloadContext(dl, func(ctx context.Context) IO.IO[[]cachedResult] {
+								return downloadResults(dl, cachedVersion(download(ctx), cfg, resolver, index, now))(versions)
+							}),
+							IOE.FromIO[error, []cachedResult],
+							IOE.ChainFirst(updateCacheIndex(cfg.Dir, index)),
+							IOE.Map[error](A.Map(toDownloadResult)),
+						)
+					})),
+				)
+			}
+		}
+	}
+}
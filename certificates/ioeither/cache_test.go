@@ -0,0 +1,191 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	IOEH "github.com/IBM/fp-go/ioeither/http"
+	T "github.com/IBM/fp-go/tuple"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+	CE "github.com/ibm-hyper-protect/contract-go/certificates/either"
+	D "github.com/ibm-hyper-protect/contract-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// certServer serves a fake certificate for every path and counts the requests
+func certServer(t *testing.T) (*httptest.Server, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		fmt.Fprintf(w, "cert %s", r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+// testDownloadConfig retries quickly, so tests of failures do not take long
+var testDownloadConfig = &C.DownloadConfig{
+	Concurrency:    2,
+	Retries:        1,
+	Backoff:        time.Millisecond,
+	RequestTimeout: time.Second,
+	Timeout:        10 * time.Second,
+}
+
+func downloadCached(server *httptest.Server, cfg *C.CacheConfig, versions ...string) E.Either[error, []C.VersionCert] {
+	client := IOEH.MakeClient(http.DefaultClient)
+	resolver := CE.ParseResolver(server.URL + "/{{.Major}}.{{.Minor}}.{{.Patch}}")
+
+	return F.Pipe3(
+		versions,
+		E.TraverseArray(CE.ParseVersion),
+		E.Fold(IOE.Left[[]C.DownloadResult, error], DownloadCertificatesCached(client, testDownloadConfig)(cfg)(resolver)),
+		IOE.ChainEitherK(F.Flow2(
+			C.PartitionDownloads,
+			T.Tupled2(func(errs []error, certs []C.VersionCert) E.Either[error, []C.VersionCert] {
+				if len(errs) > 0 {
+					return E.Left[[]C.VersionCert](errors.Join(errs...))
+				}
+				return E.Of[error](certs)
+			}),
+		)),
+	)()
+}
+
+func certificates(certs []C.VersionCert) []string {
+	return A.Map(T.Second[C.Version, string])(certs)
+}
+
+func TestDownloadCertificatesCached(t *testing.T) {
+	server, hits := certServer(t)
+	cfg := &C.CacheConfig{Dir: t.TempDir(), TTL: time.Hour}
+
+	certs, err := E.UnwrapError(downloadCached(server, cfg, "1.0.10", "1.0.11"))
+	require.NoError(t, err)
+	assert.Equal(t, A.From("cert /1.0.10", "cert /1.0.11"), certificates(certs))
+	assert.Equal(t, int32(2), *hits)
+
+	// fresh entries are served from the cache
+	_, err = E.UnwrapError(downloadCached(server, cfg, "1.0.10", "1.0.11", "1.0.12"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), *hits)
+
+	index, err := E.UnwrapError(ReadCacheIndex(cfg.Dir)())
+	require.NoError(t, err)
+	require.Contains(t, index, "1.0.10")
+	assert.Equal(t, server.URL+"/1.0.10", index["1.0.10"].Url)
+	assert.Equal(t, CE.Checksum("cert /1.0.10"), index["1.0.10"].Sha256)
+
+	// a refresh downloads again
+	refresh := &C.CacheConfig{Dir: cfg.Dir, TTL: time.Hour, Refresh: true}
+	_, err = E.UnwrapError(downloadCached(server, refresh, "1.0.10"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), *hits)
+}
+
+func TestDownloadCertificatesCachedOtherSource(t *testing.T) {
+	staging, _ := certServer(t)
+	production, hits := certServer(t)
+	cfg := &C.CacheConfig{Dir: t.TempDir(), TTL: time.Hour}
+
+	_, err := E.UnwrapError(downloadCached(staging, cfg, "1.0.10"))
+	require.NoError(t, err)
+
+	// a fresh entry from another source is a miss
+	_, err = E.UnwrapError(downloadCached(production, cfg, "1.0.10"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), *hits)
+
+	index, err := E.UnwrapError(ReadCacheIndex(cfg.Dir)())
+	require.NoError(t, err)
+	assert.Equal(t, production.URL+"/1.0.10", index["1.0.10"].Url)
+
+	// the entry of the source is served from the cache
+	_, err = E.UnwrapError(downloadCached(production, cfg, "1.0.10"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), *hits)
+}
+
+func TestDownloadCertificatesCachedOffline(t *testing.T) {
+	server, _ := certServer(t)
+	cfg := &C.CacheConfig{Dir: t.TempDir(), TTL: 0}
+
+	_, err := E.UnwrapError(downloadCached(server, cfg, "1.0.10"))
+	require.NoError(t, err)
+
+	server.Close()
+
+	// stale entries are used if the download fails
+	certs, err := E.UnwrapError(downloadCached(server, cfg, "1.0.10"))
+	require.NoError(t, err)
+	assert.Equal(t, A.From("cert /1.0.10"), certificates(certs))
+
+	// missing entries fail
+	assert.True(t, E.IsLeft(downloadCached(server, cfg, "1.0.11")))
+
+	// a refresh does not fall back to stale entries
+	refresh := &C.CacheConfig{Dir: cfg.Dir, Refresh: true}
+	assert.True(t, E.IsLeft(downloadCached(server, refresh, "1.0.10")))
+}
+
+func TestCertificateFromSpecCached(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	index := C.CacheIndex{
+		"1.0.12": CE.MakeCacheEntry("https://example.com/1.0.12", now)("cached 1.0.12"),
+		"1.0.13": CE.MakeCacheEntry("https://example.com/1.0.13", now)("cached 1.0.13"),
+	}
+	_, err := E.UnwrapError(WriteCacheIndex(dir)(index)())
+	require.NoError(t, err)
+
+	selectCert := func(spec string) E.Either[error, string] {
+		return F.Pipe2(
+			CE.ParseConstraint(spec),
+			E.Fold(IOE.Left[C.VersionCert, error], CertificateFromSpecCached(dir)),
+			IOE.Map[error](T.Second[C.Version, string]),
+		)()
+	}
+
+	// cached certificates take precedence over built-in ones
+	assert.Equal(t, E.Of[error]("cached 1.0.12"), selectCert("1.0.12"))
+	assert.Equal(t, E.Of[error]("cached 1.0.13"), selectCert("~1.0.10"))
+	// built-in certificates are available without a cache entry
+	assert.Equal(t, E.Of[error](D.Certificates["1.0.10"]), selectCert("1.0.10"))
+
+	// corrupted entries are detected
+	index["1.0.12"] = C.CacheEntry{Certificate: "tampered", Sha256: index["1.0.12"].Sha256}
+	_, err = E.UnwrapError(WriteCacheIndex(dir)(index)())
+	require.NoError(t, err)
+	assert.True(t, E.IsLeft(selectCert("1.0.12")))
+}
+
+func TestReadCacheIndexMissing(t *testing.T) {
+	index, err := E.UnwrapError(ReadCacheIndex(t.TempDir())())
+	require.NoError(t, err)
+	assert.Empty(t, index)
+}
@@ -15,6 +15,7 @@
 package ioeither
 
 import (
+	"context"
 	"net/http"
 
 	F "github.com/IBM/fp-go/function"
@@ -28,3 +29,12 @@ var (
 	// specialize
 	makeGetRequest = makeRequest(http.MethodGet, nil)
 )
+
+// makeGetRequestWithContext creates a GET request that is bound to a context
+func makeGetRequestWithContext(ctx context.Context) func(url string) IOE.IOEither[error, *http.Request] {
+	return func(url string) IOE.IOEither[error, *http.Request] {
+		return IOE.TryCatchError(func() (*http.Request, error) {
+			return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		})
+	}
+}
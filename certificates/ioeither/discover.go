@@ -0,0 +1,192 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	H "github.com/IBM/fp-go/http"
+	IO "github.com/IBM/fp-go/io"
+	IOE "github.com/IBM/fp-go/ioeither"
+	IOEH "github.com/IBM/fp-go/ioeither/http"
+	T "github.com/IBM/fp-go/tuple"
+	"github.com/Masterminds/semver"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+	CE "github.com/ibm-hyper-protect/contract-go/certificates/either"
+)
+
+type (
+	// discovered is a version that exists together with the cache entry of its certificate
+	discovered = T.Tuple2[C.Version, C.CacheEntry]
+)
+
+var (
+	// discoveredToVersionCert extracts the certificate from a discovered version
+	discoveredToVersionCert = T.Map2(F.Identity[C.Version], func(entry C.CacheEntry) string {
+		return entry.Certificate
+	})
+
+	// discoveredToCachedResult converts a discovered version into a successful cache lookup
+	discoveredToCachedResult = T.Map2(F.Identity[C.Version], E.Of[error, C.CacheEntry])
+)
+
+// isMissing tests if a download failed because the version does not exist, i.e. the server responded with a client
+// error that is not worth a retry
+func isMissing(err error) bool {
+	var httpErr *H.HttpError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	status := httpErr.StatusCode()
+	return status >= http.StatusBadRequest && status < http.StatusInternalServerError && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests
+}
+
+// discoverVersions probes versions in order of increasing patch and minor versions. A minor version is exhausted after
+// [C.DiscoveryConfig.MaxMisses] consecutive missing patch versions, the discovery stops at the first minor version
+// without any certificate. Errors other than missing versions abort the discovery, so an unreachable server is never
+// mistaken for the absence of certificates.
+func discoverVersions(fetch func(C.Version) IOE.IOEither[error, C.CacheEntry], discovery *C.DiscoveryConfig) IOE.IOEither[error, []discovered] {
+	return IOE.TryCatchError(func() ([]discovered, error) {
+		var found []discovered
+		major, minor, patch := discovery.Start.Major(), discovery.Start.Minor(), discovery.Start.Patch()
+		for {
+			hits := 0
+			for misses := 0; misses < discovery.MaxMisses; patch++ {
+				version, err := semver.NewVersion(fmt.Sprintf("%d.%d.%d", major, minor, patch))
+				if err != nil {
+					return nil, err
+				}
+				entry, err := E.UnwrapError(fetch(version)())
+				switch {
+				case err == nil:
+					found = append(found, T.MakeTuple2(version, entry))
+					hits++
+					misses = 0
+				case isMissing(err):
+					misses++
+				default:
+					return nil, err
+				}
+			}
+			if hits == 0 {
+				return found, nil
+			}
+			minor, patch = minor+1, 0
+		}
+	})
+}
+
+// discoverWithContext discovers the versions that exist, fetch resolves a single version within the context of the
+// downloads
+func discoverWithContext(dl *C.DownloadConfig, fetch func(ctx context.Context) func(C.Version) IOE.IOEither[error, C.CacheEntry]) func(discovery *C.DiscoveryConfig) IOE.IOEither[error, []discovered] {
+	return func(discovery *C.DiscoveryConfig) IOE.IOEither[error, []discovered] {
+		return IOE.IOEither[error, []discovered](withDownloadContext(dl, func(ctx context.Context) IO.IO[E.Either[error, []discovered]] {
+			return IO.IO[E.Either[error, []discovered]](discoverVersions(fetch(ctx), discovery))
+		}))
+	}
+}
+
+// cachedDiscovered returns the valid cache entries downloaded from the resolver for the versions from the start of the
+// discovery on, in ascending order
+func cachedDiscovered(resolver CE.Resolver, index C.CacheIndex, discovery *C.DiscoveryConfig) []discovered {
+	var found []discovered
+	for key, entry := range index {
+		version, err := semver.NewVersion(key)
+		if err != nil || version.LessThan(discovery.Start) {
+			continue
+		}
+		if url, err := E.UnwrapError(resolver(version)); err != nil || url != entry.Url || E.IsLeft(CE.ValidateCacheEntry(entry)) {
+			continue
+		}
+		found = append(found, T.MakeTuple2(version, entry))
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].F1.LessThan(found[j].F1)
+	})
+	return found
+}
+
+// orCached falls back to the cached versions if the discovery fails, e.g. because the server cannot be reached. The
+// error is kept if nothing has been cached.
+func orCached(cached []discovered) func(IOE.IOEither[error, []discovered]) IOE.IOEither[error, []discovered] {
+	fallback := IOE.Fold(func(err error) IO.IO[E.Either[error, []discovered]] {
+		if len(cached) == 0 {
+			return IO.Of(E.Left[[]discovered](err))
+		}
+		return IO.Of(E.Of[error](cached))
+	}, F.Flow2(E.Of[error, []discovered], IO.Of[E.Either[error, []discovered]]))
+	return func(discover IOE.IOEither[error, []discovered]) IOE.IOEither[error, []discovered] {
+		return IOE.IOEither[error, []discovered](fallback(discover))
+	}
+}
+
+// DiscoverCertificates probes the URL template of the resolver for the versions that exist and returns their
+// certificates, see [C.DiscoveryConfig] for the probing strategy
+func DiscoverCertificates(client IOEH.Client, dl *C.DownloadConfig) func(resolver CE.Resolver) func(discovery *C.DiscoveryConfig) IOE.IOEither[error, []C.VersionCert] {
+	download := downloadWithRetry(client, dl)
+	return func(resolver CE.Resolver) func(discovery *C.DiscoveryConfig) IOE.IOEither[error, []C.VersionCert] {
+		return func(discovery *C.DiscoveryConfig) IOE.IOEither[error, []C.VersionCert] {
+			return F.Pipe2(
+				IOE.FromIO[error](IO.Now),
+				IOE.Chain(func(now time.Time) IOE.IOEither[error, []discovered] {
+					return discoverWithContext(dl, func(ctx context.Context) func(C.Version) IOE.IOEither[error, C.CacheEntry] {
+						return downloadVersion(download(ctx), resolver, now)
+					})(discovery)
+				}),
+				IOE.Map[error](A.Map(discoveredToVersionCert)),
+			)
+		}
+	}
+}
+
+// DiscoverCertificatesCached discovers the versions that exist like [DiscoverCertificates] through the local
+// certificate cache. Fresh cache entries are not downloaded again and stale entries are kept if their download fails.
+// If the discovery fails, e.g. offline, the cached versions are returned unless the cache is refreshed.
+func DiscoverCertificatesCached(client IOEH.Client, dl *C.DownloadConfig) func(cfg *C.CacheConfig) func(resolver CE.Resolver) func(discovery *C.DiscoveryConfig) IOE.IOEither[error, []C.VersionCert] {
+	download := downloadWithRetry(client, dl)
+	return func(cfg *C.CacheConfig) func(resolver CE.Resolver) func(discovery *C.DiscoveryConfig) IOE.IOEither[error, []C.VersionCert] {
+		return func(resolver CE.Resolver) func(discovery *C.DiscoveryConfig) IOE.IOEither[error, []C.VersionCert] {
+			return func(discovery *C.DiscoveryConfig) IOE.IOEither[error, []C.VersionCert] {
+				return F.Pipe1(
+					IOE.SequenceT2(ReadCacheIndex(cfg.Dir), IOE.FromIO[error](IO.Now)),
+					IOE.Chain(T.Tupled2(func(index C.CacheIndex, now time.Time) IOE.IOEither[error, []C.VersionCert] {
+						discover := discoverWithContext(dl, func(ctx context.Context) func(C.Version) IOE.IOEither[error, C.CacheEntry] {
+							return cachedVersion(download(ctx), cfg, resolver, index, now)
+						})(discovery)
+						if !cfg.Refresh {
+							discover = orCached(cachedDiscovered(resolver, index, discovery))(discover)
+						}
+						return F.Pipe2(
+							discover,
+							IOE.ChainFirst(F.Flow2(
+								A.Map(discoveredToCachedResult),
+								updateCacheIndex(cfg.Dir, index),
+							)),
+							IOE.Map[error](A.Map(discoveredToVersionCert)),
+						)
+					})),
+				)
+			}
+		}
+	}
+}
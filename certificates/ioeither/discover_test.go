@@ -0,0 +1,148 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	IOEH "github.com/IBM/fp-go/ioeither/http"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+	CE "github.com/ibm-hyper-protect/contract-go/certificates/either"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// availableVersions mimics the gaps of the published HPCR certificates
+var availableVersions = A.From("1.0.2", "1.0.5", "1.0.6", "1.0.7", "1.0.8", "1.0.9", "1.0.10", "1.0.11", "1.0.12", "1.1.0")
+
+// versionServer serves a certificate for each of the versions and counts the requests
+func versionServer(t *testing.T, versions []string) (*httptest.Server, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		version := strings.TrimPrefix(r.URL.Path, "/")
+		for _, v := range versions {
+			if v == version {
+				fmt.Fprintf(w, "cert %s", version)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func discoveryResolver(server *httptest.Server) CE.Resolver {
+	return CE.ParseResolver(server.URL + "/{{.Major}}.{{.Minor}}.{{.Patch}}")
+}
+
+func TestDiscoverCertificates(t *testing.T) {
+	server, hits := versionServer(t, availableVersions)
+	discovery := &C.DiscoveryConfig{Start: C.DefaultDiscoveryConfig.Start, MaxMisses: 3}
+
+	certs, err := E.UnwrapError(DiscoverCertificates(IOEH.MakeClient(http.DefaultClient), testDownloadConfig)(discoveryResolver(server))(discovery)())
+	require.NoError(t, err)
+
+	assert.Equal(t, availableVersions, A.Map(func(cert C.VersionCert) string {
+		return cert.F1.String()
+	})(certs))
+	assert.Equal(t, "cert 1.0.10", certs[6].F2)
+	// 1.0.0-1.0.15 with 3 trailing misses, 1.1.0-1.1.3 and 1.2.0-1.2.2
+	assert.Equal(t, int32(16+4+3), *hits)
+}
+
+func TestDiscoverCertificatesStopsAtMisses(t *testing.T) {
+	server, _ := versionServer(t, availableVersions)
+	discovery := &C.DiscoveryConfig{Start: C.DefaultDiscoveryConfig.Start, MaxMisses: 2}
+
+	certs, err := E.UnwrapError(DiscoverCertificates(IOEH.MakeClient(http.DefaultClient), testDownloadConfig)(discoveryResolver(server))(discovery)())
+	require.NoError(t, err)
+
+	// 1.0.0 and 1.0.1 exhaust the misses before 1.0.2 is found
+	assert.Empty(t, certs)
+}
+
+func TestDiscoverCertificatesFailsOnServerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	discovery := &C.DiscoveryConfig{Start: C.DefaultDiscoveryConfig.Start, MaxMisses: 3}
+	res := DiscoverCertificates(IOEH.MakeClient(http.DefaultClient), testDownloadConfig)(discoveryResolver(server))(discovery)()
+
+	assert.True(t, E.IsLeft(res))
+}
+
+func TestDiscoverCertificatesCached(t *testing.T) {
+	server, _ := versionServer(t, availableVersions)
+	discovery := &C.DiscoveryConfig{Start: C.DefaultDiscoveryConfig.Start, MaxMisses: 3}
+	cfg := &C.CacheConfig{Dir: t.TempDir(), TTL: time.Hour}
+
+	_, err := E.UnwrapError(DiscoverCertificatesCached(IOEH.MakeClient(http.DefaultClient), testDownloadConfig)(cfg)(discoveryResolver(server))(discovery)())
+	require.NoError(t, err)
+
+	certs, err := E.UnwrapError(CertificatesFromCache(cfg.Dir)())
+	require.NoError(t, err)
+	assert.Len(t, certs, len(availableVersions))
+	assert.Equal(t, "cert 1.1.0", certs["1.1.0"])
+}
+
+func TestDiscoverCertificatesCachedUsesFreshEntries(t *testing.T) {
+	server, hits := versionServer(t, availableVersions)
+	discovery := &C.DiscoveryConfig{Start: C.DefaultDiscoveryConfig.Start, MaxMisses: 3}
+	cfg := &C.CacheConfig{Dir: t.TempDir(), TTL: time.Hour}
+	discover := DiscoverCertificatesCached(IOEH.MakeClient(http.DefaultClient), testDownloadConfig)(cfg)(discoveryResolver(server))(discovery)
+
+	_, err := E.UnwrapError(discover())
+	require.NoError(t, err)
+	atomic.StoreInt32(hits, 0)
+
+	certs, err := E.UnwrapError(discover())
+	require.NoError(t, err)
+	assert.Len(t, certs, len(availableVersions))
+	// only the missing versions are probed again
+	assert.Equal(t, int32(16+4+3-len(availableVersions)), *hits)
+}
+
+func TestDiscoverCertificatesCachedOffline(t *testing.T) {
+	server, _ := versionServer(t, availableVersions)
+	discovery := &C.DiscoveryConfig{Start: C.DefaultDiscoveryConfig.Start, MaxMisses: 3}
+	cfg := &C.CacheConfig{Dir: t.TempDir(), TTL: time.Hour}
+	discover := DiscoverCertificatesCached(IOEH.MakeClient(http.DefaultClient), testDownloadConfig)(cfg)(discoveryResolver(server))(discovery)
+
+	_, err := E.UnwrapError(discover())
+	require.NoError(t, err)
+	server.Close()
+
+	certs, err := E.UnwrapError(discover())
+	require.NoError(t, err)
+	assert.Equal(t, availableVersions, A.Map(func(cert C.VersionCert) string {
+		return cert.F1.String()
+	})(certs))
+
+	refresh := &C.CacheConfig{Dir: cfg.Dir, TTL: cfg.TTL, Refresh: true}
+	res := DiscoverCertificatesCached(IOEH.MakeClient(http.DefaultClient), testDownloadConfig)(refresh)(discoveryResolver(server))(discovery)()
+	assert.True(t, E.IsLeft(res))
+}
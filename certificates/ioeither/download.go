@@ -0,0 +1,146 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	H "github.com/IBM/fp-go/http"
+	IO "github.com/IBM/fp-go/io"
+	IOE "github.com/IBM/fp-go/ioeither"
+	IOEH "github.com/IBM/fp-go/ioeither/http"
+	R "github.com/IBM/fp-go/retry"
+	T "github.com/IBM/fp-go/tuple"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+	CE "github.com/ibm-hyper-protect/contract-go/certificates/either"
+	CIO "github.com/ibm-hyper-protect/contract-go/common/io"
+)
+
+// MakeHttpClient creates an HTTP client for certificate downloads. The client honours the proxy environment variables
+// (`HTTPS_PROXY`, `HTTP_PROXY` and `NO_PROXY`) and trusts the PEM encoded root CAs in addition to the system roots, e.g.
+// the CA of a TLS intercepting proxy.
+func MakeHttpClient(cfg *C.DownloadConfig) func(caCerts []byte) IOE.IOEither[error, *http.Client] {
+	return func(caCerts []byte) IOE.IOEither[error, *http.Client] {
+		return IOE.TryCatchError(func() (*http.Client, error) {
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.Proxy = http.ProxyFromEnvironment
+			if len(caCerts) > 0 {
+				pool, err := x509.SystemCertPool()
+				if err != nil {
+					pool = x509.NewCertPool()
+				}
+				if !pool.AppendCertsFromPEM(caCerts) {
+					return nil, fmt.Errorf("unable to parse any PEM encoded CA certificate")
+				}
+				transport.TLSClientConfig.RootCAs = pool
+			}
+			return &http.Client{
+				Transport: transport,
+				Timeout:   cfg.RequestTimeout,
+			}, nil
+		})
+	}
+}
+
+// isTransient tests if a download error is worth a retry, i.e. a network error, a request timeout or a server side
+// HTTP status. Errors after the end of the overall download context are final.
+func isTransient(ctx context.Context) func(error) bool {
+	return func(err error) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		var httpErr *H.HttpError
+		if errors.As(err, &httpErr) {
+			status := httpErr.StatusCode()
+			return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests || status == http.StatusRequestTimeout
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr)
+	}
+}
+
+// retryPolicy returns the retry policy for the config, the delay grows exponentially
+func retryPolicy(cfg *C.DownloadConfig) R.RetryPolicy {
+	return R.Monoid.Concat(
+		R.ExponentialBackoff(cfg.Backoff),
+		R.LimitRetries(uint(cfg.Retries)),
+	)
+}
+
+// downloadWithRetry downloads textual content from a URL in the context, transient errors are retried
+func downloadWithRetry(client IOEH.Client, cfg *C.DownloadConfig) func(ctx context.Context) func(url string) IOE.IOEither[error, string] {
+	policy := retryPolicy(cfg)
+	read := IOEH.ReadText(client)
+	return func(ctx context.Context) func(url string) IOE.IOEither[error, string] {
+		check := E.Fold(isTransient(ctx), F.Constant1[string](false))
+		return func(url string) IOE.IOEither[error, string] {
+			return IOE.Retrying(
+				policy,
+				func(R.RetryStatus) IOE.IOEither[error, string] {
+					return F.Pipe1(
+						makeGetRequestWithContext(ctx)(url),
+						read,
+					)
+				},
+				check,
+			)
+		}
+	}
+}
+
+// withDownloadContext runs a batch of downloads in a context that honours the overall timeout of the config
+func withDownloadContext[A any](cfg *C.DownloadConfig, f func(ctx context.Context) IO.IO[A]) IO.IO[A] {
+	return func() A {
+		ctx, cancel := context.WithCancel(context.Background())
+		if cfg.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), cfg.Timeout)
+		}
+		defer cancel()
+		return f(ctx)()
+	}
+}
+
+// downloadResults downloads versions in parallel with bounded concurrency, each download reports its own result
+func downloadResults[A any](cfg *C.DownloadConfig, download func(C.Version) IOE.IOEither[error, A]) func([]C.Version) IO.IO[[]T.Tuple2[C.Version, E.Either[error, A]]] {
+	limit := CIO.LimitConcurrency[T.Tuple2[C.Version, E.Either[error, A]]](cfg.Concurrency)
+	return IO.TraverseArray(func(version C.Version) IO.IO[T.Tuple2[C.Version, E.Either[error, A]]] {
+		return limit(IO.MakeIO(func() T.Tuple2[C.Version, E.Either[error, A]] {
+			return T.MakeTuple2(version, download(version)())
+		}))
+	})
+}
+
+// DownloadCertificatesWithConfig downloads the certificates for the given versions in parallel and retries transient
+// errors. A failed download does not abort the others, the result of each version is reported individually.
+func DownloadCertificatesWithConfig(client IOEH.Client, cfg *C.DownloadConfig) func(resolver CE.Resolver) func(versions []C.Version) IO.IO[[]C.DownloadResult] {
+	download := downloadWithRetry(client, cfg)
+	return func(resolver CE.Resolver) func(versions []C.Version) IO.IO[[]C.DownloadResult] {
+		return func(versions []C.Version) IO.IO[[]C.DownloadResult] {
+			return withDownloadContext(cfg, func(ctx context.Context) IO.IO[[]C.DownloadResult] {
+				return downloadResults(cfg, F.Flow2(
+					resolver,
+					E.Fold(IOE.Left[string, error], download(ctx)),
+				))(versions)
+			})
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	IOEH "github.com/IBM/fp-go/ioeither/http"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+	CE "github.com/ibm-hyper-protect/contract-go/certificates/either"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func downloadWithConfig(t *testing.T, client *http.Client, url string, cfg *C.DownloadConfig, versions ...string) []C.DownloadResult {
+	resolver := CE.ParseResolver(url + "/{{.Major}}.{{.Minor}}.{{.Patch}}")
+
+	parsed, err := E.UnwrapError(E.TraverseArray(CE.ParseVersion)(versions))
+	require.NoError(t, err)
+
+	return DownloadCertificatesWithConfig(IOEH.MakeClient(client), cfg)(resolver)(parsed)()
+}
+
+func resultOf(result C.DownloadResult) (string, error) {
+	return E.UnwrapError(result.F2)
+}
+
+func TestDownloadRetriesTransientErrors(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "cert")
+	}))
+	defer server.Close()
+
+	cfg := &C.DownloadConfig{Concurrency: 1, Retries: 3, Backoff: time.Millisecond}
+	results := downloadWithConfig(t, http.DefaultClient, server.URL, cfg, "1.0.10")
+
+	require.Len(t, results, 1)
+	cert, err := resultOf(results[0])
+	require.NoError(t, err)
+	assert.Equal(t, "cert", cert)
+	assert.Equal(t, int32(3), hits)
+}
+
+func TestDownloadReportsFailuresPerVersion(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if strings.HasSuffix(r.URL.Path, "/1.0.11") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, "cert %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	cfg := &C.DownloadConfig{Concurrency: 2, Retries: 3, Backoff: time.Millisecond}
+	results := downloadWithConfig(t, http.DefaultClient, server.URL, cfg, "1.0.10", "1.0.11", "1.0.12")
+
+	require.Len(t, results, 3)
+	partition := C.PartitionDownloads(results)
+	require.Len(t, partition.F1, 1)
+	assert.Contains(t, partition.F1[0].Error(), "1.0.11")
+	assert.Equal(t, A.From("cert /1.0.10", "cert /1.0.12"), certificates(partition.F2))
+	// client errors are not retried
+	assert.Equal(t, int32(3), hits)
+}
+
+func TestDownloadBoundedConcurrency(t *testing.T) {
+	var mutex sync.Mutex
+	running, peak := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mutex.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+		fmt.Fprint(w, "cert")
+	}))
+	defer server.Close()
+
+	cfg := &C.DownloadConfig{Concurrency: 2}
+	results := downloadWithConfig(t, http.DefaultClient, server.URL, cfg, "1.0.2", "1.0.5", "1.0.6", "1.0.7", "1.0.8", "1.0.9")
+
+	assert.Empty(t, C.PartitionDownloads(results).F1)
+	assert.LessOrEqual(t, peak, 2)
+	assert.Greater(t, peak, 0)
+}
+
+func TestDownloadTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	t.Run("request", func(t *testing.T) {
+		cfg := &C.DownloadConfig{Concurrency: 1, RequestTimeout: 50 * time.Millisecond}
+		client, err := E.UnwrapError(MakeHttpClient(cfg)(nil)())
+		require.NoError(t, err)
+
+		results := downloadWithConfig(t, client, server.URL, cfg, "1.0.10")
+		_, err = resultOf(results[0])
+		assert.Error(t, err)
+	})
+
+	t.Run("overall", func(t *testing.T) {
+		cfg := &C.DownloadConfig{Concurrency: 1, Retries: 10, Backoff: time.Millisecond, Timeout: 100 * time.Millisecond}
+
+		start := time.Now()
+		results := downloadWithConfig(t, http.DefaultClient, server.URL, cfg, "1.0.10", "1.0.11")
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Len(t, C.PartitionDownloads(results).F1, 2)
+	})
+}
+
+func TestMakeHttpClientCustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "cert")
+	}))
+	defer server.Close()
+
+	cfg := &C.DownloadConfig{Concurrency: 1}
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	trusted, err := E.UnwrapError(MakeHttpClient(cfg)(caCert)())
+	require.NoError(t, err)
+	cert, err := resultOf(downloadWithConfig(t, trusted, server.URL, cfg, "1.0.10")[0])
+	require.NoError(t, err)
+	assert.Equal(t, "cert", cert)
+
+	untrusted, err := E.UnwrapError(MakeHttpClient(cfg)(nil)())
+	require.NoError(t, err)
+	_, err = resultOf(downloadWithConfig(t, untrusted, server.URL, cfg, "1.0.10")[0])
+	assert.Error(t, err)
+
+	_, err = E.UnwrapError(MakeHttpClient(cfg)([]byte("no certificate"))())
+	assert.Error(t, err)
+}
+
+func TestMakeHttpClientProxy(t *testing.T) {
+	client, err := E.UnwrapError(MakeHttpClient(&C.DefaultDownloadConfig)(nil)())
+	require.NoError(t, err)
+
+	transport, ok := client.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.NotNil(t, transport.Proxy)
+	assert.Equal(t, C.DefaultDownloadConfig.RequestTimeout, client.Timeout)
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificates
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// HpcrEncryptionCommonName is the common name of the subject of HPCR contract encryption certificates
+	HpcrEncryptionCommonName = "Hyper Protect Container Runtime Contract Encryption"
+	// HpcrLegacyEncryptionCommonName is the common name of the subject of early HPCR contract encryption certificates,
+	// e.g. the ones of 1.0.2 and 1.0.5
+	HpcrLegacyEncryptionCommonName = "contract-decryption"
+	// HpcrOrganizationPrefix is the prefix of the organization of the subject of HPCR contract encryption certificates
+	HpcrOrganizationPrefix = "IBM"
+	// DefaultMinKeySize is the minimum size in bits of the RSA key of an HPCR contract encryption certificate
+	DefaultMinKeySize = 4096
+)
+
+var (
+	// HpcrEncryptionCommonNames are the common names that IBM issued HPCR contract encryption certificates for
+	HpcrEncryptionCommonNames = []string{HpcrEncryptionCommonName, HpcrLegacyEncryptionCommonName}
+)
+
+type (
+	// VerifyConfig specifies how an encryption certificate is verified, the verification works offline
+	VerifyConfig struct {
+		Bundle     []byte    // PEM encoded intermediate and root certificates, self-signed certificates are trusted as roots
+		CRL        []byte    // optional PEM or DER encoded certificate revocation list of the issuer
+		Time       time.Time // time of the verification, the zero value denotes the current time
+		MinKeySize int       // minimum size of the RSA key in bits
+	}
+
+	// CertificateVerification is the result of the verification of an encryption certificate
+	CertificateVerification struct {
+		Subject   string    `json:"subject" yaml:"subject"`
+		Issuer    string    `json:"issuer" yaml:"issuer"`
+		NotBefore time.Time `json:"notBefore" yaml:"notBefore"`
+		NotAfter  time.Time `json:"notAfter" yaml:"notAfter"`
+		KeySize   int       `json:"keySize,omitempty" yaml:"keySize,omitempty"`
+		Problems  []string  `json:"problems,omitempty" yaml:"problems,omitempty"`
+	}
+)
+
+// Valid tests if the verification did not find any problem
+func (v *CertificateVerification) Valid() bool {
+	return len(v.Problems) == 0
+}
+
+// ToError returns an error that lists the problems of an invalid certificate or nil for a valid certificate
+func (v *CertificateVerification) ToError() error {
+	if v.Valid() {
+		return nil
+	}
+	return fmt.Errorf("the encryption certificate [%s] is invalid: %s", v.Subject, strings.Join(v.Problems, "; "))
+}
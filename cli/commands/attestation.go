@@ -0,0 +1,45 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	F "github.com/IBM/fp-go/function"
+	AT "github.com/ibm-hyper-protect/contract-go/attestation"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// AttestationCommand returns a command that verifies an attestation record against a contract
+func AttestationCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "attestation",
+		Usage:       "verify an attestation record against a contract",
+		Description: "Decrypts the attestation record (se-checksums.txt.enc) with the attestation private key and compares its checksums with the encrypted contract",
+		Flags: []cli.Flag{
+			flagInput,
+			flagContract,
+			flagOutput,
+			flagFormat,
+			flagMode,
+			flagDecryptionKey,
+			flagDecryptionKeyFile,
+		},
+		Action: F.Flow2(
+			AttestationAndWriteFromContext,
+			U.RunIOEither[*AT.Verification],
+		),
+	}
+}
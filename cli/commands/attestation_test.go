@@ -0,0 +1,98 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	AT "github.com/ibm-hyper-protect/contract-go/attestation"
+	ATE "github.com/ibm-hyper-protect/contract-go/attestation/either"
+	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// createAttestationRecord simulates the attestation record that HPCR creates for the user data
+func createAttestationRecord(t *testing.T, userData []byte, pubKey []byte, recordName string) {
+	contract, err := E.UnwrapError(Y.Parse[SC.EncryptedContract](userData))
+	require.NoError(t, err)
+
+	lines := A.From(
+		"23.11.0",
+		"Machine Type/Plant/Serial: 3931/02/8A018",
+		fmt.Sprintf("%s  %s", ATE.Sha256(userData), AT.ChecksumUserData),
+		fmt.Sprintf("%s  %s", ATE.Sha256([]byte(contract[SC.KeyWorkload])), AT.ChecksumWorkload),
+		fmt.Sprintf("%s  %s", ATE.Sha256([]byte(contract[SC.KeyEnv])), AT.ChecksumEnv),
+		fmt.Sprintf("%s  %s", ATE.Sha256([]byte(contract[SC.KeyEnvWorkloadSignature])), AT.ChecksumEnvWorkloadSignature),
+	)
+
+	token, err := E.UnwrapError(Encrypt.CryptoEncryptBasic(pubKey)([]byte(strings.Join(lines, "\n")))())
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(recordName, []byte(token), 0600))
+}
+
+func TestAttestationCommand(t *testing.T) {
+	inName := "../samples/simple.yaml"
+	encName := "../../build/TestAttestationCommand.encrypted.yaml"
+	recordName := "../../build/TestAttestationCommand.se-checksums.txt.enc"
+	outName := "../../build/TestAttestationCommand.yaml"
+
+	privKeyName, pubKeyName := createTestKeyPair(t, "TestAttestationCommand")
+
+	encCmd := EncryptAndSignCommand()
+	attCmd := AttestationCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.From(encCmd, attCmd),
+	}
+
+	require.NoError(t, app.Run(A.From(os.Args[0], encCmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), encName)))
+
+	userData, err := os.ReadFile(encName)
+	require.NoError(t, err)
+	pubKey, err := os.ReadFile(pubKeyName)
+	require.NoError(t, err)
+
+	createAttestationRecord(t, userData, pubKey, recordName)
+
+	attArgs := A.From(os.Args[0], attCmd.Name, fmt.Sprintf("--%s", flagInput.Name), recordName, fmt.Sprintf("--%s", flagContract.Name), encName, fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagDecryptionKeyFile.Name), privKeyName)
+	require.NoError(t, app.Run(attArgs))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	ver, err := E.UnwrapError(Y.Parse[AT.Verification](data))
+	require.NoError(t, err)
+	assert.True(t, ver.Valid)
+	assert.Len(t, ver.Comparisons, 4)
+
+	// the record does not match a different contract
+	otherName := "../../build/TestAttestationCommand.other.yaml"
+	require.NoError(t, app.Run(A.From(os.Args[0], encCmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), otherName)))
+
+	attArgs = A.From(os.Args[0], attCmd.Name, fmt.Sprintf("--%s", flagInput.Name), recordName, fmt.Sprintf("--%s", flagContract.Name), otherName, fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagDecryptionKeyFile.Name), privKeyName)
+	assert.Error(t, app.Run(attArgs))
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IO "github.com/IBM/fp-go/io"
+	IOE "github.com/IBM/fp-go/ioeither"
+	O "github.com/IBM/fp-go/option"
+	ORD "github.com/IBM/fp-go/ord"
+	RR "github.com/IBM/fp-go/record"
+	S "github.com/IBM/fp-go/string"
+	T "github.com/IBM/fp-go/tuple"
+	CIO "github.com/ibm-hyper-protect/contract-go/common/io"
+	CF "github.com/ibm-hyper-protect/contract-go/file"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	SVIOE "github.com/ibm-hyper-protect/contract-go/service/ioeither"
+	TA "github.com/ibm-hyper-protect/contract-go/tar"
+	TAIOE "github.com/ibm-hyper-protect/contract-go/tar/ioeither"
+	TPL "github.com/ibm-hyper-protect/contract-go/template"
+	TPLE "github.com/ibm-hyper-protect/contract-go/template/either"
+	"github.com/ibm-hyper-protect/contract-go/types"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/urfave/cli/v2"
+)
+
+type (
+	// batchFile is the result of the encryption of a single file of a batch
+	batchFile = T.Tuple2[string, E.Either[error, []byte]]
+)
+
+var (
+	// contractExtensions are the extensions of the files of a batch that are encrypted, other files are skipped
+	contractExtensions = A.From(".yaml", ".yml", ".json")
+
+	// ordFileEntry sorts the files of a batch by their name
+	ordFileEntry = ORD.Contramap(T.First[string, []byte])(S.Ord)
+)
+
+// isContractFile tests if a file of a batch has the extension of a contract
+func isContractFile(name string) bool {
+	return F.Pipe2(
+		contractExtensions,
+		A.Filter(S.Equals(strings.ToLower(filepath.Ext(name)))),
+		A.IsNonEmpty[string],
+	)
+}
+
+// isContractData tests if the content of a file of a batch may be a contract. Documents without a [SC.KeyEnv] or
+// [SC.KeyWorkload] section, such as the compose files of the folders referenced by the contracts, are skipped. Content
+// that cannot be parsed is kept, so a broken contract is reported
+func isContractData(data []byte) bool {
+	return F.Pipe2(
+		Y.Parse[map[string]any](data),
+		E.ToOption[error, map[string]any],
+		O.Fold(F.Constant(true), func(doc map[string]any) bool {
+			return O.IsSome(RR.Lookup[any](SC.KeyEnv)(doc)) || O.IsSome(RR.Lookup[any](SC.KeyWorkload)(doc))
+		}),
+	)
+}
+
+// isBatchContract tests if a file of a batch is a contract, other files such as the compose folders referenced by the
+// contracts are skipped
+func isBatchContract(entry TA.FileEntry) bool {
+	return isContractFile(entry.F1) && isContractData(entry.F2)
+}
+
+// isDirectory tests if the input of a batch is an existing directory
+func isDirectory(input string) bool {
+	if input == CF.StdInOutIdentifier {
+		return false
+	}
+	status, err := os.Stat(input)
+	return err == nil && status.IsDir()
+}
+
+// isTarOutput tests if the output of a batch is a tar file or stdout, all other outputs are directories
+func isTarOutput(output string) bool {
+	return output == CF.StdInOutIdentifier || strings.HasSuffix(strings.ToLower(output), ".tar")
+}
+
+// batchInput reads the files of a batch from a directory or from a tar file or stdin
+func batchInput(input string) IOE.IOEither[error, TA.FileList] {
+	if isDirectory(input) {
+		return TAIOE.FromFolder(input)
+	}
+	return TAIOE.UnmarshalFromInput(input)
+}
+
+// batchBaseDir returns the folder that the folders referenced by the contracts of a batch are resolved against
+func batchBaseDir(input string) string {
+	if isDirectory(input) {
+		return input
+	}
+	return "."
+}
+
+// batchOutput writes the encrypted contracts of a batch as a tar file or into a directory
+func batchOutput(output string) func(TA.FileList) IOE.IOEither[error, TA.FileList] {
+	if isTarOutput(output) {
+		return func(files TA.FileList) IOE.IOEither[error, TA.FileList] {
+			return F.Pipe1(
+				TAIOE.MarshalToOutput(output)(files),
+				IOE.Map[error](F.Constant1[[]byte](files)),
+			)
+		}
+	}
+	return TAIOE.ExtractToFolder(output, os.ModePerm)
+}
+
+// encryptBatchFile renders, validates, encrypts and serializes a single contract of a batch
+func encryptBatchFile(
+	render func([]byte) E.Either[error, []byte],
+	enc SVIOE.ContractEncrypter,
+	serialize func(SC.EncryptedContract) E.Either[error, []byte],
+	baseDir string,
+) func(name string, data []byte) IOE.IOEither[error, []byte] {
+	return func(name string, data []byte) IOE.IOEither[error, []byte] {
+		return F.Pipe4(
+			IOE.FromEither(render(data)),
+			inlinedContractFromSource(filepath.Join(baseDir, name)),
+			IOE.ChainEitherK(types.ValidateContract),
+			IOE.Chain(enc),
+			IOE.ChainEitherK(serialize),
+		)
+	}
+}
+
+// encryptBatch encrypts the contracts of a batch in parallel with bounded concurrency and pairs the name of each
+// contract with its outcome
+func encryptBatch(concurrency int, encrypt func(name string, data []byte) IOE.IOEither[error, []byte]) func(TA.FileList) IO.IO[[]batchFile] {
+	limit := CIO.LimitConcurrency[batchFile](concurrency)
+	return F.Flow4(
+		RR.ToEntries[string, []byte],
+		A.Filter(isBatchContract),
+		A.Sort(ordFileEntry),
+		IO.TraverseArray(func(entry TA.FileEntry) IO.IO[batchFile] {
+			return limit(IO.MakeIO(func() batchFile {
+				return T.MakeTuple2(entry.F1, encrypt(entry.F1, entry.F2)())
+			}))
+		}),
+	)
+}
+
+// batchResult summarizes the results of a batch
+func batchResult(files []batchFile) *BatchResult {
+	res := &BatchResult{Total: len(files), Files: A.Empty[BatchEntry]()}
+	for _, file := range files {
+		entry := BatchEntry{File: file.F1}
+		if err := E.ToError(file.F2); err != nil {
+			entry.Error = err.Error()
+			res.Failed++
+		} else {
+			res.Encrypted++
+		}
+		res.Files = append(res.Files, entry)
+	}
+	return res
+}
+
+// encryptedFiles collects the encrypted contracts of a batch into a file list
+func encryptedFiles(files []batchFile) TA.FileList {
+	return F.Pipe2(
+		files,
+		A.FilterMap(func(file batchFile) O.Option[TA.FileEntry] {
+			return F.Pipe2(
+				file.F2,
+				E.ToOption[error, []byte],
+				O.Map(TA.CreateEntry(file.F1)),
+			)
+		}),
+		TA.FromEntries,
+	)
+}
+
+// writeBatch writes the encrypted contracts of a batch and summarizes the results
+func writeBatch(write func(TA.FileList) IOE.IOEither[error, TA.FileList]) func([]batchFile) IOE.IOEither[error, *BatchResult] {
+	return func(files []batchFile) IOE.IOEither[error, *BatchResult] {
+		return F.Pipe2(
+			encryptedFiles(files),
+			write,
+			IOE.Map[error](F.Constant1[TA.FileList](batchResult(files))),
+		)
+	}
+}
+
+// BatchEncryptFromContext encrypts all contracts of the directory or tar file on the [cli.Context] and writes the
+// encrypted contracts. Failures of single contracts are reported in the summary.
+func BatchEncryptFromContext(ctx *cli.Context) IOE.IOEither[error, *BatchResult] {
+	cfg := EncryptAndSignConfigFromContext(ctx)
+	tmpl := TemplateConfigFromContext(ctx)
+	input := lookupInput(ctx)
+
+	return F.Pipe1(
+		IOE.SequenceT3(
+			ContractEncrypterFromConfig(cfg),
+			TemplateVarsFromConfig(tmpl),
+			batchInput(input),
+		),
+		IOE.Chain(T.Tupled3(func(enc SVIOE.ContractEncrypter, vars TPL.Vars, files TA.FileList) IOE.IOEither[error, *BatchResult] {
+			encrypt := encryptBatchFile(
+				TPLE.RenderTemplate(tmpl.Syntax)(vars),
+				enc,
+				getSerializer[SC.EncryptedContract](lookupFormat(ctx)),
+				batchBaseDir(input),
+			)
+			return F.Pipe3(
+				files,
+				encryptBatch(lookupConcurrency(ctx), encrypt),
+				IOE.FromIO[error, []batchFile],
+				IOE.Chain(writeBatch(batchOutput(lookupOutput(ctx)))),
+			)
+		})),
+	)
+}
+
+// textBatchResult renders the summary of a batch as human readable text
+func textBatchResult(res *BatchResult) []byte {
+	var buf bytes.Buffer
+	for _, file := range res.Files {
+		if file.Error != "" {
+			fmt.Fprintf(&buf, "%s: failed: %s\n", file.File, file.Error)
+		} else {
+			fmt.Fprintf(&buf, "%s: encrypted\n", file.File)
+		}
+	}
+	fmt.Fprintf(&buf, "encrypted %d of %d contract(s)\n", res.Encrypted, res.Total)
+	return buf.Bytes()
+}
+
+// writeBatchSummary writes the summary of a batch to the summary file or as text to stderr
+func writeBatchSummary(ctx *cli.Context) func(*BatchResult) IOE.IOEither[error, []byte] {
+	return func(res *BatchResult) IOE.IOEither[error, []byte] {
+		return F.Pipe1(
+			lookupSummary(ctx),
+			O.Fold(func() IOE.IOEither[error, []byte] {
+				return IOE.TryCatchError(func() ([]byte, error) {
+					data := textBatchResult(res)
+					_, err := ctx.App.ErrWriter.Write(data)
+					return data, err
+				})
+			}, func(summary string) IOE.IOEither[error, []byte] {
+				return F.Pipe2(
+					res,
+					getSerializer[*BatchResult](lookupFormat(ctx)),
+					E.Fold(IOE.Left[[]byte, error], getWriter(summary)),
+				)
+			}),
+		)
+	}
+}
+
+// batchResultToEither fails if any contract of the batch could not be encrypted
+func batchResultToEither(res *BatchResult) E.Either[error, *BatchResult] {
+	if res.Failed == 0 {
+		return E.Of[error](res)
+	}
+	return E.Left[*BatchResult](fmt.Errorf("%d of %d contract(s) failed to encrypt", res.Failed, res.Total))
+}
+
+// summarizeBatch writes the summary of a batch. The summary is written in any case, the result fails if any contract
+// could not be encrypted
+func summarizeBatch(ctx *cli.Context) func(IOE.IOEither[error, *BatchResult]) IOE.IOEither[error, []byte] {
+	return F.Flow2(
+		IOE.ChainFirst(writeBatchSummary(ctx)),
+		IOE.ChainEitherK(F.Flow2(
+			batchResultToEither,
+			E.Chain(getSerializer[*BatchResult](lookupFormat(ctx))),
+		)),
+	)
+}
+
+// BatchEncryptAndWriteFromContext encrypts a batch of contracts and writes the summary
+func BatchEncryptAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe1(
+		BatchEncryptFromContext(ctx),
+		summarizeBatch(ctx),
+	)
+}
+
+// EncryptAndWriteFromContext encrypts a single contract or, in batch mode, all contracts of a directory or tar file
+func EncryptAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	if lookupBatch(ctx) {
+		return BatchEncryptAndWriteFromContext(ctx)
+	}
+	return EncryptSignAndWriteFromContext(ctx)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	RR "github.com/IBM/fp-go/record"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	TA "github.com/ibm-hyper-protect/contract-go/tar"
+	TAIOE "github.com/ibm-hyper-protect/contract-go/tar/ioeither"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// createTestBatch creates a file list with valid and broken contracts, a file that is not a contract and a compose
+// folder next to the contracts
+func createTestBatch(t *testing.T) TA.FileList {
+	simple, err := os.ReadFile("../samples/simple.yaml")
+	require.NoError(t, err)
+	invalid, err := os.ReadFile("../samples/invalid.yaml")
+	require.NoError(t, err)
+
+	return F.Pipe5(
+		TA.Empty,
+		TA.UpsertBytes("prod/app.yaml")(simple),
+		TA.UpsertBytes("test/app.yml")(simple),
+		TA.UpsertBytes("broken.yaml")(invalid),
+		TA.UpsertString("README.md")("not a contract"),
+		TA.UpsertString("hello/docker-compose.yml")("services:\n  hello:\n    image: docker.io/library/hello-world\n"),
+	)
+}
+
+// runBatch runs the encrypt command in batch mode
+func runBatch(inName, outName string, extra ...string) error {
+	cmd := EncryptAndSignCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	return app.Run(append(A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagBatch.Name), fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), outName), extra...))
+}
+
+func TestEncryptBatchDirectory(t *testing.T) {
+	inDir := t.TempDir()
+	outDir := "../../build/TestEncryptBatchDirectory"
+	summaryName := "../../build/TestEncryptBatchDirectory.summary.json"
+
+	require.NoError(t, os.RemoveAll(outDir))
+	_, err := E.UnwrapError(TAIOE.ExtractToFolder(inDir, os.ModePerm)(createTestBatch(t))())
+	require.NoError(t, err)
+
+	encKeyName, encPubKeyName := createTestKeyPair(t, "TestEncryptBatchDirectory")
+
+	// the broken contract fails the batch but does not stop the others
+	err = runBatch(inDir, outDir, fmt.Sprintf("--%s", flagCertFile.Name), encPubKeyName, fmt.Sprintf("--%s", flagSummary.Name), summaryName, fmt.Sprintf("--%s", flagFormat.Name), FormatJson, fmt.Sprintf("--%s", flagConcurrency.Name), "2")
+	assert.Error(t, err)
+
+	data, err := os.ReadFile(summaryName)
+	require.NoError(t, err)
+	summary, err := E.UnwrapError(Y.Parse[BatchResult](data))
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, summary.Total)
+	assert.Equal(t, 2, summary.Encrypted)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, "broken.yaml", summary.Files[0].File)
+	assert.NotEmpty(t, summary.Files[0].Error)
+
+	for _, name := range A.From("prod/app.yaml", "test/app.yml") {
+		data, err := os.ReadFile(filepath.Join(outDir, name))
+		require.NoError(t, err)
+		encrypted, err := E.UnwrapError(Y.Parse[SC.EncryptedContract](data))
+		require.NoError(t, err)
+		assert.Contains(t, decryptToken(t, encKeyName, encrypted[SC.KeyWorkload]), "compose")
+	}
+	assert.NoFileExists(t, filepath.Join(outDir, "broken.yaml"))
+	assert.NoFileExists(t, filepath.Join(outDir, "README.md"))
+	assert.NoFileExists(t, filepath.Join(outDir, "hello", "docker-compose.yml"))
+}
+
+func TestEncryptBatchTar(t *testing.T) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	inName := "../../build/TestEncryptBatchTar.in.tar"
+	outName := "../../build/TestEncryptBatchTar.out.tar"
+
+	batch := F.Pipe1(
+		createTestBatch(t),
+		RR.DeleteAt[string, []byte]("broken.yaml"),
+	)
+	_, err := E.UnwrapError(TAIOE.MarshalToFile(inName)(batch)())
+	require.NoError(t, err)
+
+	require.NoError(t, runBatch(inName, outName))
+
+	encrypted, err := E.UnwrapError(TAIOE.UnmarshalFromFile(outName)())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, A.From("prod/app.yaml", "test/app.yml"), RR.Keys(encrypted))
+}
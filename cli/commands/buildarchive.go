@@ -0,0 +1,45 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	F "github.com/IBM/fp-go/function"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// BuildArchiveCommand returns a command that replaces folder references in a contract by their archives
+func BuildArchiveCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "build-archive",
+		Usage:       "inline compose and play folders of a contract as archives",
+		Description: "Replaces the folder of the compose or play section of a plaintext HPCR contract by the base64 encoded tgz archive of that folder, or archives a single folder",
+		Flags: []cli.Flag{
+			flagInput,
+			flagOutput,
+			flagFormat,
+			flagFolder,
+			flagVar,
+			flagVarFile,
+			flagDotEnv,
+			flagTemplateSyntax,
+		},
+		Action: F.Flow2(
+			BuildArchiveAndWriteFromContext,
+			U.RunIOEither[[]byte],
+		),
+	}
+}
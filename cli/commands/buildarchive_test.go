@@ -0,0 +1,86 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	"github.com/ibm-hyper-protect/contract-go/types"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestBuildArchiveCommand(t *testing.T) {
+	outName := "../../build/TestBuildArchiveCommand.yaml"
+
+	cmd := BuildArchiveCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	require.NoError(t, app.Run(A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), "../samples/folder.yaml", fmt.Sprintf("--%s", flagOutput.Name), outName)))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	contract, err := E.UnwrapError(Y.Parse[types.AnyMap](data))
+	require.NoError(t, err)
+
+	compose := contract["workload"].(types.AnyMap)["compose"].(types.AnyMap)
+	assert.NotContains(t, compose, "folder")
+	assert.NotEmpty(t, compose["archive"])
+}
+
+func TestBuildArchiveCommandFolder(t *testing.T) {
+	outName := "../../build/TestBuildArchiveCommandFolder.txt"
+
+	cmd := BuildArchiveCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	require.NoError(t, app.Run(A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagFolder.Name), "../../samples/hello-world", fmt.Sprintf("--%s", flagOutput.Name), outName)))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	_, err = base64.StdEncoding.DecodeString(string(data))
+	assert.NoError(t, err)
+}
+
+func TestEncryptWithFolder(t *testing.T) {
+	outName := "../../build/TestEncryptWithFolder.yaml"
+
+	cmd := EncryptAndSignCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	require.NoError(t, app.Run(A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), "../samples/folder.yaml", fmt.Sprintf("--%s", flagOutput.Name), outName)))
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	A "github.com/IBM/fp-go/array"
+	F "github.com/IBM/fp-go/function"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// VerifyCertificateCommand returns a command that verifies an encryption certificate
+func VerifyCertificateCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "verify",
+		Usage:       "verify an encryption certificate",
+		Description: "Verifies an HPCR encryption certificate offline against a bundle of intermediate and root certificates and an optional revocation list. Checks the chain, the validity window, the key size and the subject",
+		Flags: []cli.Flag{
+			flagInput,
+			flagOutput,
+			flagInspectFormat,
+			flagCertBundle,
+			flagCRL,
+			flagVerifyTime,
+			flagMinKeySize,
+		},
+		Action: F.Flow2(
+			VerifyCertificateAndWriteFromContext,
+			U.RunIOEither[[]byte],
+		),
+	}
+}
+
+// CertificatesCommand returns a command that groups the operations on encryption certificates
+func CertificatesCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "certificates",
+		Usage:       "work with encryption certificates",
+		Description: "Operations on HPCR encryption certificates",
+		Subcommands: A.From(
+			VerifyCertificateCommand(),
+		),
+	}
+}
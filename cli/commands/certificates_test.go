@@ -0,0 +1,104 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func runVerifyCertificate(outName string, extra ...string) error {
+	cmd := CertificatesCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	args := A.From(os.Args[0], cmd.Name, "verify", fmt.Sprintf("--%s", flagOutput.Name), outName)
+	return app.Run(append(args, extra...))
+}
+
+func TestVerifyCertificateCommand(t *testing.T) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	outName := "../../build/TestVerifyCertificateCommand.txt"
+
+	require.NoError(t, runVerifyCertificate(outName,
+		fmt.Sprintf("--%s", flagInput.Name), "../samples/pki/hpcr-encrypt.crt",
+		fmt.Sprintf("--%s", flagCertBundle.Name), "../samples/pki/bundle.crt",
+	))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+	text := string(data)
+
+	assert.Contains(t, text, "status: valid")
+	assert.Contains(t, text, "key size: 4096 bit")
+	assert.Contains(t, text, "CN=Hyper Protect Container Runtime Contract Encryption")
+}
+
+func TestVerifyCertificateCommandInvalid(t *testing.T) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	outName := "../../build/TestVerifyCertificateCommandInvalid.txt"
+
+	// the intermediate is missing from the bundle
+	assert.Error(t, runVerifyCertificate(outName,
+		fmt.Sprintf("--%s", flagInput.Name), "../samples/pki/hpcr-encrypt.crt",
+		fmt.Sprintf("--%s", flagCertBundle.Name), "../samples/pki/root.crt",
+	))
+
+	// the report is written nevertheless
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "status: invalid")
+
+	// a bundle is required
+	assert.Error(t, runVerifyCertificate(outName,
+		fmt.Sprintf("--%s", flagInput.Name), "../samples/pki/hpcr-encrypt.crt",
+	))
+}
+
+func TestEncryptCommandVerifyCert(t *testing.T) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	cmd := EncryptAndSignCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	args := A.From(
+		os.Args[0], cmd.Name,
+		fmt.Sprintf("--%s", flagInput.Name), "../samples/simple.yaml",
+		fmt.Sprintf("--%s", flagOutput.Name), "../../build/TestEncryptCommandVerifyCert.yaml",
+		fmt.Sprintf("--%s", flagVerifyCert.Name),
+		fmt.Sprintf("--%s", flagCertBundle.Name), "../samples/pki/bundle.crt",
+	)
+
+	assert.NoError(t, app.Run(append(args, fmt.Sprintf("--%s", flagCertFile.Name), "../samples/pki/hpcr-encrypt.crt")))
+
+	// the built-in certificate does not chain to the sample bundle
+	assert.Error(t, app.Run(args))
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	F "github.com/IBM/fp-go/function"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// CidataCommand returns a command that encrypts a contract and packages it as a NoCloud cidata ISO image
+func CidataCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "cidata",
+		Usage:       "encrypt a contract and package it as a cidata ISO image",
+		Description: "Encrypts an HPCR contract and writes a NoCloud cidata ISO image with the contract as user-data, together with meta-data and vendor-data",
+		Flags: []cli.Flag{
+			flagInput,
+			flagOutput,
+			flagMode,
+			flagPrivKey,
+			flagPrivKeyFile,
+			flagCert,
+			flagCertFile,
+			flagHpcrVersion,
+			flagCacheDir,
+			flagVerifyCert,
+			flagCertBundle,
+			flagCRL,
+			flagMinKeySize,
+			flagHostname,
+			flagVar,
+			flagVarFile,
+			flagDotEnv,
+			flagTemplateSyntax,
+		},
+		Action: F.Flow2(
+			CidataAndWriteFromContext,
+			U.RunIOEither[[]byte],
+		),
+	}
+}
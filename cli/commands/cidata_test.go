@@ -0,0 +1,65 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	O "github.com/IBM/fp-go/option"
+	ISO "github.com/ibm-hyper-protect/contract-go/iso9660"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCidataCommand(t *testing.T) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	outName := "../../build/TestCidataCommand.iso"
+
+	cmd := CidataCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	args := A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), "../samples/simple.yaml", fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagHostname.Name), "hpcr-test")
+	require.NoError(t, app.Run(args))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, len(data)%ISO.SectorSize)
+	assert.Equal(t, []byte("CD001"), data[16*ISO.SectorSize+1:16*ISO.SectorSize+6])
+	assert.True(t, bytes.Contains(data, []byte("hyper-protect-basic.")))
+	assert.True(t, bytes.Contains(data, []byte("local-hostname: hpcr-test")))
+	assert.True(t, bytes.Contains(data, []byte(cidataVendorData)))
+}
+
+func TestCidataFiles(t *testing.T) {
+	files, err := E.UnwrapError(cidataFiles(O.None[string]())([]byte("user")))
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("user"), files[ISO.FileUserData])
+	assert.Empty(t, files[ISO.FileMetaData])
+	assert.Equal(t, []byte(cidataVendorData), files[ISO.FileVendorData])
+}
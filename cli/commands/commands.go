@@ -16,13 +16,31 @@
 package commands
 
 import (
+	A "github.com/IBM/fp-go/array"
 	"github.com/urfave/cli/v2"
 )
 
-// Commands returns the array of supported commands
+// Commands returns the array of supported commands, the commands honour the profiles of the configuration file
 func Commands() []*cli.Command {
-	return []*cli.Command{
+	return A.Map(withProfile)([]*cli.Command{
 		EncryptAndSignCommand(),
+		EncryptStringCommand(),
+		GenerateCommand(),
+		SignCommand(),
+		DecryptCommand(),
+		VerifyCommand(),
+		ValidateCommand(),
+		KeygenCommand(),
+		AttestationCommand(),
+		BuildArchiveCommand(),
+		CidataCommand(),
+		InspectCommand(),
+		DiffCommand(),
+		MergeCommand(),
 		DownloadCertificatesCommand(),
-	}
+		CertificatesCommand(),
+		ServeCommand(),
+		TerraformExternalCommand(),
+		SelfTestCommand(),
+	})
 }
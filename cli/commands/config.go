@@ -16,12 +16,16 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	A "github.com/IBM/fp-go/array"
+	B "github.com/IBM/fp-go/bytes"
 	E "github.com/IBM/fp-go/either"
 	"github.com/IBM/fp-go/errors"
 	F "github.com/IBM/fp-go/function"
@@ -30,20 +34,34 @@ import (
 	IOE "github.com/IBM/fp-go/ioeither"
 	IOEH "github.com/IBM/fp-go/ioeither/http"
 	J "github.com/IBM/fp-go/json"
+	M "github.com/IBM/fp-go/monoid"
 	O "github.com/IBM/fp-go/option"
 	RR "github.com/IBM/fp-go/record"
 	S "github.com/IBM/fp-go/string"
 	T "github.com/IBM/fp-go/tuple"
 	"github.com/Masterminds/semver"
+	AIOE "github.com/ibm-hyper-protect/contract-go/archive/ioeither"
+	AT "github.com/ibm-hyper-protect/contract-go/attestation"
+	ATIOE "github.com/ibm-hyper-protect/contract-go/attestation/ioeither"
 	C "github.com/ibm-hyper-protect/contract-go/certificates"
 	CE "github.com/ibm-hyper-protect/contract-go/certificates/either"
 	CIOE "github.com/ibm-hyper-protect/contract-go/certificates/ioeither"
 	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	Common "github.com/ibm-hyper-protect/contract-go/common"
+	DF "github.com/ibm-hyper-protect/contract-go/diff"
+	DFE "github.com/ibm-hyper-protect/contract-go/diff/either"
 	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
 	CF "github.com/ibm-hyper-protect/contract-go/file"
 	CFIOE "github.com/ibm-hyper-protect/contract-go/file/ioeither"
+	ISO "github.com/ibm-hyper-protect/contract-go/iso9660"
+	ISOE "github.com/ibm-hyper-protect/contract-go/iso9660/either"
 	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	SVE "github.com/ibm-hyper-protect/contract-go/service/either"
 	SVIOE "github.com/ibm-hyper-protect/contract-go/service/ioeither"
+	TA "github.com/ibm-hyper-protect/contract-go/tar"
+	TPL "github.com/ibm-hyper-protect/contract-go/template"
+	TPLE "github.com/ibm-hyper-protect/contract-go/template/either"
+	TPLIOE "github.com/ibm-hyper-protect/contract-go/template/ioeither"
 	"github.com/ibm-hyper-protect/contract-go/types"
 	Y "github.com/ibm-hyper-protect/contract-go/yaml"
 	"github.com/urfave/cli/v2"
@@ -56,8 +74,24 @@ const (
 	ModeAuto    = "auto"
 
 	// serialization formats
-	FormatJson = "json"
-	FormatYaml = "yaml"
+	FormatJson  = "json"
+	FormatYaml  = "yaml"
+	FormatText  = "text"
+	FormatSarif = "sarif"
+
+	// purposes of generated keys
+	PurposeSigning     = "signing"
+	PurposeAttestation = "attestation"
+
+	// environment variable that carries the passphrase of a private key
+	EnvPassphrase = "CONTRACT_CLI_PASSPHRASE"
+	// environment variable that selects the profile of the configuration file
+	EnvProfile = "CONTRACT_CLI_PROFILE"
+	// environment variable that specifies the configuration file
+	EnvConfig = "CONTRACT_CLI_CONFIG"
+
+	// name of the project or user configuration file
+	ConfigFileName = ".contract-cli.yaml"
 )
 
 type (
@@ -73,14 +107,122 @@ type (
 	}
 
 	EncryptAndSignConfig struct {
+		Mode       string           // one of the mode flags
+		PrivKey    KeyConfig        // private key used for signing
+		PubCert    KeyConfig        // public key used for encryption
+		Section    O.Option[string] // encrypt only this section of the contract, without signature
+		Version    O.Option[string] // semantic version range of the HPCR image, selects a cached or built-in certificate
+		CacheDir   O.Option[string] // directory of the certificate cache, falls back to the default cache directory
+		VerifyCert bool             // refuse to encrypt with a certificate that does not pass the verification
+		Verify     CertVerifyConfig // verification of the encryption certificate
+	}
+
+	// CertVerifyConfig specifies the offline verification of an encryption certificate
+	CertVerifyConfig struct {
+		Bundle     O.Option[string]    // file with the PEM encoded intermediate and root certificates
+		CRL        O.Option[string]    // optional file with the revocation list of the issuer
+		Time       O.Option[time.Time] // time of the verification, defaults to the current time
+		MinKeySize int                 // minimum size of the RSA key in bits
+	}
+
+	DecryptConfig struct {
 		Mode    string    // one of the mode flags
-		PrivKey KeyConfig // private key used for signing
-		PubCert KeyConfig // public key used for encryption
+		PrivKey KeyConfig // private key used for decryption
+	}
+
+	VerifyConfig struct {
+		Mode       string           // one of the mode flags
+		SigningKey KeyConfig        // public key used to verify the signature
+		Plaintext  O.Option[string] // filename of the plaintext contract that carries the signing key
+	}
+
+	// VerifyResult is the report of a signature verification, the error explains why a signature is invalid
+	VerifyResult struct {
+		Valid                bool   `json:"valid" yaml:"valid"`
+		EnvWorkloadSignature string `json:"envWorkloadSignature" yaml:"envWorkloadSignature"`
+		Error                string `json:"error,omitempty" yaml:"error,omitempty"`
+	}
+
+	// ValidateResult is the report of a schema validation
+	ValidateResult struct {
+		Valid      bool              `json:"valid" yaml:"valid"`
+		Violations []types.Violation `json:"violations" yaml:"violations"`
+	}
+
+	AttestationConfig struct {
+		Mode     string    // one of the mode flags
+		PrivKey  KeyConfig // attestation private key used to decrypt the attestation record
+		Contract string    // filename of the encrypted contract that has been passed to the instance as user data
+	}
+
+	KeygenConfig struct {
+		Mode       string    // one of the mode flags
+		Purpose    string    // purpose of the key pair, one of the purpose flags
+		OutDir     string    // target folder for the key files
+		Passphrase KeyConfig // optional passphrase used to protect the private key
+	}
+
+	// KeygenResult is the report of a key generation
+	KeygenResult struct {
+		Purpose         string `json:"purpose" yaml:"purpose"`
+		ContractField   string `json:"contractField" yaml:"contractField"`
+		PrivateKey      string `json:"privateKey" yaml:"privateKey"`
+		PublicKey       string `json:"publicKey" yaml:"publicKey"`
+		FingerprintFile string `json:"fingerprintFile" yaml:"fingerprintFile"`
+		Fingerprint     string `json:"fingerprint" yaml:"fingerprint"`
+		Encrypted       bool   `json:"encrypted" yaml:"encrypted"`
+	}
+
+	// Profile maps the names of flags to the values that are used if the flag is not given explicitly. A map under the
+	// name of a command, e.g. `validate: {format: sarif}`, scopes settings to that command and its subcommands
+	Profile = map[string]any
+
+	// CliConfig is the content of a configuration file with named profiles
+	CliConfig struct {
+		DefaultProfile string             `json:"defaultProfile,omitempty" yaml:"defaultProfile,omitempty"` // profile used if none is selected
+		Profiles       map[string]Profile `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+	}
+
+	// TemplateConfig specifies the rendering of a contract template
+	TemplateConfig struct {
+		Syntax   string   // syntax of the placeholders, one of the template syntax flags
+		Vars     []string // variables given as NAME=VALUE
+		VarFiles []string // YAML or JSON files with variables
+		DotEnvs  []string // folders with a .env file with variables
+	}
+
+	// BatchEntry is the result of the encryption of a single contract of a batch
+	BatchEntry struct {
+		File  string `json:"file" yaml:"file"`
+		Error string `json:"error,omitempty" yaml:"error,omitempty"` // reason of the failure, empty if the contract has been encrypted
+	}
+
+	// BatchResult is the summary of a batch encryption
+	BatchResult struct {
+		Total     int          `json:"total" yaml:"total"`
+		Encrypted int          `json:"encrypted" yaml:"encrypted"`
+		Failed    int          `json:"failed" yaml:"failed"`
+		Files     []BatchEntry `json:"files" yaml:"files"`
+	}
+
+	// ServeConfig specifies the local HTTP encryption service
+	ServeConfig struct {
+		Addr            string               // address the service listens on
+		MaxBodySize     int64                // maximum size of a request body in bytes
+		ShutdownTimeout time.Duration        // time granted to running requests when the service shuts down
+		Encrypt         EncryptAndSignConfig // defaults of the encryption, requests may select a different HPCR version
 	}
 
 	DownloadCertificatesConfig struct {
-		Versions    []string // possible versions to download
-		UrlTemplate string   // the URL template for the download URL
+		Versions    []string         // possible versions to download, the versions are discovered if empty
+		Spec        string           // semantic version range of the certificates to download
+		MaxMisses   int              // number of consecutive missing patch versions that end the discovery of a minor version
+		UrlTemplate string           // the URL template for the download URL
+		CacheDir    O.Option[string] // directory of the certificate cache, falls back to the default cache directory
+		CacheTTL    time.Duration    // maximum age of cached certificates
+		Refresh     bool             // download certificates even if they are cached
+		Download    C.DownloadConfig // resilience of the downloads
+		CaCerts     []string         // files with additional PEM encoded root CAs
 	}
 )
 
@@ -91,6 +233,35 @@ var (
 	// valid formats
 	validFormats   = A.From(FormatJson, FormatYaml)
 	validateFormat = validateOneOfMany(validFormats)
+	// valid formats for validation reports
+	validReportFormats   = A.From(FormatText, FormatJson, FormatYaml, FormatSarif)
+	validateReportFormat = validateOneOfMany(validReportFormats)
+	// valid merge strategies
+	validateStrategy = validateOneOfMany(types.AllStrategies)
+	// valid formats for inspection reports
+	validInspectFormats   = A.From(FormatText, FormatJson, FormatYaml)
+	validateInspectFormat = validateOneOfMany(validInspectFormats)
+
+	// valid template syntaxes
+	validTemplateSyntaxes  = A.From(TPL.SyntaxShell, TPL.SyntaxGo)
+	validateTemplateSyntax = validateOneOfMany(validTemplateSyntaxes)
+
+	// sections that can be encrypted separately
+	validSections   = A.From(SC.KeyWorkload, SC.KeyEnv)
+	validateSection = validateOneOfMany(validSections)
+
+	// valid purposes
+	validPurposes   = A.From(PurposeSigning, PurposeAttestation)
+	validatePurpose = validateOneOfMany(validPurposes)
+
+	// keys of the query of the Terraform external data source
+	validTerraformKeys = A.From(Common.KeyContract, Common.KeyCert, Common.KeyPrivKey, Common.KeyHpcrVersion)
+
+	// purposeToContractField documents where the public key of a key pair goes in a contract
+	purposeToContractField = map[string]string{
+		PurposeSigning:     "env.signingKey",
+		PurposeAttestation: "attestationPublicKey",
+	}
 
 	// flagInput defines the CLI flag for the main input
 	flagInput = &cli.StringFlag{
@@ -141,6 +312,187 @@ var (
 	}
 	lookupPrivKeyFile = U.LookupStringFlagOpt(flagPrivKeyFile.Name)
 
+	// flagDecryptionKey defines the CLI flag for the private decryption key
+	flagDecryptionKey = &cli.StringFlag{
+		Name:      flagPrivKey.Name,
+		Aliases:   flagPrivKey.Aliases,
+		TakesFile: false,
+		Usage:     "Content of the private decryption key as a string",
+	}
+
+	// flagDecryptionKeyFile defines the CLI flag for the private decryption key file
+	flagDecryptionKeyFile = &cli.StringFlag{
+		Name:      flagPrivKeyFile.Name,
+		Aliases:   flagPrivKeyFile.Aliases,
+		Action:    validateInput,
+		TakesFile: true,
+		Usage:     "Private decryption key as a filepath",
+	}
+
+	// flagPassphrase defines the CLI flag for the passphrase of a private key
+	flagPassphrase = &cli.StringFlag{
+		Name:    "passphrase",
+		EnvVars: A.Of(EnvPassphrase),
+		Usage:   "Passphrase used to encrypt the private key. If absent the private key is stored unencrypted",
+	}
+	lookupPassphrase = U.LookupStringFlagOpt(flagPassphrase.Name)
+
+	// flagPassphraseFile defines the CLI flag for a file containing the passphrase of a private key
+	flagPassphraseFile = &cli.StringFlag{
+		Name:      "passphrasefile",
+		Action:    validateInput,
+		TakesFile: true,
+		Usage:     fmt.Sprintf("Passphrase used to encrypt the private key as a filepath or '%s' for stdin", CF.StdInOutIdentifier),
+	}
+	lookupPassphraseFile = U.LookupStringFlagOpt(flagPassphraseFile.Name)
+
+	// flagContract defines the CLI flag for the encrypted contract that has been passed to an instance
+	flagContract = &cli.StringFlag{
+		Name:      "contract",
+		Required:  true,
+		Action:    validateInput,
+		TakesFile: true,
+		Usage:     fmt.Sprintf("Encrypted contract that has been passed to the instance as user data as a filepath or '%s' for stdin", CF.StdInOutIdentifier),
+	}
+	lookupContract = U.LookupStringFlag(flagContract.Name)
+
+	// flagFolder defines the CLI flag for a folder that is archived
+	flagFolder = &cli.StringFlag{
+		Name:      "folder",
+		Action:    validateFolder,
+		TakesFile: true,
+		Usage:     "Folder to archive. If present the base64 encoded archive of the folder is written instead of the contract",
+	}
+	lookupFolder = U.LookupStringFlagOpt(flagFolder.Name)
+
+	// flagHostname defines the hostname recorded in the meta data of a cidata image
+	flagHostname = &cli.StringFlag{
+		Name:  "hostname",
+		Usage: "Hostname of the instance, recorded as 'local-hostname' in the meta data of the cidata image",
+	}
+	lookupHostname = U.LookupStringFlagOpt(flagHostname.Name)
+
+	// flagShowSecrets reveals secret values in a diff report
+	flagShowSecrets = &cli.BoolFlag{
+		Name:  "show-secrets",
+		Usage: "Show secret values such as passwords, volume seeds and environment variables in the report instead of masking them",
+	}
+	lookupShowSecrets = U.LookupBoolFlag(flagShowSecrets.Name)
+
+	// flagStrategy selects the strategy used to merge contracts
+	flagStrategy = &cli.StringFlag{
+		Name:   "strategy",
+		Action: validateStrategy,
+		Value:  types.StrategyLastWins,
+		Usage:  fmt.Sprintf("Strategy used to merge the contracts, valid values are %s", types.AllStrategies),
+	}
+	lookupStrategy = U.LookupStringFlag(flagStrategy.Name)
+
+	// flagProfile selects the profile of the configuration file
+	flagProfile = &cli.StringFlag{
+		Name:    "profile",
+		EnvVars: A.Of(EnvProfile),
+		Usage:   "Name of the profile in the configuration file that provides the values of flags that are not given explicitly. Defaults to the defaultProfile of the configuration file",
+	}
+	lookupProfile = U.LookupStringFlagOpt(flagProfile.Name)
+
+	// flagConfig specifies the configuration file
+	flagConfig = &cli.StringFlag{
+		Name:      "config",
+		EnvVars:   A.Of(EnvConfig),
+		TakesFile: true,
+		Usage:     fmt.Sprintf("Configuration file with named profiles. Defaults to the nearest %s in the current folder or its parents, merged over %s in the home folder", ConfigFileName, ConfigFileName),
+	}
+	lookupConfig = U.LookupStringFlagOpt(flagConfig.Name)
+
+	// flagVar defines a variable of a contract template
+	flagVar = &cli.StringSliceFlag{
+		Name:  "var",
+		Usage: "Variable of a contract template as NAME=VALUE, takes precedence over variables from files",
+	}
+	lookupVar = U.LookupStringSliceFlag(flagVar.Name)
+
+	// flagVarFile defines a file with variables of a contract template
+	flagVarFile = &cli.StringSliceFlag{
+		Name:      "var-file",
+		TakesFile: true,
+		Usage:     "YAML or JSON file with variables of a contract template, takes precedence over .env files",
+	}
+	lookupVarFile = U.LookupStringSliceFlag(flagVarFile.Name)
+
+	// flagDotEnv defines a folder with a .env file with variables of a contract template
+	flagDotEnv = &cli.StringSliceFlag{
+		Name:      "dotenv",
+		TakesFile: true,
+		Usage:     "Folder with a .env file with variables of a contract template",
+	}
+	lookupDotEnv = U.LookupStringSliceFlag(flagDotEnv.Name)
+
+	// flagTemplateSyntax selects the syntax of the placeholders in a contract template
+	flagTemplateSyntax = &cli.StringFlag{
+		Name:   "template-syntax",
+		Action: validateTemplateSyntax,
+		Value:  TPL.SyntaxShell,
+		Usage:  fmt.Sprintf("Syntax of the placeholders in a contract template, valid values are %s. The input is rendered if it declares parameters or if variables are given", validTemplateSyntaxes),
+	}
+	lookupTemplateSyntax = U.LookupStringFlag(flagTemplateSyntax.Name)
+
+	// flagSection selects a single section of the contract to encrypt
+	flagSection = &cli.StringFlag{
+		Name:   "section",
+		Action: validateSection,
+		Usage:  fmt.Sprintf("Encrypt only the given section of the contract without signing it, valid values are %s. Combine the sections with the sign command", validSections),
+	}
+	lookupSection = U.LookupStringFlagOpt(flagSection.Name)
+
+	// flagWorkload defines the CLI flag for the encrypted workload to sign
+	flagWorkload = &cli.StringFlag{
+		Name:      "workload",
+		Required:  true,
+		Action:    validateInput,
+		TakesFile: true,
+		Usage:     fmt.Sprintf("Name of the file with the encrypted workload, either a contract or a bare token, or '%s' for stdin", CF.StdInOutIdentifier),
+	}
+	lookupWorkload = U.LookupStringFlag(flagWorkload.Name)
+
+	// flagEnv defines the CLI flag for the env to sign
+	flagEnv = &cli.StringFlag{
+		Name:      "env",
+		Required:  true,
+		Action:    validateInput,
+		TakesFile: true,
+		Usage:     fmt.Sprintf("Name of the file with the plaintext or encrypted env, either a contract or a bare token, or '%s' for stdin", CF.StdInOutIdentifier),
+	}
+	lookupEnv = U.LookupStringFlag(flagEnv.Name)
+
+	// flagPurpose defines the purpose of a generated key pair
+	flagPurpose = &cli.StringFlag{
+		Name:   "purpose",
+		Action: validatePurpose,
+		Value:  PurposeSigning,
+		Usage:  fmt.Sprintf("Purpose of the key pair, valid values are %s", validPurposes),
+	}
+	lookupPurpose = U.LookupStringFlag(flagPurpose.Name)
+
+	// flagOutDir defines the target folder for generated files
+	flagOutDir = &cli.StringFlag{
+		Name:      "out-dir",
+		Required:  true,
+		TakesFile: true,
+		Usage:     "Name of the output folder",
+	}
+	lookupOutDir = U.LookupStringFlag(flagOutDir.Name)
+
+	// flagText defines the CLI flag for a plaintext value given on the command line
+	flagText = &cli.StringFlag{
+		Name: "text",
+		Aliases: []string{
+			"t",
+		},
+		Usage: "Value to encrypt as a string. If absent the value is read verbatim from the input",
+	}
+	lookupText = U.LookupStringFlagOpt(flagText.Name)
+
 	// flagCert defines the CLI flag for the public encryption certificate
 	flagCert = &cli.StringFlag{
 		Name: "cert",
@@ -164,6 +516,87 @@ var (
 	}
 	lookupCertFile = U.LookupStringFlagOpt(flagCertFile.Name)
 
+	// flagCertBundle specifies the trusted certificates for the verification of encryption certificates
+	flagCertBundle = &cli.StringFlag{
+		Name:      "cert-bundle",
+		Action:    validateInput,
+		TakesFile: true,
+		Usage:     "File with the PEM encoded intermediate and root certificates used to verify the encryption certificate, self-signed certificates are trusted as roots",
+	}
+	lookupCertBundle = U.LookupStringFlagOpt(flagCertBundle.Name)
+
+	// flagCRL specifies a certificate revocation list
+	flagCRL = &cli.StringFlag{
+		Name:      "crl",
+		Action:    validateInput,
+		TakesFile: true,
+		Usage:     "File with the PEM or DER encoded certificate revocation list of the issuer of the encryption certificate",
+	}
+	lookupCRL = U.LookupStringFlagOpt(flagCRL.Name)
+
+	// flagVerifyTime specifies the time of a certificate verification
+	flagVerifyTime = &cli.TimestampFlag{
+		Name:   "at",
+		Layout: time.RFC3339,
+		Usage:  "Time of the verification in RFC3339 format, e.g. 2023-06-01T00:00:00Z. If absent the tool uses the current time",
+	}
+	lookupVerifyTime = U.LookupTimestampFlagOpt(flagVerifyTime.Name)
+
+	// flagMinKeySize specifies the minimum size of the key of an encryption certificate
+	flagMinKeySize = &cli.IntFlag{
+		Name:  "min-key-size",
+		Value: C.DefaultMinKeySize,
+		Usage: "Minimum size in bits of the RSA key of the encryption certificate",
+	}
+	lookupMinKeySize = U.LookupIntFlag(flagMinKeySize.Name)
+
+	// flagVerifyCert enables the verification of the encryption certificate
+	flagVerifyCert = &cli.BoolFlag{
+		Name:  "verify-cert",
+		Usage: fmt.Sprintf("Refuse to encrypt if the encryption certificate does not pass the verification against [--%s]", flagCertBundle.Name),
+	}
+	lookupVerifyCert = U.LookupBoolFlag(flagVerifyCert.Name)
+
+	// flagHpcrVersion selects the built-in encryption certificate by the version of the HPCR image
+	flagHpcrVersion = &cli.StringFlag{
+		Name:   "hpcr-version",
+		Action: validateSpec,
+		Usage:  "Semantic version range of the HPCR image, e.g. '~1.0.10', used to select a built-in encryption certificate. Ignored if a certificate is passed explicitly",
+	}
+	lookupHpcrVersion = U.LookupStringFlagOpt(flagHpcrVersion.Name)
+
+	// flagSigningKey defines the CLI flag for the public signing key
+	flagSigningKey = &cli.StringFlag{
+		Name: "signingkey",
+		Aliases: []string{
+			"s",
+		},
+		TakesFile: false,
+		Usage:     "Content of the public signing key as a string. If absent the key is taken from the plaintext contract",
+	}
+	lookupSigningKey = U.LookupStringFlagOpt(flagSigningKey.Name)
+
+	// flagSigningKeyFile defines the CLI flag for the public signing key file
+	flagSigningKeyFile = &cli.StringFlag{
+		Name: "signingkeyfile",
+		Aliases: []string{
+			"sf",
+		},
+		Action:    validateInput,
+		TakesFile: true,
+		Usage:     "Public signing key as a filepath. If absent the key is taken from the plaintext contract",
+	}
+	lookupSigningKeyFile = U.LookupStringFlagOpt(flagSigningKeyFile.Name)
+
+	// flagPlaintext defines the CLI flag for the plaintext version of a contract
+	flagPlaintext = &cli.StringFlag{
+		Name:      "plaintext",
+		Action:    validateInput,
+		TakesFile: true,
+		Usage:     "Plaintext contract as a filepath, the public signing key is taken from its env section",
+	}
+	lookupPlaintext = U.LookupStringFlagOpt(flagPlaintext.Name)
+
 	// flagMode is the operation mode
 	flagMode = &cli.StringFlag{
 		Name: "mode",
@@ -206,6 +639,33 @@ var (
 	}
 	lookupFormat = U.LookupStringFlag(flagFormat.Name)
 
+	// flagReportFormat is a format specifier for the output format of a validation report
+	flagReportFormat = &cli.StringFlag{
+		Name:     flagFormat.Name,
+		Action:   validateReportFormat,
+		Required: false,
+		Value:    FormatText,
+		Usage:    fmt.Sprintf("Format specififiers, valid values are %s", validReportFormats),
+	}
+
+	// flagInspectFormat is a format specifier for the output format of an inspection report
+	flagInspectFormat = &cli.StringFlag{
+		Name:     flagFormat.Name,
+		Action:   validateInspectFormat,
+		Required: false,
+		Value:    FormatText,
+		Usage:    fmt.Sprintf("Format specififiers, valid values are %s", validInspectFormats),
+	}
+
+	// flagDiffFormat is a format specifier for the output format of a diff report
+	flagDiffFormat = &cli.StringFlag{
+		Name:     flagFormat.Name,
+		Action:   validateInspectFormat,
+		Required: false,
+		Value:    FormatText,
+		Usage:    fmt.Sprintf("Format specififiers, valid values are %s", validInspectFormats),
+	}
+
 	// flagUrlTemplate specifies an URL template used to download certificates
 	flagUrlTemplate = &cli.StringFlag{
 		Name:     "urltemplate",
@@ -215,6 +675,168 @@ var (
 	}
 	lookupUrlTemplate = U.LookupStringFlag(flagUrlTemplate.Name)
 
+	// flagCacheDir specifies the directory of the local certificate cache
+	flagCacheDir = &cli.StringFlag{
+		Name:      "cache-dir",
+		TakesFile: true,
+		Usage:     "Directory of the local certificate cache. If absent the tool uses a folder in the user cache directory",
+	}
+	lookupCacheDir = U.LookupStringFlagOpt(flagCacheDir.Name)
+
+	// flagCacheTTL specifies the maximum age of cached certificates
+	flagCacheTTL = &cli.DurationFlag{
+		Name:  "cache-ttl",
+		Value: 24 * time.Hour,
+		Usage: "Maximum age of a cached certificate before it is downloaded again. Stale certificates are used if the download fails",
+	}
+	lookupCacheTTL = U.LookupDurationFlag(flagCacheTTL.Name)
+
+	// flagRefresh forces the download of cached certificates
+	flagRefresh = &cli.BoolFlag{
+		Name:  "refresh",
+		Usage: "Download the certificates even if they are cached",
+	}
+	lookupRefresh = U.LookupBoolFlag(flagRefresh.Name)
+
+	// flagConcurrency limits the number of parallel downloads
+	flagConcurrency = &cli.IntFlag{
+		Name:  "concurrency",
+		Value: C.DefaultDownloadConfig.Concurrency,
+		Usage: "Maximum number of parallel downloads, or of parallel encryptions in batch mode",
+	}
+	lookupConcurrency = U.LookupIntFlag(flagConcurrency.Name)
+
+	// flagRetries specifies how often a download is retried after a transient error
+	flagRetries = &cli.IntFlag{
+		Name:  "retries",
+		Value: C.DefaultDownloadConfig.Retries,
+		Usage: "Number of retries of a download after a network error or a server side HTTP status",
+	}
+	lookupRetries = U.LookupIntFlag(flagRetries.Name)
+
+	// flagBackoff specifies the delay before the first retry
+	flagBackoff = &cli.DurationFlag{
+		Name:  "backoff",
+		Value: C.DefaultDownloadConfig.Backoff,
+		Usage: "Delay before the first retry of a download, the delay doubles with every further retry",
+	}
+	lookupBackoff = U.LookupDurationFlag(flagBackoff.Name)
+
+	// flagRequestTimeout specifies the timeout of a single request
+	flagRequestTimeout = &cli.DurationFlag{
+		Name:  "request-timeout",
+		Value: C.DefaultDownloadConfig.RequestTimeout,
+		Usage: "Timeout of a single download request, 0 disables the timeout",
+	}
+	lookupRequestTimeout = U.LookupDurationFlag(flagRequestTimeout.Name)
+
+	// flagTimeout specifies the timeout of all downloads
+	flagTimeout = &cli.DurationFlag{
+		Name:  "timeout",
+		Value: C.DefaultDownloadConfig.Timeout,
+		Usage: "Timeout of all downloads including retries, 0 disables the timeout",
+	}
+	lookupTimeout = U.LookupDurationFlag(flagTimeout.Name)
+
+	// flagCaCert specifies additional root CAs for downloads
+	flagCaCert = &cli.StringSliceFlag{
+		Name:      "cacert",
+		TakesFile: true,
+		Usage:     "File with PEM encoded root CAs that are trusted in addition to the system roots, e.g. the CA of a TLS intercepting proxy. Proxies are configured via HTTPS_PROXY",
+	}
+	lookupCaCert = U.LookupStringSliceFlag(flagCaCert.Name)
+
+	// flagMaxMisses specifies when the discovery of versions stops
+	flagMaxMisses = &cli.IntFlag{
+		Name:   "max-misses",
+		Value:  C.DefaultDiscoveryConfig.MaxMisses,
+		Action: validateMaxMisses,
+		Usage:  "Number of consecutive missing patch versions after which the discovery of a minor version stops. Only used if no versions are given",
+	}
+	lookupMaxMisses = U.LookupIntFlag(flagMaxMisses.Name)
+
+	// flagBatch switches the encryption to batch mode
+	flagBatch = &cli.BoolFlag{
+		Name:  "batch",
+		Usage: fmt.Sprintf("Encrypt all contracts (*.yaml, *.yml, *.json) of a directory or tar file given as [--in], files without an env or workload section are skipped, '%s' reads a tar stream from stdin. The encrypted contracts are written as a tar file if [--out] is '%s' or ends with .tar, else into a directory", CF.StdInOutIdentifier, CF.StdInOutIdentifier),
+	}
+	lookupBatch = U.LookupBoolFlag(flagBatch.Name)
+
+	// flagSummary specifies the file that receives the summary of a batch
+	flagSummary = &cli.StringFlag{
+		Name:      "summary",
+		TakesFile: true,
+		Usage:     fmt.Sprintf("File that receives the summary of a batch in the format given by [--%s]. If absent a text summary is written to stderr", flagFormat.Name),
+	}
+	lookupSummary = U.LookupStringFlagOpt(flagSummary.Name)
+
+	// flagTemplate specifies the contract template of the generation
+	flagTemplate = &cli.StringFlag{
+		Name:      "template",
+		Required:  true,
+		TakesFile: true,
+		Usage:     "Name of the contract template that is rendered once per row of the matrix",
+	}
+	lookupTemplate = U.LookupStringFlag(flagTemplate.Name)
+
+	// flagMatrix specifies the variables of the generation
+	flagMatrix = &cli.StringFlag{
+		Name:      "matrix",
+		Required:  true,
+		TakesFile: true,
+		Usage:     "Name of the matrix with one set of template variables per row, either a CSV file with a header or a YAML or JSON list of maps",
+	}
+	lookupMatrix = U.LookupStringFlag(flagMatrix.Name)
+
+	// flagNameColumn specifies the column of the matrix that names the outputs
+	flagNameColumn = &cli.StringFlag{
+		Name:  "name-column",
+		Value: "name",
+		Usage: "Column of the matrix that names the output of a row. If the matrix has no such column the rows are numbered",
+	}
+	lookupNameColumn = U.LookupStringFlag(flagNameColumn.Name)
+
+	// flagCertColumn specifies the column of the matrix that selects the encryption certificate of a row
+	flagCertColumn = &cli.StringFlag{
+		Name:  "cert-column",
+		Value: "cert",
+		Usage: "Column of the matrix with the file of the encryption certificate of a row, relative to the matrix. Rows without a value use the shared certificate",
+	}
+	lookupCertColumn = U.LookupStringFlag(flagCertColumn.Name)
+
+	// flagListen specifies the address of the encryption service
+	flagListen = &cli.StringFlag{
+		Name:  "listen",
+		Value: "127.0.0.1:8080",
+		Usage: "Address the encryption service listens on. The service has no authentication, bind it to a trusted interface only",
+	}
+	lookupListen = U.LookupStringFlag(flagListen.Name)
+
+	// flagMaxBodySize limits the size of requests to the encryption service
+	flagMaxBodySize = &cli.IntFlag{
+		Name:  "max-body-size",
+		Value: 10 << 20,
+		Usage: "Maximum size of a request body in bytes, larger requests are rejected",
+	}
+	lookupMaxBodySize = U.LookupIntFlag(flagMaxBodySize.Name)
+
+	// flagShutdownTimeout specifies how long running requests may take when the service shuts down
+	flagShutdownTimeout = &cli.DurationFlag{
+		Name:  "shutdown-timeout",
+		Value: 30 * time.Second,
+		Usage: "Time granted to running requests when the service receives an interrupt",
+	}
+	lookupShutdownTimeout = U.LookupDurationFlag(flagShutdownTimeout.Name)
+
+	// flagPayloadSize specifies the size of the random payload of the self-test
+	flagPayloadSize = &cli.IntFlag{
+		Name:   "payload-size",
+		Value:  4096,
+		Action: validatePayloadSize,
+		Usage:  "Size in bytes of the random payload that is encrypted and signed by the self-test",
+	}
+	lookupPayloadSize = U.LookupIntFlag(flagPayloadSize.Name)
+
 	// modeToEncrypt is the mapping from encryption module identifier to
 	modeToEncrypt = map[string]IO.IO[Encrypt.Encryption]{
 		ModeCrypto:  Encrypt.CryptoEncryption,
@@ -222,6 +844,26 @@ var (
 		ModeAuto:    Encrypt.DefaultEncryption,
 	}
 
+	// modeToDecrypt is the mapping from decryption module identifier to
+	modeToDecrypt = map[string]IO.IO[Encrypt.Decryption]{
+		ModeCrypto:  Encrypt.CryptoDecryption,
+		ModeOpenSSL: Encrypt.OpenSSLDecryption,
+		ModeAuto:    Encrypt.DefaultDecryption,
+	}
+
+	// getDecryption returns the configured decryption module
+	getDecryption = F.Flow3(
+		RR.Lookup[IO.IO[Encrypt.Decryption], string],
+		I.Ap[O.Option[IO.IO[Encrypt.Decryption]]](modeToDecrypt),
+		O.GetOrElse(F.Constant(Encrypt.DefaultDecryption)),
+	)
+
+	// missingCertBundle is the fallback if the encryption certificate is verified without a certificate bundle
+	missingCertBundle = IOE.Left[[]byte](fmt.Errorf("a certificate bundle is required to verify the encryption certificate, use [--%s]", flagCertBundle.Name))
+
+	// missingDecryptionKey is the fallback if no decryption key has been specified
+	missingDecryptionKey = IOE.Left[[]byte](fmt.Errorf("a private decryption key is required, use [--%s] or [--%s]", flagDecryptionKey.Name, flagDecryptionKeyFile.Name))
+
 	// getEncryption returns the configured encryption module
 	getEncryption = F.Flow3(
 		RR.Lookup[IO.IO[Encrypt.Encryption], string],
@@ -235,14 +877,11 @@ var (
 		ContractEncrypterFromConfig,
 	)
 
-	// ValidatedContractFromContext returns a [types.Contract] from a [cli.Context] and validates it against the schema
-	ValidatedContractFromContext = F.Flow3(
-		lookupInput,
-		CFIOE.ReadFromInput,
-		IOE.ChainEitherK(F.Flow2(
-			Y.Parse[types.AnyMap],
-			E.Chain(types.ValidateContract),
-		)),
+	// ValidatedContractFromContext returns a [types.Contract] from a [cli.Context] and validates it against the schema.
+	// Folders referenced by the workload are inlined as archives before the validation
+	ValidatedContractFromContext = F.Flow2(
+		InlinedContractFromContext,
+		IOE.ChainEitherK(types.ValidateContract),
 	)
 
 	// getWriter gets a writer method to the specified output
@@ -263,6 +902,150 @@ var (
 		T.Tupled2(IOE.MonadChain[error, SC.EncryptedContract, []byte]),
 	)
 
+	// ContractDecrypterFromContext returns a [SVIOE.ContractDecrypter] based on a [cli.Context]
+	ContractDecrypterFromContext = F.Flow2(
+		DecryptConfigFromContext,
+		ContractDecrypterFromConfig,
+	)
+
+	// EncryptedContractFromContext returns an [SC.EncryptedContract] from a [cli.Context]
+	EncryptedContractFromContext = F.Flow3(
+		lookupInput,
+		CFIOE.ReadFromInput,
+		IOE.ChainEitherK(Y.Parse[SC.EncryptedContract]),
+	)
+
+	// InspectFromContext describes the encrypted contract from a [cli.Context] without decrypting it. Plaintext
+	// sections of a partially encrypted contract are accepted
+	InspectFromContext = F.Flow5(
+		lookupInput,
+		CFIOE.ReadFromInput,
+		IOE.ChainEitherK(Y.Parse[types.AnyMap]),
+		IOE.ChainEitherK(SVE.EncryptedContractFromAnyMap),
+		IOE.Map[error](SVE.InspectContract),
+	)
+
+	// DecryptFromContext returns the plaintext version of an [SC.EncryptedContract] from information on the [cli.Context]
+	DecryptFromContext = F.Flow4(
+		T.Replicate2[*cli.Context],
+		T.Map2(ContractDecrypterFromContext, EncryptedContractFromContext),
+		T.Tupled2(IOE.MonadAp[IOE.IOEither[error, types.AnyMap], error, SC.EncryptedContract]),
+		IOE.Flatten[error, types.AnyMap],
+	)
+
+	// DecryptAndWriteFromContext transforms an encrypted contract into a plaintext contract from information on the [cli.Context]
+	DecryptAndWriteFromContext = F.Flow3(
+		T.Replicate2[*cli.Context],
+		T.Map2(DecryptFromContext, writeFromContext[types.AnyMap]),
+		T.Tupled2(IOE.MonadChain[error, types.AnyMap, []byte]),
+	)
+
+	// missingSigningKey is the fallback if no signing key has been specified
+	missingSigningKey = IOE.Left[[]byte](fmt.Errorf("a public signing key is required, use [--%s], [--%s] or [--%s]", flagSigningKey.Name, flagSigningKeyFile.Name, flagPlaintext.Name))
+
+	// signingKeyFromPlaintext reads the public signing key from the env section of a plaintext contract
+	signingKeyFromPlaintext = F.Flow3(
+		CFIOE.ReadFromInput,
+		IOE.ChainEitherK(Y.Parse[*types.Contract]),
+		IOE.ChainEitherK(SVE.SigningKeyFromContract),
+	)
+
+	// ContractVerifierFromContext returns a [SVIOE.ContractVerifier] based on a [cli.Context]
+	ContractVerifierFromContext = F.Flow2(
+		VerifyConfigFromContext,
+		ContractVerifierFromConfig,
+	)
+
+	// VerifyFromContext verifies the signature of an [SC.EncryptedContract] from information on the [cli.Context]
+	VerifyFromContext = F.Flow4(
+		T.Replicate2[*cli.Context],
+		T.Map2(ContractVerifierFromContext, EncryptedContractFromContext),
+		T.Tupled2(IOE.MonadAp[IOE.IOEither[error, SC.EncryptedContract], error, SC.EncryptedContract]),
+		IOE.Flatten[error, SC.EncryptedContract],
+	)
+
+	// VerifyResultFromContext verifies the signature of an [SC.EncryptedContract] from information on the [cli.Context],
+	// an invalid signature is reported in the result
+	VerifyResultFromContext = F.Flow3(
+		T.Replicate2[*cli.Context],
+		T.Map2(ContractVerifierFromContext, EncryptedContractFromContext),
+		T.Tupled2(func(verifier IOE.IOEither[error, SVIOE.ContractVerifier], ctr IOE.IOEither[error, SC.EncryptedContract]) IOE.IOEither[error, *VerifyResult] {
+			return F.Pipe1(
+				IOE.SequenceT2(verifier, ctr),
+				IOE.ChainIOK[error](T.Tupled2(verifyResultFromContract)),
+			)
+		}),
+	)
+
+	// VerifyAndWriteFromContext verifies the signature of an encrypted contract and reports the result from information on
+	// the [cli.Context]. The report is written in any case, the result fails if the signature is invalid
+	VerifyAndWriteFromContext = F.Flow3(
+		T.Replicate2[*cli.Context],
+		T.Map2(VerifyResultFromContext, writeFromContext[*VerifyResult]),
+		T.Tupled2(func(resIOE IOE.IOEither[error, *VerifyResult], write func(*VerifyResult) IOE.IOEither[error, []byte]) IOE.IOEither[error, *VerifyResult] {
+			return F.Pipe2(
+				resIOE,
+				IOE.ChainFirst(write),
+				IOE.ChainEitherK(verifyResultToEither),
+			)
+		}),
+	)
+
+	// ValidateAndWriteFromContext validates a contract and reports all violations from information on the [cli.Context]. The
+	// report is written in any case, the result fails if the contract is invalid
+	ValidateAndWriteFromContext = F.Flow3(
+		T.Replicate2[*cli.Context],
+		T.Map2(F.Flow2(
+			ContractViolationsFromContext,
+			IOE.Map[error](validateResultFromViolations),
+		), writeReportFromContext),
+		T.Tupled2(func(resIOE IOE.IOEither[error, *ValidateResult], write func(*ValidateResult) IOE.IOEither[error, []byte]) IOE.IOEither[error, *ValidateResult] {
+			return F.Pipe2(
+				resIOE,
+				IOE.ChainFirst(write),
+				IOE.ChainEitherK(validateResultToEither),
+			)
+		}),
+	)
+
+	// AttestationFromContext verifies an attestation record from information on the [cli.Context]
+	AttestationFromContext = F.Flow4(
+		T.Replicate2[*cli.Context],
+		T.Map2(F.Flow2(AttestationConfigFromContext, AttestationVerifierFromConfig), F.Flow2(lookupInput, CFIOE.ReadFromInput)),
+		T.Tupled2(IOE.MonadAp[IOE.IOEither[error, *AT.Verification], error, []byte]),
+		IOE.Flatten[error, *AT.Verification],
+	)
+
+	// AttestationAndWriteFromContext verifies an attestation record and reports the result from information on the
+	// [cli.Context]. The report is written in any case, the result fails if the record does not match the contract
+	AttestationAndWriteFromContext = F.Flow3(
+		T.Replicate2[*cli.Context],
+		T.Map2(AttestationFromContext, writeFromContext[*AT.Verification]),
+		T.Tupled2(func(verIOE IOE.IOEither[error, *AT.Verification], write func(*AT.Verification) IOE.IOEither[error, []byte]) IOE.IOEither[error, *AT.Verification] {
+			return F.Pipe2(
+				verIOE,
+				IOE.ChainFirst(write),
+				IOE.ChainEitherK(verificationToEither),
+			)
+		}),
+	)
+
+	// KeygenFromContext generates a key pair from information on the [cli.Context]
+	KeygenFromContext = F.Flow2(
+		KeygenConfigFromContext,
+		KeygenFromConfig,
+	)
+
+	// KeygenAndWriteFromContext generates a key pair and reports the result from information on the [cli.Context]
+	KeygenAndWriteFromContext = F.Flow3(
+		T.Replicate2[*cli.Context],
+		T.Map2(KeygenFromContext, writeFromContext[*KeygenResult]),
+		T.Tupled2(IOE.MonadChain[error, *KeygenResult, []byte]),
+	)
+
+	// noPassphrase is the fallback if no passphrase has been specified
+	noPassphrase = IOE.Of[error](A.Empty[byte]())
+
 	DownloadCertificatesFromContext = F.Flow2(
 		DownloadCertificatesConfigFromContext,
 		DownloadCertificatesFromConfig,
@@ -270,8 +1053,8 @@ var (
 
 	DownloadCertificatesAndWriteFromContext = F.Flow3(
 		T.Replicate2[*cli.Context],
-		T.Map2(DownloadCertificatesFromContext, writeFromContext[map[string]string]),
-		T.Tupled2(IOE.MonadChain[error, map[string]string, []byte]),
+		T.Map2(DownloadCertificatesFromContext, writeDownloadsFromContext),
+		T.Tupled2(IOE.MonadChain[error, []C.DownloadResult, []byte]),
 	)
 )
 
@@ -292,26 +1075,455 @@ func writeFromContext[T any](ctx *cli.Context) func(T) IOE.IOEither[error, []byt
 	)
 }
 
-// getSerializer returns a serializer for the format string
-func getSerializer[T any](format string) func(T) E.Either[error, []byte] {
-	switch format {
-	case FormatJson:
-		return J.Marshal[T]
-	case FormatYaml:
-		return Y.Stringify[T]
-	default:
-		return J.Marshal[T]
+// inputDir returns the folder that relative references in the input are resolved against
+func inputDir(input string) string {
+	if input == CF.StdInOutIdentifier {
+		return "."
 	}
+	return filepath.Dir(input)
 }
 
-func validateSpec(ctx *cli.Context, value string) error {
-	return F.Pipe2(
-		value,
-		CE.ParseConstraint,
-		E.ToError[*semver.Constraints],
+// TemplateConfigFromContext decodes a [TemplateConfig] from a [cli.Context]
+func TemplateConfigFromContext(ctx *cli.Context) *TemplateConfig {
+	return &TemplateConfig{
+		Syntax:   lookupTemplateSyntax(ctx),
+		Vars:     lookupVar(ctx),
+		VarFiles: lookupVarFile(ctx),
+		DotEnvs:  lookupDotEnv(ctx),
+	}
+}
+
+// TemplateVarsFromConfig collects the variables of a contract template. Variables given directly take precedence over
+// variables from files, which take precedence over variables from .env files
+func TemplateVarsFromConfig(cfg *TemplateConfig) IOE.IOEither[error, TPL.Vars] {
+	return F.Pipe1(
+		IOE.SequenceT3(
+			IOE.TraverseArray(TPLIOE.VarsFromDotEnv)(cfg.DotEnvs),
+			IOE.TraverseArray(TPLIOE.VarsFromFile)(cfg.VarFiles),
+			IOE.FromEither(E.TraverseArray(TPLE.ParseVar)(cfg.Vars)),
+		),
+		IOE.Map[error](T.Tupled3(func(dotEnvs, files, vars []TPL.Vars) TPL.Vars {
+			return M.ConcatAll(RR.UnionLastMonoid[string, string]())(A.Flatten(A.From(dotEnvs, files, vars)))
+		})),
 	)
 }
 
+// RenderedInputFromContext reads the input from a [cli.Context] and renders it as a contract template, see
+// [TPLE.RenderTemplate]
+func RenderedInputFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	cfg := TemplateConfigFromContext(ctx)
+	return F.Pipe1(
+		IOE.SequenceT2(CFIOE.ReadFromInput(lookupInput(ctx)), TemplateVarsFromConfig(cfg)),
+		IOE.ChainEitherK(T.Tupled2(func(source []byte, vars TPL.Vars) E.Either[error, []byte] {
+			return TPLE.RenderTemplate(cfg.Syntax)(vars)(source)
+		})),
+	)
+}
+
+// InlinedContractFromContext reads and renders the plaintext contract from a [cli.Context] and replaces the folders
+// referenced by the `compose` and `play` sections of the workload by their base64 encoded archives
+func InlinedContractFromContext(ctx *cli.Context) IOE.IOEither[error, types.AnyMap] {
+	input := lookupInput(ctx)
+	return F.Pipe1(
+		RenderedInputFromContext(ctx),
+		inlinedContractFromSource(input),
+	)
+}
+
+// InlinedContractFromInput reads the plaintext contract from a file or stdin and replaces the folders referenced by
+// the `compose` and `play` sections of the workload by their base64 encoded archives
+func InlinedContractFromInput(input string) IOE.IOEither[error, types.AnyMap] {
+	return F.Pipe1(
+		CFIOE.ReadFromInput(input),
+		inlinedContractFromSource(input),
+	)
+}
+
+// inlinedContractFromSource parses the source of a plaintext contract and inlines the folders relative to the input
+func inlinedContractFromSource(input string) func(IOE.IOEither[error, []byte]) IOE.IOEither[error, types.AnyMap] {
+	return F.Flow2(
+		IOE.ChainEitherK(Y.Parse[types.AnyMap]),
+		IOE.Chain(SVIOE.InlineArchives(inputDir(input))),
+	)
+}
+
+// diffInputs returns the names of the two contracts to compare from the arguments of the [cli.Context]
+func diffInputs(ctx *cli.Context) E.Either[error, T.Tuple2[string, string]] {
+	args := ctx.Args().Slice()
+	if len(args) != 2 {
+		return E.Left[T.Tuple2[string, string]](fmt.Errorf("expected the names of two contracts, got %d argument(s)", len(args)))
+	}
+	return E.Of[error](T.MakeTuple2(args[0], args[1]))
+}
+
+// contractForDiff reads a plaintext contract for a diff, sections embedded as YAML strings are parsed and folders
+// are inlined as archives
+func contractForDiff(input string) IOE.IOEither[error, *types.Contract] {
+	return F.Pipe3(
+		CFIOE.ReadFromInput(input),
+		IOE.ChainEitherK(F.Flow2(
+			Y.Parse[types.AnyMap],
+			E.Chain(types.ParseContractSections),
+		)),
+		IOE.Chain(SVIOE.InlineArchives(inputDir(input))),
+		IOE.ChainEitherK(types.ValidateContract),
+	)
+}
+
+// contractForMerge reads a partial plaintext contract for a merge, sections embedded as YAML strings are parsed and
+// folders are inlined as archives. The contract is not validated, since only the merge result needs to be complete
+func contractForMerge(input string) IOE.IOEither[error, *types.Merge] {
+	return F.Pipe4(
+		CFIOE.ReadFromInput(input),
+		IOE.ChainEitherK(F.Flow2(
+			Y.Parse[types.AnyMap],
+			E.Chain(types.ParseContractSections),
+		)),
+		IOE.Chain(SVIOE.InlineArchives(inputDir(input))),
+		IOE.ChainEitherK(types.DecodeContract),
+		IOE.Map[error](types.MergeFrom(input)),
+	)
+}
+
+// mergeInputs returns the names of the contracts to merge from the arguments of the [cli.Context]
+func mergeInputs(ctx *cli.Context) E.Either[error, []string] {
+	args := ctx.Args().Slice()
+	if len(args) == 0 {
+		return E.Left[[]string](fmt.Errorf("expected the names of the contracts to merge"))
+	}
+	return E.Of[error](args)
+}
+
+// MergeFromContext merges the contracts passed as arguments on the [cli.Context] from left to right
+func MergeFromContext(ctx *cli.Context) IOE.IOEither[error, *types.Merge] {
+	return F.Pipe3(
+		mergeInputs(ctx),
+		IOE.FromEither[error, []string],
+		IOE.Chain(IOE.TraverseArray(contractForMerge)),
+		IOE.ChainEitherK(types.MergeContracts(lookupStrategy(ctx))),
+	)
+}
+
+// writeMergeReport prints the overridden keys of a merge to the error writer of the app, so they do not interfere with
+// the merged contract
+func writeMergeReport(ctx *cli.Context) func(*types.Merge) IOE.IOEither[error, *types.Merge] {
+	return func(merge *types.Merge) IOE.IOEither[error, *types.Merge] {
+		return IOE.TryCatchError(func() (*types.Merge, error) {
+			for _, o := range merge.Overrides {
+				if _, err := fmt.Fprintf(ctx.App.ErrWriter, "overridden: %s\n", o); err != nil {
+					return merge, err
+				}
+			}
+			return merge, nil
+		})
+	}
+}
+
+// MergeAndWriteFromContext merges contracts, reports the overridden keys and writes the merged contract
+func MergeAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe3(
+		MergeFromContext(ctx),
+		IOE.Chain(writeMergeReport(ctx)),
+		IOE.Map[error](func(merge *types.Merge) *types.Contract {
+			return merge.Contract
+		}),
+		IOE.Chain(writeFromContext[*types.Contract](ctx)),
+	)
+}
+
+// DiffFromContext computes the semantic difference between the two contracts passed as arguments on the [cli.Context]
+func DiffFromContext(ctx *cli.Context) IOE.IOEither[error, *DF.Report] {
+	return F.Pipe2(
+		diffInputs(ctx),
+		IOE.FromEither[error, T.Tuple2[string, string]],
+		IOE.Chain(func(inputs T.Tuple2[string, string]) IOE.IOEither[error, *DF.Report] {
+			return F.Pipe1(
+				IOE.SequenceT2(contractForDiff(inputs.F1), contractForDiff(inputs.F2)),
+				IOE.Map[error](T.Tupled2(DFE.DiffContracts(!lookupShowSecrets(ctx)))),
+			)
+		}),
+	)
+}
+
+// DiffAndWriteFromContext computes the semantic difference between two contracts and writes the report
+func DiffAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe2(
+		DiffFromContext(ctx),
+		IOE.ChainEitherK(getDiffSerializer(lookupFormat(ctx))),
+		IOE.Chain(getWriter(lookupOutput(ctx))),
+	)
+}
+
+// getDiffSerializer returns a serializer for a diff report
+func getDiffSerializer(format string) func(*DF.Report) E.Either[error, []byte] {
+	if format == FormatText {
+		return F.Flow2(
+			textDiff,
+			E.Of[error, []byte],
+		)
+	}
+	return getSerializer[*DF.Report](format)
+}
+
+// textChange renders a single change as a line of text
+func textChange(change DF.Change) string {
+	switch {
+	case change.Section == DF.SectionArchive:
+		return fmt.Sprintf("%-7s %s", change.Kind, change.Path)
+	case change.Kind == DF.KindAdded:
+		return fmt.Sprintf("%-7s %s: %s", change.Kind, change.Path, change.New)
+	case change.Kind == DF.KindRemoved:
+		return fmt.Sprintf("%-7s %s: %s", change.Kind, change.Path, change.Old)
+	default:
+		return fmt.Sprintf("%-7s %s: %s -> %s", change.Kind, change.Path, change.Old, change.New)
+	}
+}
+
+// textDiff renders a diff report as human readable text, one line per change followed by the lines of changed files
+func textDiff(res *DF.Report) []byte {
+	if len(res.Changes) == 0 {
+		return []byte("no differences\n")
+	}
+	var buf bytes.Buffer
+	for _, change := range res.Changes {
+		fmt.Fprintln(&buf, textChange(change))
+		for _, line := range change.Lines {
+			fmt.Fprintf(&buf, "    %s\n", line)
+		}
+	}
+	return buf.Bytes()
+}
+
+// BuildArchiveAndWriteFromContext writes either the archive of a single folder or the contract with inlined archives
+// from information on the [cli.Context]
+func BuildArchiveAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe1(
+		lookupFolder(ctx),
+		buildArchiveFromContext(ctx),
+	)
+}
+
+// buildArchiveFromContext writes either the archive of a single folder or the contract with inlined archives
+func buildArchiveFromContext(ctx *cli.Context) func(O.Option[string]) IOE.IOEither[error, []byte] {
+	return O.Fold(
+		func() IOE.IOEither[error, []byte] {
+			return F.Pipe1(
+				InlinedContractFromContext(ctx),
+				IOE.Chain(writeFromContext[types.AnyMap](ctx)),
+			)
+		},
+		F.Flow3(
+			AIOE.Base64TarFolder,
+			IOE.Map[error](S.ToBytes),
+			IOE.Chain(getWriter(lookupOutput(ctx))),
+		),
+	)
+}
+
+// cidataVendorData is the vendor data of a cidata image, it enables the default user of cloud-init
+const cidataVendorData = "#cloud-config\nusers:\n- default\n"
+
+// cidataMetaData serializes the meta data of a cidata image, the hostname is optional
+func cidataMetaData(hostname O.Option[string]) E.Either[error, []byte] {
+	return F.Pipe1(
+		hostname,
+		O.Fold(
+			F.Constant(E.Of[error](A.Empty[byte]())),
+			func(name string) E.Either[error, []byte] {
+				return Y.Stringify(map[string]string{"local-hostname": name})
+			},
+		),
+	)
+}
+
+// cidataFiles assembles the files of a cidata image, the user data holds the serialized encrypted contract
+func cidataFiles(hostname O.Option[string]) func([]byte) E.Either[error, TA.FileList] {
+	return func(userData []byte) E.Either[error, TA.FileList] {
+		return F.Pipe1(
+			cidataMetaData(hostname),
+			E.Map[error](func(metaData []byte) TA.FileList {
+				return TA.FileList{
+					ISO.FileUserData:   userData,
+					ISO.FileMetaData:   metaData,
+					ISO.FileVendorData: []byte(cidataVendorData),
+				}
+			}),
+		)
+	}
+}
+
+// CidataFromContext encrypts the contract from a [cli.Context] and assembles the files of a NoCloud cidata image
+func CidataFromContext(ctx *cli.Context) IOE.IOEither[error, TA.FileList] {
+	return F.Pipe2(
+		EncryptAndSignFromContext(ctx),
+		IOE.ChainEitherK(Y.Stringify[SC.EncryptedContract]),
+		IOE.ChainEitherK(cidataFiles(lookupHostname(ctx))),
+	)
+}
+
+// CidataAndWriteFromContext encrypts the contract from a [cli.Context] and writes it as a NoCloud cidata ISO image
+func CidataAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe2(
+		CidataFromContext(ctx),
+		IOE.ChainEitherK(ISOE.Marshal(ISO.VolumeIDCidata)),
+		IOE.Chain(getWriter(lookupOutput(ctx))),
+	)
+}
+
+// ContractViolationsFromContext validates the contract from a [cli.Context] against the schema and returns all violations
+// together with their location in the input
+func ContractViolationsFromContext(ctx *cli.Context) IOE.IOEither[error, []types.Violation] {
+	input := lookupInput(ctx)
+	cfg := TemplateConfigFromContext(ctx)
+	return F.Pipe1(
+		IOE.SequenceT2(CFIOE.ReadFromInput(input), TemplateVarsFromConfig(cfg)),
+		IOE.ChainEitherK(T.Tupled2(func(source []byte, vars TPL.Vars) E.Either[error, []types.Violation] {
+			return F.Pipe2(
+				TPLE.RenderTemplate(cfg.Syntax)(vars)(source),
+				E.Chain(types.ContractViolationsFromYAML(input)),
+				E.Map[error](A.Map(shiftViolation(TPLE.BodyOffset(source)))),
+			)
+		})),
+	)
+}
+
+// shiftViolation moves the location of a violation in a rendered template to its line in the source
+func shiftViolation(lines int) func(types.Violation) types.Violation {
+	return func(v types.Violation) types.Violation {
+		if v.Line > 0 {
+			v.Line += lines
+		}
+		return v
+	}
+}
+
+// writeReportFromContext serializes a validation report and persists it to a location specified by the [cli.Context]
+func writeReportFromContext(ctx *cli.Context) func(*ValidateResult) IOE.IOEither[error, []byte] {
+	cfg := OutputConfigFromContext(ctx)
+	return F.Flow2(
+		getReportSerializer(cfg.Format, lookupInput(ctx)),
+		E.Fold(IOE.Left[[]byte, error], getWriter(cfg.Output)),
+	)
+}
+
+// getReportSerializer returns a serializer for a validation report of the given input
+func getReportSerializer(format, input string) func(*ValidateResult) E.Either[error, []byte] {
+	switch format {
+	case FormatText:
+		return F.Flow2(
+			textReport(input),
+			E.Of[error, []byte],
+		)
+	case FormatSarif:
+		return F.Flow2(
+			sarifReport(input),
+			J.Marshal[*SarifLog],
+		)
+	default:
+		return getSerializer[*ValidateResult](format)
+	}
+}
+
+// textReport renders a validation report as human readable text, one line per violation
+func textReport(input string) func(*ValidateResult) []byte {
+	return func(res *ValidateResult) []byte {
+		if res.Valid {
+			return []byte(fmt.Sprintf("%s: valid\n", input))
+		}
+		var buf bytes.Buffer
+		for _, v := range res.Violations {
+			fmt.Fprintf(&buf, "%s\n", v)
+		}
+		return buf.Bytes()
+	}
+}
+
+// InspectAndWriteFromContext inspects the encrypted contract from a [cli.Context] and writes the report
+func InspectAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe2(
+		InspectFromContext(ctx),
+		IOE.ChainEitherK(getInspectSerializer(lookupFormat(ctx))),
+		IOE.Chain(getWriter(lookupOutput(ctx))),
+	)
+}
+
+// getInspectSerializer returns a serializer for an inspection report
+func getInspectSerializer(format string) func(*SC.ContractInspection) E.Either[error, []byte] {
+	if format == FormatText {
+		return F.Flow2(
+			textInspection,
+			E.Of[error, []byte],
+		)
+	}
+	return getSerializer[*SC.ContractInspection](format)
+}
+
+// textSection renders the inspection of a single section as a line of text
+func textSection(section SC.SectionInspection) string {
+	switch {
+	case section.Token:
+		details := section.Details
+		header := "no Salted__ header"
+		if details.Salted {
+			header = "Salted__ header"
+		}
+		return fmt.Sprintf("%s: hyper-protect-basic token, RSA ciphertext %d bytes (%d bit key), %s, payload %d bytes", section.Key, details.RsaCiphertextLength, details.KeySize, header, details.PayloadSize)
+	case section.Plaintext:
+		return fmt.Sprintf("%s: plaintext, expected a hyper-protect-basic token", section.Key)
+	default:
+		return fmt.Sprintf("%s: plaintext", section.Key)
+	}
+}
+
+// textInspection renders an inspection report as human readable text
+func textInspection(res *SC.ContractInspection) []byte {
+	var buf bytes.Buffer
+	for _, section := range res.Sections {
+		fmt.Fprintln(&buf, textSection(section))
+		for _, problem := range section.Problems {
+			fmt.Fprintf(&buf, "  problem: %s\n", problem)
+		}
+	}
+	signature := "missing"
+	if res.Signed {
+		signature = "present"
+	}
+	fmt.Fprintf(&buf, "signature: %s\n", signature)
+	plaintext := "none"
+	if len(res.PlaintextSections) > 0 {
+		plaintext = strings.Join(res.PlaintextSections, ", ")
+	}
+	fmt.Fprintf(&buf, "plaintext sections: %s\n", plaintext)
+	return buf.Bytes()
+}
+
+// getSerializer returns a serializer for the format string
+func getSerializer[T any](format string) func(T) E.Either[error, []byte] {
+	switch format {
+	case FormatJson:
+		return J.Marshal[T]
+	case FormatYaml:
+		return Y.Stringify[T]
+	default:
+		return J.Marshal[T]
+	}
+}
+
+func validateSpec(ctx *cli.Context, value string) error {
+	return F.Pipe2(
+		value,
+		CE.ParseConstraint,
+		E.ToError[*semver.Constraints],
+	)
+}
+
+func validateMaxMisses(ctx *cli.Context, value int) error {
+	if value < 1 {
+		return fmt.Errorf("the number of misses must be positive, got [%d]", value)
+	}
+	return nil
+}
+
 func validateVersions(ctx *cli.Context, values []string) error {
 	return F.Pipe2(
 		values,
@@ -328,12 +1540,23 @@ func validateInput(ctx *cli.Context, value string) error {
 	if err != nil {
 		return err
 	}
-	if status.IsDir() {
+	if status.IsDir() && !lookupBatch(ctx) {
 		return fmt.Errorf("input [%s] must be a file not a directory", value)
 	}
 	return nil
 }
 
+func validateFolder(ctx *cli.Context, value string) error {
+	status, err := os.Stat(value)
+	if err != nil {
+		return err
+	}
+	if !status.IsDir() {
+		return fmt.Errorf("folder [%s] must be a directory not a file", value)
+	}
+	return nil
+}
+
 func validateOutput(ctx *cli.Context, value string) error {
 	if value == CF.StdInOutIdentifier {
 		return nil
@@ -347,7 +1570,7 @@ func validateOutput(ctx *cli.Context, value string) error {
 	if err != nil && os.IsNotExist(err) {
 		return nil
 	}
-	if status.IsDir() {
+	if status.IsDir() && !lookupBatch(ctx) {
 		return fmt.Errorf("output [%s] must be a file not a directory", value)
 	}
 	return nil
@@ -389,6 +1612,79 @@ func EncryptAndSignConfigFromContext(ctx *cli.Context) *EncryptAndSignConfig {
 			lookupCert(ctx),
 			lookupCertFile(ctx),
 		},
+		Section:    lookupSection(ctx),
+		Version:    lookupHpcrVersion(ctx),
+		CacheDir:   lookupCacheDir(ctx),
+		VerifyCert: lookupVerifyCert(ctx),
+		Verify:     *CertVerifyConfigFromContext(ctx),
+	}
+}
+
+// ServeConfigFromContext decodes a [ServeConfig] from a [cli.Context]
+func ServeConfigFromContext(ctx *cli.Context) *ServeConfig {
+	return &ServeConfig{
+		Addr:            lookupListen(ctx),
+		MaxBodySize:     int64(lookupMaxBodySize(ctx)),
+		ShutdownTimeout: lookupShutdownTimeout(ctx),
+		Encrypt:         *EncryptAndSignConfigFromContext(ctx),
+	}
+}
+
+// CertVerifyConfigFromContext decodes a [CertVerifyConfig] from a [cli.Context]
+func CertVerifyConfigFromContext(ctx *cli.Context) *CertVerifyConfig {
+	return &CertVerifyConfig{
+		Bundle:     lookupCertBundle(ctx),
+		CRL:        lookupCRL(ctx),
+		Time:       lookupVerifyTime(ctx),
+		MinKeySize: lookupMinKeySize(ctx),
+	}
+}
+
+// DecryptConfigFromContext decodes a [DecryptConfig] from a [cli.Context]
+func DecryptConfigFromContext(ctx *cli.Context) *DecryptConfig {
+	return &DecryptConfig{
+		Mode: lookupMode(ctx),
+		PrivKey: KeyConfig{
+			lookupPrivKey(ctx),
+			lookupPrivKeyFile(ctx),
+		},
+	}
+}
+
+// VerifyConfigFromContext decodes a [VerifyConfig] from a [cli.Context]
+func VerifyConfigFromContext(ctx *cli.Context) *VerifyConfig {
+	return &VerifyConfig{
+		Mode: lookupMode(ctx),
+		SigningKey: KeyConfig{
+			lookupSigningKey(ctx),
+			lookupSigningKeyFile(ctx),
+		},
+		Plaintext: lookupPlaintext(ctx),
+	}
+}
+
+// AttestationConfigFromContext decodes an [AttestationConfig] from a [cli.Context]
+func AttestationConfigFromContext(ctx *cli.Context) *AttestationConfig {
+	return &AttestationConfig{
+		Mode: lookupMode(ctx),
+		PrivKey: KeyConfig{
+			lookupPrivKey(ctx),
+			lookupPrivKeyFile(ctx),
+		},
+		Contract: lookupContract(ctx),
+	}
+}
+
+// KeygenConfigFromContext decodes a [KeygenConfig] from a [cli.Context]
+func KeygenConfigFromContext(ctx *cli.Context) *KeygenConfig {
+	return &KeygenConfig{
+		Mode:    lookupMode(ctx),
+		Purpose: lookupPurpose(ctx),
+		OutDir:  lookupOutDir(ctx),
+		Passphrase: KeyConfig{
+			lookupPassphrase(ctx),
+			lookupPassphraseFile(ctx),
+		},
 	}
 }
 
@@ -396,39 +1692,241 @@ func EncryptAndSignConfigFromContext(ctx *cli.Context) *EncryptAndSignConfig {
 func DownloadCertificatesConfigFromContext(ctx *cli.Context) *DownloadCertificatesConfig {
 	return &DownloadCertificatesConfig{
 		Versions:    lookupVersions(ctx),
+		Spec:        lookupSpec(ctx),
+		MaxMisses:   lookupMaxMisses(ctx),
 		UrlTemplate: lookupUrlTemplate(ctx),
+		CacheDir:    lookupCacheDir(ctx),
+		CacheTTL:    lookupCacheTTL(ctx),
+		Refresh:     lookupRefresh(ctx),
+		Download: C.DownloadConfig{
+			Concurrency:    lookupConcurrency(ctx),
+			Retries:        lookupRetries(ctx),
+			Backoff:        lookupBackoff(ctx),
+			RequestTimeout: lookupRequestTimeout(ctx),
+			Timeout:        lookupTimeout(ctx),
+		},
+		CaCerts: lookupCaCert(ctx),
 	}
 }
 
-// ContractEncrypterFromConfig constructs a [SVIOE.ContractEncrypter] based on a config object
-func ContractEncrypterFromConfig(cfg *EncryptAndSignConfig) IOE.IOEither[error, SVIOE.ContractEncrypter] {
-	// encryption module
-	encryption := F.Pipe2(
+// VerifyConfigFromConfig reads the files referenced by a [CertVerifyConfig], a certificate bundle is required
+func VerifyConfigFromConfig(cfg *CertVerifyConfig) IOE.IOEither[error, *C.VerifyConfig] {
+	bundle := F.Pipe1(
+		cfg.Bundle,
+		O.Fold(F.Constant(missingCertBundle), CFIOE.ReadFromInput),
+	)
+	crl := F.Pipe1(
+		cfg.CRL,
+		O.Fold(F.Constant(IOE.Of[error](A.Empty[byte]())), CFIOE.ReadFromInput),
+	)
+	return F.Pipe1(
+		IOE.SequenceT2(bundle, crl),
+		IOE.Map[error](T.Tupled2(func(bundle, crl []byte) *C.VerifyConfig {
+			return &C.VerifyConfig{
+				Bundle:     bundle,
+				CRL:        crl,
+				Time:       O.GetOrElse(F.Constant(time.Time{}))(cfg.Time),
+				MinKeySize: cfg.MinKeySize,
+			}
+		})),
+	)
+}
+
+// verifyKeyFromConfig verifies the encryption certificate if requested by the config
+func verifyKeyFromConfig(cfg *EncryptAndSignConfig) func(Encrypt.Key) Encrypt.Key {
+	if !cfg.VerifyCert {
+		return F.Identity[Encrypt.Key]
+	}
+	return func(key Encrypt.Key) Encrypt.Key {
+		return F.Pipe1(
+			IOE.SequenceT2(key, VerifyConfigFromConfig(&cfg.Verify)),
+			IOE.ChainEitherK(T.Tupled2(func(data []byte, verify *C.VerifyConfig) E.Either[error, []byte] {
+				return CE.ValidCertificate(verify)(data)
+			})),
+		)
+	}
+}
+
+// VerifyCertificateFromContext verifies the encryption certificate passed as input on the [cli.Context]
+func VerifyCertificateFromContext(ctx *cli.Context) IOE.IOEither[error, *C.CertificateVerification] {
+	return F.Pipe1(
+		IOE.SequenceT2(
+			CFIOE.ReadFromInput(lookupInput(ctx)),
+			VerifyConfigFromConfig(CertVerifyConfigFromContext(ctx)),
+		),
+		IOE.ChainEitherK(T.Tupled2(func(data []byte, verify *C.VerifyConfig) E.Either[error, *C.CertificateVerification] {
+			return CE.VerifyCertificatePEM(verify)(data)
+		})),
+	)
+}
+
+// VerifyCertificateAndWriteFromContext verifies an encryption certificate and writes the report. The operation fails
+// after writing the report if the certificate is invalid
+func VerifyCertificateAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe1(
+		VerifyCertificateFromContext(ctx),
+		IOE.Chain(func(result *C.CertificateVerification) IOE.IOEither[error, []byte] {
+			return F.Pipe3(
+				result,
+				getVerificationSerializer(lookupFormat(ctx)),
+				IOE.FromEither[error, []byte],
+				IOE.Chain(F.Flow2(
+					getWriter(lookupOutput(ctx)),
+					IOE.ChainEitherK(func(data []byte) E.Either[error, []byte] {
+						return E.TryCatchError(data, result.ToError())
+					}),
+				)),
+			)
+		}),
+	)
+}
+
+// getVerificationSerializer returns a serializer for a certificate verification report
+func getVerificationSerializer(format string) func(*C.CertificateVerification) E.Either[error, []byte] {
+	if format == FormatText {
+		return F.Flow2(
+			textVerification,
+			E.Of[error, []byte],
+		)
+	}
+	return getSerializer[*C.CertificateVerification](format)
+}
+
+// textVerification renders a certificate verification report as text
+func textVerification(res *C.CertificateVerification) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "subject: %s\n", res.Subject)
+	fmt.Fprintf(&buf, "issuer: %s\n", res.Issuer)
+	fmt.Fprintf(&buf, "validity: %s - %s\n", res.NotBefore.Format(time.RFC3339), res.NotAfter.Format(time.RFC3339))
+	fmt.Fprintf(&buf, "key size: %d bit\n", res.KeySize)
+	status := "valid"
+	if !res.Valid() {
+		status = "invalid"
+	}
+	fmt.Fprintf(&buf, "status: %s\n", status)
+	for _, problem := range res.Problems {
+		fmt.Fprintf(&buf, "  problem: %s\n", problem)
+	}
+	return buf.Bytes()
+}
+
+// encryptionCertFromConfig returns the public encryption key or certificate of a config, either specified explicitly or
+// selected from the cached and built-in certificates, and verifies it if requested
+func encryptionCertFromConfig(cfg *EncryptAndSignConfig) Encrypt.Key {
+	return F.Pipe3(
+		cfg.Version,
+		getDefaultCertificate(cfg.CacheDir),
+		getKeyFromConfig(cfg.PubCert),
+		verifyKeyFromConfig(cfg),
+	)
+}
+
+// basicEncrypterFromConfig returns the function that produces `hyper-protect-basic` tokens for the encryption
+// certificate of a config
+func basicEncrypterFromConfig(cfg *EncryptAndSignConfig) func(IO.IO[Encrypt.Encryption]) IOE.IOEither[error, func([]byte) IOE.IOEither[error, string]] {
+	return F.Flow3(
+		IO.Map(Encrypt.Encryption.GetEncryptBasic),
+		IOE.FromIO[error, Encrypt.EncryptBasicFunc],
+		IOE.Ap[func([]byte) IOE.IOEither[error, string]](encryptionCertFromConfig(cfg)),
+	)
+}
+
+// StringEncrypterFromConfig constructs a function that encrypts a single value into a `hyper-protect-basic` token
+func StringEncrypterFromConfig(cfg *EncryptAndSignConfig) IOE.IOEither[error, func([]byte) IOE.IOEither[error, string]] {
+	return F.Pipe2(
 		cfg.Mode,
 		getEncryption,
-		IO.Memoize[Encrypt.Encryption],
+		basicEncrypterFromConfig(cfg),
 	)
-	// signing key
-	privKey := F.Pipe3(
-		encryption,
+}
+
+// plaintextFromContext returns the value to encrypt, either given as text or read from the input
+func plaintextFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe1(
+		lookupText(ctx),
+		O.Fold(F.Constant(CFIOE.ReadFromInput(lookupInput(ctx))), F.Flow2(
+			S.ToBytes,
+			IOE.Of[error, []byte],
+		)),
+	)
+}
+
+// EncryptStringFromContext encrypts the value on the [cli.Context] into a single `hyper-protect-basic` token
+func EncryptStringFromContext(ctx *cli.Context) IOE.IOEither[error, string] {
+	return F.Pipe1(
+		IOE.SequenceT2(
+			StringEncrypterFromConfig(EncryptAndSignConfigFromContext(ctx)),
+			plaintextFromContext(ctx),
+		),
+		IOE.Chain(T.Tupled2(func(enc func([]byte) IOE.IOEither[error, string], data []byte) IOE.IOEither[error, string] {
+			return enc(data)
+		})),
+	)
+}
+
+// EncryptStringAndWriteFromContext encrypts a single value and writes the token followed by a newline
+func EncryptStringAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe2(
+		EncryptStringFromContext(ctx),
+		IOE.Map[error](func(token string) []byte {
+			return []byte(token + "\n")
+		}),
+		IOE.Chain(getWriter(lookupOutput(ctx))),
+	)
+}
+
+// signingKeyFromConfig returns the private signing key of a config or creates a transient key
+func signingKeyFromConfig(cfg *EncryptAndSignConfig) func(IO.IO[Encrypt.Encryption]) Encrypt.Key {
+	return F.Flow3(
 		IO.Map(Encrypt.Encryption.GetPrivKey),
 		IOE.FromIO[error, Encrypt.Key],
 		IOE.Chain(getKeyFromConfig(cfg.PrivKey)),
 	)
+}
 
-	// public encryption key or certificate
-	pubCert := F.Pipe1(
-		defaultCertificate,
-		getKeyFromConfig(cfg.PubCert),
+// SectionEncrypterFromConfig constructs a [SVIOE.ContractEncrypter] that encrypts a single section of a contract
+// without signing it. The public key of an explicitly specified signing key is inserted into the env section.
+func SectionEncrypterFromConfig(cfg *EncryptAndSignConfig) func(section string) IOE.IOEither[error, SVIOE.ContractEncrypter] {
+	return func(section string) IOE.IOEither[error, SVIOE.ContractEncrypter] {
+		// encryption module
+		encryption := F.Pipe2(
+			cfg.Mode,
+			getEncryption,
+			IO.Memoize[Encrypt.Encryption],
+		)
+		// public key extractor
+		pubkey := F.Pipe2(
+			encryption,
+			IO.Map(Encrypt.Encryption.GetPubKey),
+			IOE.FromIO[error, Encrypt.PubKeyFunc],
+		)
+
+		return F.Pipe3(
+			IOE.SequenceT2(basicEncrypterFromConfig(cfg)(encryption), pubkey),
+			IOE.Map[error](T.Tupled2(SVIOE.EncryptContractSection)),
+			IOE.Map[error](I.Ap[func(O.Option[[]byte]) SVIOE.ContractEncrypter](section)),
+			IOE.Ap[SVIOE.ContractEncrypter](optionalKeyFromConfig(cfg.PrivKey)),
+		)
+	}
+}
+
+// ContractEncrypterFromConfig constructs a [SVIOE.ContractEncrypter] based on a config object
+func ContractEncrypterFromConfig(cfg *EncryptAndSignConfig) IOE.IOEither[error, SVIOE.ContractEncrypter] {
+	// encrypt a single section, if requested
+	if section, ok := O.Unwrap(cfg.Section); ok {
+		return SectionEncrypterFromConfig(cfg)(section)
+	}
+	// encryption module
+	encryption := F.Pipe2(
+		cfg.Mode,
+		getEncryption,
+		IO.Memoize[Encrypt.Encryption],
 	)
+	// signing key
+	privKey := signingKeyFromConfig(cfg)(encryption)
 
 	// encryption function
-	enc := F.Pipe3(
-		encryption,
-		IO.Map(Encrypt.Encryption.GetEncryptBasic),
-		IOE.FromIO[error, Encrypt.EncryptBasicFunc],
-		IOE.Ap[func([]byte) IOE.IOEither[error, string]](pubCert),
-	)
+	enc := basicEncrypterFromConfig(cfg)(encryption)
 	// signing function
 	signer := F.Pipe2(
 		encryption,
@@ -449,18 +1947,306 @@ func ContractEncrypterFromConfig(cfg *EncryptAndSignConfig) IOE.IOEither[error,
 	)
 }
 
-// DownloadCertificatesFromConfig dowloads certificates based on some config
-func DownloadCertificatesFromConfig(cfg *DownloadCertificatesConfig) IOE.IOEither[error, map[string]string] {
-	download := CIOE.DownloadCertificates(IOEH.MakeClient(http.DefaultClient))
-	resolver := CE.ParseResolver(cfg.UrlTemplate)
+// ContractDecrypterFromConfig constructs a [SVIOE.ContractDecrypter] based on a config object
+func ContractDecrypterFromConfig(cfg *DecryptConfig) IOE.IOEither[error, SVIOE.ContractDecrypter] {
+	// decryption module
+	decryption := F.Pipe1(
+		cfg.Mode,
+		getDecryption,
+	)
+	// private decryption key
+	privKey := F.Pipe1(
+		missingDecryptionKey,
+		getKeyFromConfig(cfg.PrivKey),
+	)
 
 	return F.Pipe3(
-		cfg.Versions,
-		E.TraverseArray(CE.ParseVersion),
-		E.Fold(IOE.Left[[]C.VersionCert, error], download(resolver)),
-		IOE.Map[error](F.Flow2(
-			A.Map(T.Map2(C.Version.String, F.Identity[string])),
-			RR.FromEntries[string, string],
+		decryption,
+		IO.Map(func(dec Encrypt.Decryption) func([]byte) SVIOE.ContractDecrypter {
+			return SVIOE.DecryptContract(dec.DecryptBasic)
+		}),
+		IOE.FromIO[error, func([]byte) SVIOE.ContractDecrypter],
+		IOE.Ap[SVIOE.ContractDecrypter](privKey),
+	)
+}
+
+// contractVerifier constructs a [SVIOE.ContractVerifier] for the public signing key using the given mode
+func contractVerifier(mode string) func(pubKey Encrypt.Key) IOE.IOEither[error, SVIOE.ContractVerifier] {
+	return func(pubKey Encrypt.Key) IOE.IOEither[error, SVIOE.ContractVerifier] {
+		return F.Pipe4(
+			mode,
+			getEncryption,
+			IO.Map(F.Flow2(
+				Encrypt.Encryption.GetVerifyDigest,
+				SVIOE.VerifyContract,
+			)),
+			IOE.FromIO[error, func([]byte) SVIOE.ContractVerifier],
+			IOE.Ap[SVIOE.ContractVerifier](pubKey),
+		)
+	}
+}
+
+// ContractVerifierFromConfig constructs a [SVIOE.ContractVerifier] based on a config object
+func ContractVerifierFromConfig(cfg *VerifyConfig) IOE.IOEither[error, SVIOE.ContractVerifier] {
+	// public signing key, either specified directly or taken from the plaintext contract
+	return F.Pipe3(
+		cfg.Plaintext,
+		O.Fold(F.Constant(missingSigningKey), signingKeyFromPlaintext),
+		getKeyFromConfig(cfg.SigningKey),
+		contractVerifier(cfg.Mode),
+	)
+}
+
+// verifyResultFromContract verifies a contract and produces its report, a failed verification is reported as invalid
+func verifyResultFromContract(verify SVIOE.ContractVerifier, ctr SC.EncryptedContract) IO.IO[*VerifyResult] {
+	return F.Pipe1(
+		verify(ctr),
+		IOE.Fold(func(err error) IO.IO[*VerifyResult] {
+			return IO.Of(&VerifyResult{
+				EnvWorkloadSignature: ctr[SC.KeyEnvWorkloadSignature],
+				Error:                err.Error(),
+			})
+		}, func(ctr SC.EncryptedContract) IO.IO[*VerifyResult] {
+			return IO.Of(&VerifyResult{
+				Valid:                true,
+				EnvWorkloadSignature: ctr[SC.KeyEnvWorkloadSignature],
+			})
+		}),
+	)
+}
+
+// verifyResultToEither fails if the verification report is invalid
+func verifyResultToEither(res *VerifyResult) E.Either[error, *VerifyResult] {
+	if res.Valid {
+		return E.Of[error](res)
+	}
+	return E.Left[*VerifyResult](fmt.Errorf("the signature of the contract is invalid: %s", res.Error))
+}
+
+// validateResultFromViolations creates the report of a validation
+func validateResultFromViolations(vs []types.Violation) *ValidateResult {
+	return &ValidateResult{
+		Valid:      A.IsEmpty(vs),
+		Violations: vs,
+	}
+}
+
+// validateResultToEither fails if the validation report contains violations
+func validateResultToEither(res *ValidateResult) E.Either[error, *ValidateResult] {
+	if res.Valid {
+		return E.Of[error](res)
+	}
+	return E.Left[*ValidateResult](fmt.Errorf("the contract has %d schema violation(s)", len(res.Violations)))
+}
+
+// AttestationDecrypterFromConfig constructs the decrypter for attestation records based on a config object
+func AttestationDecrypterFromConfig(cfg *AttestationConfig) IOE.IOEither[error, ATIOE.Decrypter] {
+	// decryption module
+	decryption := F.Pipe1(
+		cfg.Mode,
+		getDecryption,
+	)
+	// attestation private key
+	privKey := F.Pipe1(
+		missingDecryptionKey,
+		getKeyFromConfig(cfg.PrivKey),
+	)
+
+	return F.Pipe3(
+		decryption,
+		IO.Map(func(dec Encrypt.Decryption) func([]byte) ATIOE.Decrypter {
+			return dec.DecryptBasic
+		}),
+		IOE.FromIO[error, func([]byte) ATIOE.Decrypter],
+		IOE.Ap[ATIOE.Decrypter](privKey),
+	)
+}
+
+// AttestationVerifierFromConfig constructs a function that verifies an attestation record against the contract
+// referenced by the config object
+func AttestationVerifierFromConfig(cfg *AttestationConfig) IOE.IOEither[error, func([]byte) IOE.IOEither[error, *AT.Verification]] {
+	return F.Pipe1(
+		CFIOE.ReadFromInput(cfg.Contract),
+		IOE.Map[error](ATIOE.VerifyRecord(AttestationDecrypterFromConfig(cfg))),
+	)
+}
+
+// verificationToEither fails if the attestation record does not match the contract
+func verificationToEither(ver *AT.Verification) E.Either[error, *AT.Verification] {
+	if ver.Valid {
+		return E.Of[error](ver)
+	}
+	return E.Left[*AT.Verification](fmt.Errorf("the attestation record does not match the contract"))
+}
+
+// passphraseFromConfig returns the optional passphrase, a trailing line break (e.g. from a file) is not part of it
+func passphraseFromConfig(cfg KeyConfig) IOE.IOEither[error, O.Option[[]byte]] {
+	return F.Pipe1(
+		getKeyFromConfig(cfg)(noPassphrase),
+		IOE.Map[error](F.Flow4(
+			B.ToString,
+			F.Bind2nd(strings.TrimRight, "\r\n"),
+			O.FromPredicate(S.IsNonEmpty),
+			O.Map(S.ToBytes),
 		)),
 	)
 }
+
+// protectPrivKey encrypts the private key with the passphrase, if present
+func protectPrivKey(enc Encrypt.Encryption, privKey []byte) func(O.Option[[]byte]) IOE.IOEither[error, []byte] {
+	return O.Fold(
+		F.Constant(IOE.Of[error](privKey)),
+		enc.GetEncryptPrivKey()(privKey),
+	)
+}
+
+// KeygenFromConfig generates a new key pair and persists private key, public key and fingerprint in the output folder.
+// Existing keys are never overwritten.
+func KeygenFromConfig(cfg *KeygenConfig) IOE.IOEither[error, *KeygenResult] {
+	res := &KeygenResult{
+		Purpose:         cfg.Purpose,
+		ContractField:   purposeToContractField[cfg.Purpose],
+		PrivateKey:      filepath.Join(cfg.OutDir, fmt.Sprintf("%s.key", cfg.Purpose)),
+		PublicKey:       filepath.Join(cfg.OutDir, fmt.Sprintf("%s.pub", cfg.Purpose)),
+		FingerprintFile: filepath.Join(cfg.OutDir, fmt.Sprintf("%s.fingerprint", cfg.Purpose)),
+	}
+	// make sure the output folder exists, it is only accessible by the owner
+	mkdirIOE := IOE.TryCatchError(func() (string, error) {
+		return cfg.OutDir, os.MkdirAll(cfg.OutDir, 0700)
+	})
+
+	return F.Pipe1(
+		IOE.SequenceT3(
+			IOE.FromIO[error](getEncryption(cfg.Mode)),
+			passphraseFromConfig(cfg.Passphrase),
+			mkdirIOE,
+		),
+		IOE.Chain(T.Tupled3(func(enc Encrypt.Encryption, passphrase O.Option[[]byte], _ string) IOE.IOEither[error, *KeygenResult] {
+			return F.Pipe1(
+				enc.GetPrivKey(),
+				IOE.Chain(func(privKey []byte) IOE.IOEither[error, *KeygenResult] {
+					// the public key and the fingerprint are derived from the unencrypted private key
+					pubKeyIOE := F.Pipe2(
+						enc.GetPubKey()(privKey),
+						IOE.FromEither[error, []byte],
+						IOE.Chain(CFIOE.WriteToNewFile(res.PublicKey, 0644)),
+					)
+					fingerprintIOE := F.Pipe3(
+						enc.GetPrivKeyFingerprint()(privKey),
+						E.Map[error](hex.EncodeToString),
+						IOE.FromEither[error, string],
+						IOE.ChainFirst(F.Flow2(
+							func(fp string) []byte { return []byte(fp + "\n") },
+							CFIOE.WriteToNewFile(res.FingerprintFile, 0644),
+						)),
+					)
+					privKeyIOE := F.Pipe2(
+						passphrase,
+						protectPrivKey(enc, privKey),
+						IOE.Chain(CFIOE.WriteToNewFile(res.PrivateKey, 0600)),
+					)
+					return F.Pipe1(
+						IOE.SequenceT3(privKeyIOE, pubKeyIOE, fingerprintIOE),
+						IOE.Map[error](T.Tupled3(func(_, _ []byte, fp string) *KeygenResult {
+							res.Fingerprint = fp
+							res.Encrypted = O.IsSome(passphrase)
+							return res
+						})),
+					)
+				}),
+			)
+		})),
+	)
+}
+
+// caCertsFromConfig reads the additional root CAs of a download
+func caCertsFromConfig(cfg *DownloadCertificatesConfig) IOE.IOEither[error, []byte] {
+	return F.Pipe2(
+		cfg.CaCerts,
+		IOE.TraverseArray(CFIOE.ReadFromInput),
+		IOE.Map[error](F.Bind2nd(bytes.Join, []byte("\n"))),
+	)
+}
+
+// DownloadCertificatesFromConfig dowloads certificates based on some config, certificates are resolved via the local
+// certificate cache. If no versions are given, the available versions are discovered. Only versions that match the spec
+// are returned
+func DownloadCertificatesFromConfig(cfg *DownloadCertificatesConfig) IOE.IOEither[error, []C.DownloadResult] {
+	resolver := CE.ParseResolver(cfg.UrlTemplate)
+	cache := F.Pipe1(
+		getCacheDir(cfg.CacheDir),
+		IOE.Map[error](func(dir string) *C.CacheConfig {
+			return &C.CacheConfig{
+				Dir:     dir,
+				TTL:     cfg.CacheTTL,
+				Refresh: cfg.Refresh,
+			}
+		}),
+	)
+	client := F.Pipe2(
+		caCertsFromConfig(cfg),
+		IOE.Chain(CIOE.MakeHttpClient(&cfg.Download)),
+		IOE.Map[error](IOEH.MakeClient),
+	)
+	spec := IOE.FromEither(CE.ParseConstraint(cfg.Spec))
+
+	return F.Pipe1(
+		IOE.SequenceT3(client, cache, spec),
+		IOE.Chain(T.Tupled3(func(client IOEH.Client, cache *C.CacheConfig, spec *semver.Constraints) IOE.IOEither[error, []C.DownloadResult] {
+			if len(cfg.Versions) == 0 {
+				discovery := &C.DiscoveryConfig{
+					Start:     C.DefaultDiscoveryConfig.Start,
+					MaxMisses: cfg.MaxMisses,
+				}
+				return F.Pipe2(
+					CIOE.DiscoverCertificatesCached(client, &cfg.Download)(cache)(resolver)(discovery),
+					IOE.Map[error](C.FilterCertsBySpec(spec)),
+					IOE.Map[error](A.Map(T.Map2(F.Identity[C.Version], E.Of[error, string]))),
+				)
+			}
+			return F.Pipe3(
+				cfg.Versions,
+				E.TraverseArray(CE.ParseVersion),
+				E.Map[error](A.Filter(spec.Check)),
+				E.Fold(IOE.Left[[]C.DownloadResult, error], CIOE.DownloadCertificatesCached(client, &cfg.Download)(cache)(resolver)),
+			)
+		})),
+	)
+}
+
+// writeDownloadsFromContext writes the downloaded certificates and reports failed downloads on the error writer of the
+// app. The operation fails after the successful downloads have been written if any download failed
+func writeDownloadsFromContext(ctx *cli.Context) func([]C.DownloadResult) IOE.IOEither[error, []byte] {
+	write := writeFromContext[map[string]string](ctx)
+	return func(results []C.DownloadResult) IOE.IOEither[error, []byte] {
+		partition := C.PartitionDownloads(results)
+		errs, certs := partition.F1, partition.F2
+		return F.Pipe4(
+			certs,
+			A.Map(T.Map2(C.Version.String, F.Identity[string])),
+			RR.FromEntries[string, string],
+			write,
+			IOE.Chain(func(data []byte) IOE.IOEither[error, []byte] {
+				if len(errs) == 0 {
+					return IOE.Of[error](data)
+				}
+				return IOE.TryCatchError(func() ([]byte, error) {
+					for _, err := range errs {
+						if _, werr := fmt.Fprintf(ctx.App.ErrWriter, "failed: %v\n", err); werr != nil {
+							return data, werr
+						}
+					}
+					return data, fmt.Errorf("unable to download %d of %d certificate(s)", len(errs), len(results))
+				})
+			}),
+		)
+	}
+}
+
+// validatePayloadSize checks that the payload of the self-test is not empty
+func validatePayloadSize(_ *cli.Context, size int) error {
+	if size <= 0 {
+		return fmt.Errorf("the payload size [%d] must be positive", size)
+	}
+	return nil
+}
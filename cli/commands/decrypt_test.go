@@ -0,0 +1,95 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
+	"github.com/ibm-hyper-protect/contract-go/types"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// createTestKeyPair creates an RSA key pair and persists private and public key in the build folder
+func createTestKeyPair(t *testing.T, name string) (string, string) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	privKeyName := fmt.Sprintf("../../build/%s.key", name)
+	pubKeyName := fmt.Sprintf("../../build/%s.pub", name)
+
+	privKey, err := E.UnwrapError(Encrypt.CryptoPrivateKey())
+	require.NoError(t, err)
+	pubKey, err := E.UnwrapError(Encrypt.CryptoPublicKey(privKey))
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(privKeyName, privKey, 0600))
+	require.NoError(t, os.WriteFile(pubKeyName, pubKey, 0600))
+
+	return privKeyName, pubKeyName
+}
+
+func TestDecryptCommand(t *testing.T) {
+
+	inName := "../samples/simple.yaml"
+	encName := "../../build/TestDecryptCommand.encrypted.yaml"
+	outName := "../../build/TestDecryptCommand.yaml"
+
+	// key pair used to encrypt and decrypt
+	privKeyName, pubKeyName := createTestKeyPair(t, "TestDecryptCommand")
+
+	encCmd := EncryptAndSignCommand()
+	decCmd := DecryptCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.From(encCmd, decCmd),
+	}
+
+	encArgs := A.From(os.Args[0], encCmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), encName, fmt.Sprintf("--%s", flagCertFile.Name), pubKeyName)
+	require.NoError(t, app.Run(encArgs))
+
+	decArgs := A.From(os.Args[0], decCmd.Name, fmt.Sprintf("--%s", flagInput.Name), encName, fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagDecryptionKeyFile.Name), privKeyName)
+	require.NoError(t, app.Run(decArgs))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	decrypted, err := E.UnwrapError(Y.Parse[types.AnyMap](data))
+	require.NoError(t, err)
+
+	assert.Equal(t, "workload", decrypted["workload"].(types.AnyMap)["type"])
+	assert.Equal(t, "env", decrypted["env"].(types.AnyMap)["type"])
+}
+
+func TestDecryptCommandWithoutKey(t *testing.T) {
+
+	cmd := DecryptCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	args := A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), "../samples/simple.yaml")
+	assert.Error(t, app.Run(args))
+}
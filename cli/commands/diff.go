@@ -0,0 +1,41 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	F "github.com/IBM/fp-go/function"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// DiffCommand returns a command that reports the semantic difference between two plaintext contracts
+func DiffCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "diff",
+		Usage:       "semantic diff between two plaintext contracts",
+		ArgsUsage:   "<old contract> <new contract>",
+		Description: "Reports added, removed and changed environment variables, registry credentials, volumes, logging endpoints and image trust entries of two plaintext HPCR contracts. The files of compose and play archives are compared line by line. Secret values are masked unless requested otherwise",
+		Flags: []cli.Flag{
+			flagOutput,
+			flagDiffFormat,
+			flagShowSecrets,
+		},
+		Action: F.Flow2(
+			DiffAndWriteFromContext,
+			U.RunIOEither[[]byte],
+		),
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func runDiff(t *testing.T, outName string, extra ...string) string {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	cmd := DiffCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	args := A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagOutput.Name), outName)
+	args = append(args, extra...)
+	require.NoError(t, app.Run(append(args, "../samples/diff/old.yaml", "../samples/diff/new.yaml")))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestDiffCommand(t *testing.T) {
+	text := runDiff(t, "../../build/TestDiffCommand.txt")
+
+	assert.Contains(t, text, "changed /env/logging/logDNA/hostname: syslog-a.eu-de.logging.cloud.ibm.com -> syslog-a.us-south.logging.cloud.ibm.com")
+	assert.Contains(t, text, "changed /workload/auths/us.icr.io/password: ******** -> ********")
+	assert.Contains(t, text, "changed /workload/env/LOG_LEVEL: ******** -> ********")
+	assert.Contains(t, text, "changed /workload/compose/archive/docker-compose.yml")
+	assert.Contains(t, text, "+    restart: always")
+	assert.NotContains(t, text, "old-secret")
+	assert.NotContains(t, text, "ingestionKey")
+}
+
+func TestDiffCommandShowSecrets(t *testing.T) {
+	text := runDiff(t, "../../build/TestDiffCommandShowSecrets.txt", fmt.Sprintf("--%s", flagShowSecrets.Name))
+
+	assert.Contains(t, text, "changed /workload/auths/us.icr.io/password: old-secret -> new-secret")
+}
+
+func TestDiffCommandArguments(t *testing.T) {
+	cmd := DiffCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	assert.Error(t, app.Run(A.From(os.Args[0], cmd.Name, "../samples/diff/old.yaml")))
+}
@@ -26,12 +26,23 @@ func DownloadCertificatesCommand() *cli.Command {
 	return &cli.Command{
 		Name:        "download-certificates",
 		Usage:       "Downloads certificates",
-		Description: "Downloads certificates that match the given version specifications",
+		Description: "Downloads certificates that match the given version specifications. Without explicit versions the available versions are discovered by probing the URL template. Certificates are stored in a local cache and served from the cache while they are fresh or if the download fails",
 		Flags: []cli.Flag{
 			flagOutput,
 			flagFormat,
 			flagVersions,
+			flagSpec,
+			flagMaxMisses,
 			flagUrlTemplate,
+			flagCacheDir,
+			flagCacheTTL,
+			flagRefresh,
+			flagConcurrency,
+			flagRetries,
+			flagBackoff,
+			flagRequestTimeout,
+			flagTimeout,
+			flagCaCert,
 		},
 		Action: F.Flow2(
 			DownloadCertificatesAndWriteFromContext,
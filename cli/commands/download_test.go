@@ -16,11 +16,17 @@
 package commands
 
 import (
+	"bytes"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
@@ -42,8 +48,89 @@ func TestDownloadCerts(t *testing.T) {
 
 	mergeArgs := A.Monoid[string]()
 
-	args := mergeArgs.Concat(A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagOutput.Name), outName), A.PrependAll(fmt.Sprintf("--%s", flagVersions.Name))(versions))
+	args := mergeArgs.Concat(A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagCacheDir.Name), t.TempDir()), A.PrependAll(fmt.Sprintf("--%s", flagVersions.Name))(versions))
 	assert.NoError(t, app.Run(args))
 
 	// TODO validate output here
 }
+
+func TestDownloadCertsPartialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/1.0.11") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, "cert %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	outName := "../../build/TestDownloadCertsPartialFailure.yaml"
+
+	cmd := DownloadCertificatesCommand()
+
+	var report bytes.Buffer
+	app := &cli.App{
+		Name:      "contract-cli",
+		Commands:  A.Of(cmd),
+		ErrWriter: &report,
+	}
+
+	args := A.From(
+		os.Args[0], cmd.Name,
+		fmt.Sprintf("--%s", flagOutput.Name), outName,
+		fmt.Sprintf("--%s", flagCacheDir.Name), t.TempDir(),
+		fmt.Sprintf("--%s", flagUrlTemplate.Name), server.URL+"/{{.Major}}.{{.Minor}}.{{.Patch}}",
+		fmt.Sprintf("--%s", flagBackoff.Name), "1ms",
+		fmt.Sprintf("--%s", flagVersions.Name), "1.0.10",
+		fmt.Sprintf("--%s", flagVersions.Name), "1.0.11",
+	)
+	assert.Error(t, app.Run(args))
+
+	// the successful downloads are written nevertheless
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+	assert.Equal(t, E.Of[error](map[string]string{"1.0.10": "cert /1.0.10"}), Y.Parse[map[string]string](data))
+	// the failed downloads are reported on the error writer
+	assert.True(t, strings.HasPrefix(report.String(), "failed: version [1.0.11]"), report.String())
+}
+
+func TestDownloadCertsDiscovery(t *testing.T) {
+	available := A.From("1.0.2", "1.0.5", "1.0.10", "1.0.11")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := strings.TrimPrefix(r.URL.Path, "/")
+		for _, v := range available {
+			if v == version {
+				fmt.Fprintf(w, "cert %s", version)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	outName := "../../build/TestDownloadCertsDiscovery.yaml"
+
+	cmd := DownloadCertificatesCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	args := A.From(
+		os.Args[0], cmd.Name,
+		fmt.Sprintf("--%s", flagOutput.Name), outName,
+		fmt.Sprintf("--%s", flagCacheDir.Name), t.TempDir(),
+		fmt.Sprintf("--%s", flagUrlTemplate.Name), server.URL+"/{{.Major}}.{{.Minor}}.{{.Patch}}",
+		fmt.Sprintf("--%s", flagSpec.Name), ">=1.0.10",
+	)
+	require.NoError(t, app.Run(args))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+	assert.Equal(t, E.Of[error](map[string]string{"1.0.10": "cert 1.0.10", "1.0.11": "cert 1.0.11"}), Y.Parse[map[string]string](data))
+}
@@ -26,19 +26,33 @@ func EncryptAndSignCommand() *cli.Command {
 	return &cli.Command{
 		Name:        "encrypt",
 		Usage:       "encrypt a contract",
-		Description: "Encypts an HPCR contract",
+		Description: "Encypts an HPCR contract. Use --section to encrypt the workload or the env separately and combine them with the sign command. Use --batch to encrypt all contracts of a directory or tar file",
 		Flags: []cli.Flag{
 			flagInput,
 			flagOutput,
 			flagFormat,
 			flagMode,
+			flagSection,
 			flagPrivKey,
 			flagPrivKeyFile,
 			flagCert,
 			flagCertFile,
+			flagHpcrVersion,
+			flagCacheDir,
+			flagVerifyCert,
+			flagCertBundle,
+			flagCRL,
+			flagMinKeySize,
+			flagVar,
+			flagVarFile,
+			flagDotEnv,
+			flagTemplateSyntax,
+			flagBatch,
+			flagSummary,
+			flagConcurrency,
 		},
 		Action: F.Flow2(
-			EncryptSignAndWriteFromContext,
+			EncryptAndWriteFromContext,
 			U.RunIOEither[[]byte],
 		),
 	}
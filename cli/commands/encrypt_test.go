@@ -19,8 +19,15 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	O "github.com/IBM/fp-go/option"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+	CE "github.com/ibm-hyper-protect/contract-go/certificates/either"
+	CIOE "github.com/ibm-hyper-protect/contract-go/certificates/ioeither"
+	D "github.com/ibm-hyper-protect/contract-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
@@ -65,3 +72,56 @@ func TestEncryptCommandJson(t *testing.T) {
 
 	// TODO validate output here
 }
+
+func TestEncryptCommandHpcrVersion(t *testing.T) {
+
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	inName := "../samples/simple.yaml"
+	outName := "../../build/TestEncryptCommandHpcrVersion.yaml"
+
+	cmd := EncryptAndSignCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	args := A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagHpcrVersion.Name), "~1.0.10")
+	assert.NoError(t, app.Run(args))
+
+	// no built-in certificate matches
+	args = A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagHpcrVersion.Name), ">=2.0.0")
+	assert.Error(t, app.Run(args))
+}
+
+func TestDefaultCertificateFromCatalog(t *testing.T) {
+	getCert := getDefaultCertificate(O.Some(t.TempDir()))
+
+	cert, err := E.UnwrapError(getCert(O.Some("~1.0.10"))())
+	require.NoError(t, err)
+	assert.Equal(t, D.Certificates["1.0.12"], string(cert))
+
+	cert, err = E.UnwrapError(getCert(O.Some("1.0.10"))())
+	require.NoError(t, err)
+	assert.Equal(t, D.Certificates["1.0.10"], string(cert))
+
+	cert, err = E.UnwrapError(getCert(O.None[string]())())
+	require.NoError(t, err)
+	assert.Equal(t, D.DefaultCertificate, string(cert))
+}
+
+func TestDefaultCertificateFromCache(t *testing.T) {
+	dir := t.TempDir()
+
+	// pretend that a newer certificate has been downloaded
+	index := C.CacheIndex{
+		"1.0.13": CE.MakeCacheEntry("https://example.com/1.0.13", time.Now())(D.Certificates["1.0.10"]),
+	}
+	_, err := E.UnwrapError(CIOE.WriteCacheIndex(dir)(index)())
+	require.NoError(t, err)
+
+	cert, err := E.UnwrapError(getDefaultCertificate(O.Some(dir))(O.Some("~1.0.10"))())
+	require.NoError(t, err)
+	assert.Equal(t, D.Certificates["1.0.10"], string(cert))
+}
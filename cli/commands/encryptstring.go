@@ -0,0 +1,49 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	F "github.com/IBM/fp-go/function"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// EncryptStringCommand returns a command that encrypts a single value into a hyper-protect-basic token
+func EncryptStringCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "encrypt-string",
+		Usage:       "encrypt a single value",
+		Description: "Encrypts a single value, given as text or read from a file or stdin, into a 'hyper-protect-basic.<password>.<token>' string that can be pasted into a contract",
+		Flags: []cli.Flag{
+			flagInput,
+			flagText,
+			flagOutput,
+			flagMode,
+			flagCert,
+			flagCertFile,
+			flagHpcrVersion,
+			flagCacheDir,
+			flagVerifyCert,
+			flagCertBundle,
+			flagCRL,
+			flagMinKeySize,
+		},
+		Action: F.Flow2(
+			EncryptStringAndWriteFromContext,
+			U.RunIOEither[[]byte],
+		),
+	}
+}
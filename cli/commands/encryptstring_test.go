@@ -0,0 +1,94 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	"github.com/ibm-hyper-protect/contract-go/common"
+	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// decryptToken decrypts a hyper-protect-basic token with the private key in the file
+func decryptToken(t *testing.T, privKeyName, token string) string {
+	privKey, err := os.ReadFile(privKeyName)
+	require.NoError(t, err)
+
+	data, err := E.UnwrapError(Encrypt.CryptoDecryptBasic(privKey)(token)())
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestEncryptStringCommand(t *testing.T) {
+	privKeyName, pubKeyName := createTestKeyPair(t, "TestEncryptStringCommand")
+
+	outName := "../../build/TestEncryptStringCommand.txt"
+
+	cmd := EncryptStringCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	args := A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagText.Name), "registry-password", fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagCertFile.Name), pubKeyName)
+	require.NoError(t, app.Run(args))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	token := strings.TrimSuffix(string(data), "\n")
+	assert.True(t, strings.HasPrefix(token, common.PrefixBasicEncoding+"."))
+	assert.Len(t, strings.Split(token, "."), 3)
+
+	assert.Equal(t, "registry-password", decryptToken(t, privKeyName, token))
+}
+
+func TestEncryptStringCommandFromFile(t *testing.T) {
+	privKeyName, pubKeyName := createTestKeyPair(t, "TestEncryptStringCommandFromFile")
+
+	inName := "../../build/TestEncryptStringCommandFromFile.in"
+	outName := "../../build/TestEncryptStringCommandFromFile.txt"
+
+	// the input is encrypted verbatim, including the trailing newline
+	require.NoError(t, os.WriteFile(inName, []byte("volume-seed\n"), 0600))
+
+	cmd := EncryptStringCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	for _, mode := range validModes {
+		args := A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagCertFile.Name), pubKeyName, fmt.Sprintf("--%s", flagMode.Name), mode)
+		require.NoError(t, app.Run(args))
+
+		data, err := os.ReadFile(outName)
+		require.NoError(t, err)
+
+		assert.Equal(t, "volume-seed\n", decryptToken(t, privKeyName, strings.TrimSuffix(string(data), "\n")))
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	F "github.com/IBM/fp-go/function"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// GenerateCommand returns a command that generates one encrypted contract per row of a matrix
+func GenerateCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "generate",
+		Usage:       "generate encrypted contracts from a template and a matrix",
+		Description: "Renders a contract template once per row of a CSV, YAML or JSON matrix, validates and encrypts each contract and writes one output per row into the output folder. A row may select its own encryption certificate. Failed rows are reported in the summary and do not stop the others",
+		Flags: []cli.Flag{
+			flagTemplate,
+			flagMatrix,
+			flagOutDir,
+			flagNameColumn,
+			flagCertColumn,
+			flagFormat,
+			flagSummary,
+			flagConcurrency,
+			flagMode,
+			flagPrivKey,
+			flagPrivKeyFile,
+			flagCert,
+			flagCertFile,
+			flagHpcrVersion,
+			flagCacheDir,
+			flagVerifyCert,
+			flagCertBundle,
+			flagCRL,
+			flagMinKeySize,
+			flagVar,
+			flagVarFile,
+			flagDotEnv,
+			flagTemplateSyntax,
+		},
+		Action: F.Flow2(
+			GenerateAndWriteFromContext,
+			U.RunIOEither[[]byte],
+		),
+	}
+}
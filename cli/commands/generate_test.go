@@ -0,0 +1,115 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// runGenerate runs the generate command
+func runGenerate(templateName, matrixName, outDir string, extra ...string) error {
+	cmd := GenerateCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	return app.Run(append(A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagTemplate.Name), templateName, fmt.Sprintf("--%s", flagMatrix.Name), matrixName, fmt.Sprintf("--%s", flagOutDir.Name), outDir), extra...))
+}
+
+// readGenerated decrypts the env of a generated contract
+func readGenerated(t *testing.T, privKeyName, name string) string {
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	encrypted, err := E.UnwrapError(Y.Parse[SC.EncryptedContract](data))
+	require.NoError(t, err)
+	assert.Contains(t, encrypted, SC.KeyEnvWorkloadSignature)
+	return decryptToken(t, privKeyName, encrypted[SC.KeyEnv])
+}
+
+func TestGenerateCommand(t *testing.T) {
+	outDir := "../../build/TestGenerateCommand"
+	require.NoError(t, os.RemoveAll(outDir))
+
+	encKeyName, encPubKeyName := createTestKeyPair(t, "TestGenerateCommand")
+
+	require.NoError(t, runGenerate("../samples/template/contract.yaml", "../samples/template/tenants.csv", outDir, fmt.Sprintf("--%s", flagCertFile.Name), encPubKeyName))
+
+	env := readGenerated(t, encKeyName, filepath.Join(outDir, "acme.yaml"))
+	assert.Contains(t, env, "0123456789abcdef0123456789abcde1")
+	assert.Contains(t, env, "6514")
+
+	env = readGenerated(t, encKeyName, filepath.Join(outDir, "globex.yaml"))
+	assert.Contains(t, env, "0123456789abcdef0123456789abcde2")
+	assert.Contains(t, env, "6515")
+}
+
+func TestGenerateCommandRowErrors(t *testing.T) {
+	dir := t.TempDir()
+	outDir := "../../build/TestGenerateCommandRowErrors"
+	summaryName := "../../build/TestGenerateCommandRowErrors.summary.yaml"
+	require.NoError(t, os.RemoveAll(outDir))
+
+	encKeyName, encPubKeyName := createTestKeyPair(t, "TestGenerateCommandRowErrors")
+	rowKeyName, rowPubKeyName := createTestKeyPair(t, "TestGenerateCommandRowErrorsRow")
+
+	// the row certificate is resolved relative to the matrix
+	rowCert, err := os.ReadFile(rowPubKeyName)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "row.pub"), rowCert, 0600))
+
+	matrixName := filepath.Join(dir, "tenants.yaml")
+	require.NoError(t, os.WriteFile(matrixName, []byte(`
+- name: shared
+  INGESTION_KEY: 0123456789abcdef0123456789abcde3
+- name: own
+  INGESTION_KEY: 0123456789abcdef0123456789abcde4
+  cert: row.pub
+- name: missing
+- name: shared
+  INGESTION_KEY: 0123456789abcdef0123456789abcde5
+- name: ../escape
+  INGESTION_KEY: 0123456789abcdef0123456789abcde6
+`), 0600))
+
+	err = runGenerate("../samples/template/contract.yaml", matrixName, outDir, fmt.Sprintf("--%s", flagCertFile.Name), encPubKeyName, fmt.Sprintf("--%s", flagVar.Name), "LOG_HOSTNAME=syslog.example.com", fmt.Sprintf("--%s", flagSummary.Name), summaryName)
+	assert.Error(t, err)
+
+	data, err := os.ReadFile(summaryName)
+	require.NoError(t, err)
+	summary, err := E.UnwrapError(Y.Parse[BatchResult](data))
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, summary.Total)
+	assert.Equal(t, 2, summary.Encrypted)
+	assert.Equal(t, 3, summary.Failed)
+
+	assert.Contains(t, readGenerated(t, encKeyName, filepath.Join(outDir, "shared.yaml")), "0123456789abcdef0123456789abcde3")
+	assert.Contains(t, readGenerated(t, rowKeyName, filepath.Join(outDir, "own.yaml")), "0123456789abcdef0123456789abcde4")
+	assert.NoFileExists(t, filepath.Join(outDir, "missing.yaml"))
+}
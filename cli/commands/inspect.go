@@ -0,0 +1,40 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	F "github.com/IBM/fp-go/function"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// InspectCommand returns a command that describes an encrypted contract without decrypting it
+func InspectCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "inspect",
+		Usage:       "describe an encrypted contract without decrypting it",
+		Description: "Lists the top level keys of an encrypted HPCR contract, the structure of their hyper-protect-basic tokens, if the contract is signed and which sections remain in plaintext",
+		Flags: []cli.Flag{
+			flagInput,
+			flagOutput,
+			flagInspectFormat,
+		},
+		Action: F.Flow2(
+			InspectAndWriteFromContext,
+			U.RunIOEither[[]byte],
+		),
+	}
+}
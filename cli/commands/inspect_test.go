@@ -0,0 +1,88 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestInspectCommand(t *testing.T) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	encName := "../../build/TestInspectCommand.encrypted.yaml"
+	outName := "../../build/TestInspectCommand.yaml"
+
+	encrypt := EncryptAndSignCommand()
+	inspect := InspectCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.From(encrypt, inspect),
+	}
+
+	require.NoError(t, app.Run(A.From(os.Args[0], encrypt.Name, fmt.Sprintf("--%s", flagInput.Name), "../samples/simple.yaml", fmt.Sprintf("--%s", flagOutput.Name), encName)))
+	require.NoError(t, app.Run(A.From(os.Args[0], inspect.Name, fmt.Sprintf("--%s", flagInput.Name), encName, fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagInspectFormat.Name), FormatYaml)))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	res, err := E.UnwrapError(Y.Parse[SC.ContractInspection](data))
+	require.NoError(t, err)
+
+	assert.True(t, res.Signed)
+	assert.Empty(t, res.PlaintextSections)
+	for _, section := range res.Sections {
+		if section.Key == SC.KeyEnv || section.Key == SC.KeyWorkload {
+			assert.True(t, section.Token, section.Key)
+			assert.True(t, section.Details.Salted, section.Key)
+			assert.Equal(t, 4096, section.Details.KeySize, section.Key)
+		}
+	}
+}
+
+func TestInspectCommandHalfEncrypted(t *testing.T) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	outName := "../../build/TestInspectCommandHalfEncrypted.txt"
+
+	cmd := InspectCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	require.NoError(t, app.Run(A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), "../samples/half-encrypted.yaml", fmt.Sprintf("--%s", flagOutput.Name), outName)))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	text := string(data)
+	assert.Contains(t, text, "env: plaintext, expected a hyper-protect-basic token")
+	assert.Contains(t, text, "workload: hyper-protect-basic token, RSA ciphertext 512 bytes (4096 bit key), Salted__ header, payload 32 bytes")
+	assert.Contains(t, text, "signature: missing")
+	assert.Contains(t, text, "plaintext sections: env")
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	F "github.com/IBM/fp-go/function"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// KeygenCommand returns a command that generates a key pair for signing or attestation
+func KeygenCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "keygen",
+		Usage:       "generate a key pair for contract signing or attestation",
+		Description: "Generates an RSA 4096 key pair and stores the private key, the public key and the fingerprint of the key in the output folder",
+		Flags: []cli.Flag{
+			flagPurpose,
+			flagOutDir,
+			flagPassphrase,
+			flagPassphraseFile,
+			flagOutput,
+			flagFormat,
+			flagMode,
+		},
+		Action: F.Flow2(
+			KeygenAndWriteFromContext,
+			U.RunIOEither[[]byte],
+		),
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	EC "github.com/ibm-hyper-protect/contract-go/encrypt/common"
+	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func runKeygen(t *testing.T, outDir string, args ...string) error {
+	require.NoError(t, os.RemoveAll(outDir))
+
+	cmd := KeygenCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	return app.Run(append(A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagOutDir.Name), outDir, fmt.Sprintf("--%s", flagOutput.Name), filepath.Join(outDir, "result.yaml")), args...))
+}
+
+func TestKeygenCommand(t *testing.T) {
+	outDir := "../../build/TestKeygenCommand"
+
+	require.NoError(t, runKeygen(t, outDir, fmt.Sprintf("--%s", flagPurpose.Name), PurposeAttestation))
+
+	privKeyName := filepath.Join(outDir, "attestation.key")
+	stat, err := os.Stat(privKeyName)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+
+	privKey, err := os.ReadFile(privKeyName)
+	require.NoError(t, err)
+	pubKey, err := os.ReadFile(filepath.Join(outDir, "attestation.pub"))
+	require.NoError(t, err)
+	fingerprint, err := os.ReadFile(filepath.Join(outDir, "attestation.fingerprint"))
+	require.NoError(t, err)
+
+	// the public key and the fingerprint match the private key
+	expPubKey, err := E.UnwrapError(Encrypt.CryptoPublicKey(privKey))
+	require.NoError(t, err)
+	assert.Equal(t, expPubKey, pubKey)
+
+	expFingerprint, err := E.UnwrapError(Encrypt.CryptoPrivKeyFingerprint(privKey))
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(expFingerprint), strings.TrimSpace(string(fingerprint)))
+
+	// existing keys are not overwritten
+	cmd := KeygenCommand()
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+	assert.Error(t, app.Run(A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagOutDir.Name), outDir, fmt.Sprintf("--%s", flagPurpose.Name), PurposeAttestation)))
+}
+
+func TestKeygenCommandWithPassphrase(t *testing.T) {
+	outDir := "../../build/TestKeygenCommandWithPassphrase"
+
+	require.NoError(t, runKeygen(t, outDir, fmt.Sprintf("--%s", flagPassphrase.Name), "secret"))
+
+	privKey, err := os.ReadFile(filepath.Join(outDir, "signing.key"))
+	require.NoError(t, err)
+
+	blocks := EC.PemDecodeAll(privKey)
+	require.Len(t, blocks, 1)
+	assert.Equal(t, EC.TypeEncryptedPrivateKey, blocks[0].Type)
+}
@@ -22,6 +22,11 @@ import (
 	L "github.com/IBM/fp-go/lazy"
 	O "github.com/IBM/fp-go/option"
 	S "github.com/IBM/fp-go/string"
+	T "github.com/IBM/fp-go/tuple"
+	"github.com/Masterminds/semver"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+	CE "github.com/ibm-hyper-protect/contract-go/certificates/either"
+	CIOE "github.com/ibm-hyper-protect/contract-go/certificates/ioeither"
 	D "github.com/ibm-hyper-protect/contract-go/data"
 	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
 	CF "github.com/ibm-hyper-protect/contract-go/file/ioeither"
@@ -44,8 +49,39 @@ var (
 	)
 )
 
-// getKey returns key content, either from direct input, a file or as a fallback transiently
-func getKey(direct, filename O.Option[string]) func(Encrypt.Key) Encrypt.Key {
+// getCacheDir returns the configured directory of the certificate cache or the default directory
+func getCacheDir(dir O.Option[string]) IOE.IOEither[error, string] {
+	return F.Pipe1(
+		dir,
+		O.Fold(F.Constant(CIOE.DefaultCacheDir), IOE.Of[error, string]),
+	)
+}
+
+// catalogCertificate selects the latest cached or built-in certificate that matches a semantic version range
+func catalogCertificate(cacheDir O.Option[string]) func(spec string) Encrypt.Key {
+	return func(spec string) Encrypt.Key {
+		return F.Pipe3(
+			IOE.SequenceT2(getCacheDir(cacheDir), IOE.FromEither(CE.ParseConstraint(spec))),
+			IOE.Chain(T.Tupled2(func(dir string, constraint *semver.Constraints) IOE.IOEither[error, C.VersionCert] {
+				return CIOE.CertificateFromSpecCached(dir)(constraint)
+			})),
+			IOE.Map[error](T.Second[C.Version, string]),
+			IOE.Map[error](S.ToBytes),
+		)
+	}
+}
+
+// getDefaultCertificate returns the cached or built-in certificate that matches the HPCR version range or the default
+// certificate if no range has been specified
+func getDefaultCertificate(cacheDir O.Option[string]) func(spec O.Option[string]) Encrypt.Key {
+	return F.Flow2(
+		O.Map(catalogCertificate(cacheDir)),
+		O.GetOrElse(F.Constant(defaultCertificate)),
+	)
+}
+
+// lookupKey returns key content from direct input or a file, if any has been specified
+func lookupKey(direct, filename O.Option[string]) O.Option[Encrypt.Key] {
 	fromDirect := F.Pipe1(
 		direct,
 		O.Map(keyDirect),
@@ -54,13 +90,28 @@ func getKey(direct, filename O.Option[string]) func(Encrypt.Key) Encrypt.Key {
 		filename,
 		O.Map(keyFromFile),
 	)
-	alt := O.AltMonoid[Encrypt.Key]()
+	return F.Pipe1(
+		A.From(fromDirect, fromFile),
+		A.Fold(O.AltMonoid[Encrypt.Key]()),
+	)
+}
+
+// getKey returns key content, either from direct input, a file or as a fallback transiently
+func getKey(direct, filename O.Option[string]) func(Encrypt.Key) Encrypt.Key {
+	key := lookupKey(direct, filename)
 
 	return func(defKey Encrypt.Key) Encrypt.Key {
-		return F.Pipe2(
-			A.From(fromDirect, fromFile),
-			A.Fold(alt),
+		return F.Pipe1(
+			key,
 			O.GetOrElse(L.Of(defKey)),
 		)
 	}
 }
+
+// optionalKeyFromConfig returns the key content of a config, if a key has been specified
+func optionalKeyFromConfig(cfg KeyConfig) IOE.IOEither[error, O.Option[[]byte]] {
+	return F.Pipe1(
+		lookupKey(cfg.FromDirect, cfg.FromFile),
+		O.Fold(F.Constant(IOE.Of[error](O.None[[]byte]())), IOE.Map[error](O.Some[[]byte])),
+	)
+}
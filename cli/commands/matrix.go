@@ -0,0 +1,185 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	I "github.com/IBM/fp-go/identity"
+	IO "github.com/IBM/fp-go/io"
+	IOE "github.com/IBM/fp-go/ioeither"
+	IOEF "github.com/IBM/fp-go/ioeither/file"
+	O "github.com/IBM/fp-go/option"
+	RR "github.com/IBM/fp-go/record"
+	T "github.com/IBM/fp-go/tuple"
+	CIO "github.com/ibm-hyper-protect/contract-go/common/io"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	TAIOE "github.com/ibm-hyper-protect/contract-go/tar/ioeither"
+	TPL "github.com/ibm-hyper-protect/contract-go/template"
+	TPLE "github.com/ibm-hyper-protect/contract-go/template/either"
+	TPLIOE "github.com/ibm-hyper-protect/contract-go/template/ioeither"
+	"github.com/ibm-hyper-protect/contract-go/types"
+	"github.com/urfave/cli/v2"
+)
+
+type (
+	// matrixRow is a row of a matrix together with the name of its output
+	matrixRow = T.Tuple2[string, E.Either[error, TPL.Vars]]
+)
+
+// rowName returns the name of the output of a row, the rows are numbered if the matrix has no name column
+func rowName(column string, idx int, vars TPL.Vars) E.Either[error, string] {
+	name, ok := vars[column]
+	if !ok {
+		return E.Of[error](strconv.Itoa(idx + 1))
+	}
+	if name == "" {
+		return E.Left[string](fmt.Errorf("the row has no value in the [%s] column", column))
+	}
+	if name == "." || name == ".." || filepath.Base(name) != name {
+		return E.Left[string](fmt.Errorf("the name [%s] must not contain a path", name))
+	}
+	return E.Of[error](name)
+}
+
+// matrixRows names the outputs of the rows of a matrix, rows with an invalid or a duplicate name fail
+func matrixRows(column, ext string) func(TPL.Matrix) []matrixRow {
+	return func(matrix TPL.Matrix) []matrixRow {
+		seen := make(map[string]bool)
+		rows := make([]matrixRow, len(matrix))
+		for idx, vars := range matrix {
+			label := fmt.Sprintf("row %d", idx+1)
+			name, err := E.UnwrapError(rowName(column, idx, vars))
+			switch {
+			case err != nil:
+				rows[idx] = T.MakeTuple2(label, E.Left[TPL.Vars](err))
+			case seen[name]:
+				rows[idx] = T.MakeTuple2(label, E.Left[TPL.Vars](fmt.Errorf("the name [%s] is not unique", name)))
+			default:
+				seen[name] = true
+				rows[idx] = T.MakeTuple2(fmt.Sprintf("%s.%s", name, ext), E.Of[error](vars))
+			}
+		}
+		return rows
+	}
+}
+
+// rowEncryptConfig returns the encryption config of a row, a row may specify its own encryption certificate relative
+// to the folder of the matrix
+func rowEncryptConfig(cfg *EncryptAndSignConfig, column, baseDir string) func(TPL.Vars) *EncryptAndSignConfig {
+	return func(vars TPL.Vars) *EncryptAndSignConfig {
+		cert := vars[column]
+		if cert == "" {
+			return cfg
+		}
+		if !filepath.IsAbs(cert) {
+			cert = filepath.Join(baseDir, cert)
+		}
+		resolved := *cfg
+		resolved.PubCert = KeyConfig{FromFile: O.Of(cert)}
+		return &resolved
+	}
+}
+
+// generateRow renders, validates, encrypts and serializes the contract of a single row. The values of the row take
+// precedence over the shared variables.
+func generateRow(
+	render func(TPL.Vars) func([]byte) E.Either[error, []byte],
+	source []byte,
+	templateName string,
+	shared TPL.Vars,
+	encConfig func(TPL.Vars) *EncryptAndSignConfig,
+	serialize func(SC.EncryptedContract) E.Either[error, []byte],
+) func(TPL.Vars) IOE.IOEither[error, []byte] {
+	union := RR.UnionLastMonoid[string, string]()
+	return func(row TPL.Vars) IOE.IOEither[error, []byte] {
+		return F.Pipe4(
+			IOE.FromEither(render(union.Concat(shared, row))(source)),
+			inlinedContractFromSource(templateName),
+			IOE.ChainEitherK(types.ValidateContract),
+			IOE.Chain(func(ctr *types.Contract) IOE.IOEither[error, SC.EncryptedContract] {
+				return F.Pipe1(
+					ContractEncrypterFromConfig(encConfig(row)),
+					IOE.Chain(I.Ap[IOE.IOEither[error, SC.EncryptedContract]](ctr)),
+				)
+			}),
+			IOE.ChainEitherK(serialize),
+		)
+	}
+}
+
+// generateRows generates the contracts of the rows in parallel with bounded concurrency, a row that cannot be rendered
+// or encrypted keeps its error next to its output name
+func generateRows(concurrency int, generate func(TPL.Vars) IOE.IOEither[error, []byte]) func([]matrixRow) IO.IO[[]batchFile] {
+	limit := CIO.LimitConcurrency[batchFile](concurrency)
+	return IO.TraverseArray(func(row matrixRow) IO.IO[batchFile] {
+		return limit(IO.MakeIO(func() batchFile {
+			return T.MakeTuple2(row.F1, F.Pipe2(
+				row.F2,
+				IOE.FromEither[error, TPL.Vars],
+				IOE.Chain(generate),
+			)())
+		}))
+	})
+}
+
+// GenerateFromContext renders the template on the [cli.Context] once per row of the matrix, encrypts each contract and
+// writes one output per row into the output folder. Failures of single rows are reported in the summary.
+func GenerateFromContext(ctx *cli.Context) IOE.IOEither[error, *BatchResult] {
+	cfg := EncryptAndSignConfigFromContext(ctx)
+	tmpl := TemplateConfigFromContext(ctx)
+	templateName := lookupTemplate(ctx)
+	matrixName := lookupMatrix(ctx)
+	format := lookupFormat(ctx)
+
+	return F.Pipe1(
+		IOE.SequenceT3(
+			IOEF.ReadFile(templateName),
+			TemplateVarsFromConfig(tmpl),
+			TPLIOE.MatrixFromFile(matrixName),
+		),
+		IOE.Chain(T.Tupled3(func(source []byte, shared TPL.Vars, matrix TPL.Matrix) IOE.IOEither[error, *BatchResult] {
+			generate := generateRow(
+				TPLE.RenderTemplate(tmpl.Syntax),
+				source,
+				templateName,
+				shared,
+				rowEncryptConfig(cfg, lookupCertColumn(ctx), filepath.Dir(matrixName)),
+				getSerializer[SC.EncryptedContract](format),
+			)
+			return F.Pipe4(
+				matrix,
+				matrixRows(lookupNameColumn(ctx), format),
+				generateRows(lookupConcurrency(ctx), generate),
+				IOE.FromIO[error, []batchFile],
+				IOE.Chain(writeBatch(TAIOE.ExtractToFolder(lookupOutDir(ctx), os.ModePerm))),
+			)
+		})),
+	)
+}
+
+// GenerateAndWriteFromContext generates one encrypted contract per row of a matrix and writes the summary
+func GenerateAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe1(
+		GenerateFromContext(ctx),
+		summarizeBatch(ctx),
+	)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	F "github.com/IBM/fp-go/function"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// MergeCommand returns a command that merges plaintext contracts
+func MergeCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "merge",
+		Usage:       "merge plaintext contracts",
+		ArgsUsage:   "<base contract> [<overlay contract> ...]",
+		Description: "Merges partial plaintext HPCR contracts from left to right, e.g. a workload persona file and per-environment env files. Overridden keys are reported on stderr",
+		Flags: []cli.Flag{
+			flagOutput,
+			flagFormat,
+			flagStrategy,
+		},
+		Action: F.Flow2(
+			MergeAndWriteFromContext,
+			U.RunIOEither[[]byte],
+		),
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	"github.com/ibm-hyper-protect/contract-go/types"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func mergeApp() (*cli.App, *cli.Command) {
+	cmd := MergeCommand()
+
+	return &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}, cmd
+}
+
+func TestMergeCommand(t *testing.T) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+	outName := "../../build/TestMergeCommand.yaml"
+
+	var report bytes.Buffer
+	app, cmd := mergeApp()
+	app.ErrWriter = &report
+	require.NoError(t, app.Run(A.From(os.Args[0], cmd.Name,
+		fmt.Sprintf("--%s", flagOutput.Name), outName,
+		fmt.Sprintf("--%s", flagStrategy.Name), types.StrategyDeepMerge,
+		"../samples/merge/base.yaml", "../samples/merge/prod.yaml")))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	contract, err := E.UnwrapError(Y.Parse[types.Contract](data))
+	require.NoError(t, err)
+
+	require.NotNil(t, contract.Workload)
+	require.NotNil(t, contract.Env)
+	assert.Equal(t, "warn", contract.Workload.Env["LOG_LEVEL"])
+	assert.Equal(t, "eu-de", contract.Workload.Env["REGION"])
+	assert.NotNil(t, contract.Workload.Compose)
+	assert.Contains(t, contract.Workload.Auths, "us.icr.io")
+	assert.NotNil(t, contract.Env.Logging)
+
+	assert.Equal(t, "overridden: /workload/env/LOG_LEVEL (overridden by ../samples/merge/prod.yaml)\n", report.String())
+}
+
+func TestMergeCommandErrorOnConflict(t *testing.T) {
+	app, cmd := mergeApp()
+
+	assert.Error(t, app.Run(A.From(os.Args[0], cmd.Name,
+		fmt.Sprintf("--%s", flagOutput.Name), "../../build/TestMergeCommandErrorOnConflict.yaml",
+		fmt.Sprintf("--%s", flagStrategy.Name), types.StrategyErrorOnConflict,
+		"../samples/merge/base.yaml", "../samples/merge/prod.yaml")))
+}
+
+func TestMergeCommandArguments(t *testing.T) {
+	app, cmd := mergeApp()
+
+	assert.Error(t, app.Run(A.From(os.Args[0], cmd.Name)))
+	assert.Error(t, app.Run(A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagStrategy.Name), "unknown", "../samples/merge/base.yaml")))
+}
@@ -0,0 +1,352 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	IOEF "github.com/IBM/fp-go/ioeither/file"
+	O "github.com/IBM/fp-go/option"
+	RR "github.com/IBM/fp-go/record"
+	CF "github.com/ibm-hyper-protect/contract-go/file"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/urfave/cli/v2"
+)
+
+type (
+	// resolvedProfile is a profile together with the folder of its configuration file, relative paths in the profile
+	// are resolved against this folder
+	resolvedProfile struct {
+		settings Profile
+		dir      string
+	}
+
+	// loadedConfig is the merged content of the configuration files
+	loadedConfig struct {
+		defaultProfile string
+		profiles       map[string]resolvedProfile
+	}
+)
+
+// readCliConfig reads a configuration file
+func readCliConfig(name string) IOE.IOEither[error, *loadedConfig] {
+	dir := filepath.Dir(name)
+	return F.Pipe3(
+		IOEF.ReadFile(name),
+		IOE.ChainEitherK(Y.Parse[CliConfig]),
+		IOE.Map[error](func(cfg CliConfig) *loadedConfig {
+			return &loadedConfig{
+				defaultProfile: cfg.DefaultProfile,
+				profiles: RR.Map[string](func(settings Profile) resolvedProfile {
+					return resolvedProfile{settings, dir}
+				})(cfg.Profiles),
+			}
+		}),
+		IOE.MapLeft[*loadedConfig](func(err error) error {
+			return fmt.Errorf("unable to read the configuration file [%s]: %w", name, err)
+		}),
+	)
+}
+
+// mergeCliConfigs merges configuration files, later files replace profiles of the same name and the default profile
+func mergeCliConfigs(cfgs []*loadedConfig) *loadedConfig {
+	merged := &loadedConfig{profiles: make(map[string]resolvedProfile)}
+	for _, cfg := range cfgs {
+		if cfg.defaultProfile != "" {
+			merged.defaultProfile = cfg.defaultProfile
+		}
+		for name, profile := range cfg.profiles {
+			merged.profiles[name] = profile
+		}
+	}
+	return merged
+}
+
+// fileExists tests if a regular file exists
+func fileExists(name string) (bool, error) {
+	info, err := os.Stat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil && info.Mode().IsRegular(), err
+}
+
+// findProjectConfig returns the nearest configuration file in the folder or its parents
+func findProjectConfig(dir string) (O.Option[string], error) {
+	for {
+		name := filepath.Join(dir, ConfigFileName)
+		ok, err := fileExists(name)
+		if err != nil {
+			return O.None[string](), err
+		}
+		if ok {
+			return O.Of(name), nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return O.None[string](), nil
+		}
+		dir = parent
+	}
+}
+
+// discoverConfigFiles returns the user configuration file followed by the project configuration file, if they exist
+func discoverConfigFiles() ([]string, error) {
+	var names []string
+	if home, err := os.UserHomeDir(); err == nil {
+		name := filepath.Join(home, ConfigFileName)
+		ok, err := fileExists(name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			names = append(names, name)
+		}
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	project, err := findProjectConfig(cwd)
+	if name, ok := O.Unwrap(project); ok && (len(names) == 0 || names[0] != name) {
+		names = append(names, name)
+	}
+	return names, err
+}
+
+// configFilesFromContext returns the configuration files, an explicitly specified file disables the discovery
+func configFilesFromContext(ctx *cli.Context) IOE.IOEither[error, []string] {
+	return F.Pipe1(
+		lookupConfig(ctx),
+		O.Fold(F.Constant(IOE.TryCatchError(discoverConfigFiles)), F.Flow2(
+			A.Of[string],
+			IOE.Of[error, []string],
+		)),
+	)
+}
+
+// selectProfile selects the named profile or the default profile, if any
+func selectProfile(name O.Option[string]) func(cfg *loadedConfig) E.Either[error, O.Option[resolvedProfile]] {
+	return func(cfg *loadedConfig) E.Either[error, O.Option[resolvedProfile]] {
+		selected := O.GetOrElse(F.Constant(cfg.defaultProfile))(name)
+		if selected == "" {
+			return E.Of[error](O.None[resolvedProfile]())
+		}
+		profile, ok := cfg.profiles[selected]
+		if !ok {
+			names := RR.Keys[string, resolvedProfile](cfg.profiles)
+			sort.Strings(names)
+			return E.Left[O.Option[resolvedProfile]](fmt.Errorf("unknown profile [%s], available profiles are %v", selected, names))
+		}
+		return E.Of[error](O.Of(profile))
+	}
+}
+
+// ProfileFromContext loads the configuration files and returns the selected profile, if any
+func ProfileFromContext(ctx *cli.Context) IOE.IOEither[error, O.Option[resolvedProfile]] {
+	return F.Pipe3(
+		configFilesFromContext(ctx),
+		IOE.Chain(IOE.TraverseArray(readCliConfig)),
+		IOE.Map[error](mergeCliConfigs),
+		IOE.ChainEitherK(selectProfile(lookupProfile(ctx))),
+	)
+}
+
+// knownFlags returns the names of the flags of all commands, a profile must not carry other settings
+func knownFlags(cmds []*cli.Command) map[string]bool {
+	known := make(map[string]bool)
+	for _, cmd := range cmds {
+		for _, flag := range cmd.Flags {
+			for _, name := range flag.Names() {
+				known[name] = true
+			}
+		}
+		for name := range knownFlags(cmd.Subcommands) {
+			known[name] = true
+		}
+	}
+	return known
+}
+
+// takesFile tests if the value of a flag is a filename
+func takesFile(flag cli.Flag) bool {
+	switch f := flag.(type) {
+	case *cli.StringFlag:
+		return f.TakesFile
+	case *cli.StringSliceFlag:
+		return f.TakesFile
+	}
+	return false
+}
+
+// profileValues converts the value of a setting into the values of a flag, relative filenames are resolved against
+// the folder of the configuration file
+func profileValues(flag cli.Flag, value any, dir string) ([]string, error) {
+	var values []any
+	switch v := value.(type) {
+	case []any:
+		if _, ok := flag.(*cli.StringSliceFlag); !ok {
+			return nil, fmt.Errorf("the setting [%s] does not accept a list", flag.Names()[0])
+		}
+		values = v
+	case map[string]any:
+		return nil, fmt.Errorf("the setting [%s] must not be a map", flag.Names()[0])
+	default:
+		values = A.Of[any](v)
+	}
+	return A.Map(func(v any) string {
+		s := fmt.Sprint(v)
+		if takesFile(flag) && s != CF.StdInOutIdentifier && !filepath.IsAbs(s) {
+			return filepath.Join(dir, s)
+		}
+		return s
+	})(values), nil
+}
+
+// findCommand returns the command of the given name
+func findCommand(cmds []*cli.Command, name string) O.Option[*cli.Command] {
+	return A.FindFirst(func(cmd *cli.Command) bool {
+		return cmd.HasName(name)
+	})(cmds)
+}
+
+// checkSettings verifies that the settings of a profile denote flags of the commands, a map denotes the settings scoped
+// to the command of that name
+func checkSettings(settings Profile, cmds []*cli.Command) error {
+	known := knownFlags(cmds)
+	for name, value := range settings {
+		if scope, ok := value.(map[string]any); ok {
+			cmd, found := O.Unwrap(findCommand(cmds, name))
+			if !found {
+				return fmt.Errorf("unknown command [%s] in the profile, expected the name of a command to scope settings", name)
+			}
+			scoped := cmd.Subcommands
+			if len(scoped) == 0 {
+				scoped = A.Of(cmd)
+			}
+			if err := checkSettings(scope, scoped); err != nil {
+				return err
+			}
+			continue
+		}
+		if !known[name] || name == flagProfile.Name || name == flagConfig.Name {
+			return fmt.Errorf("unknown setting [%s] in the profile, expected the name of a flag", name)
+		}
+	}
+	return nil
+}
+
+// commandPath returns the names of the commands from the root of the application to the current command
+func commandPath(ctx *cli.Context) []string {
+	var names []string
+	for _, c := range ctx.Lineage() {
+		if c.Command != nil && c.Command.Name != "" {
+			names = append(A.Of(c.Command.Name), names...)
+		}
+	}
+	return names
+}
+
+// scopedSettings returns the settings of a profile that apply to a command, settings scoped to a command take
+// precedence over the settings of its parents and over the shared settings
+func scopedSettings(settings Profile, path []string) Profile {
+	result := make(Profile)
+	apply := func(scope Profile) {
+		for name, value := range scope {
+			if _, ok := value.(map[string]any); !ok {
+				result[name] = value
+			}
+		}
+	}
+	apply(settings)
+	for _, name := range path {
+		if scope, ok := settings[name].(map[string]any); ok {
+			apply(scope)
+			settings = scope
+		}
+	}
+	return result
+}
+
+// applyProfile sets the flags of a command that have not been given explicitly to the values of the profile
+func applyProfile(ctx *cli.Context, profile resolvedProfile) error {
+	if err := checkSettings(profile.settings, Commands()); err != nil {
+		return err
+	}
+	settings := scopedSettings(profile.settings, commandPath(ctx))
+	for _, flag := range ctx.Command.Flags {
+		names := flag.Names()
+		if A.Any(ctx.IsSet)(names) {
+			continue
+		}
+		value, ok := settings[names[0]]
+		if !ok {
+			continue
+		}
+		values, err := profileValues(flag, value, profile.dir)
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			if err := ctx.Set(names[0], v); err != nil {
+				return fmt.Errorf("invalid value [%s] for the setting [%s] in the profile: %w", v, names[0], err)
+			}
+		}
+	}
+	return nil
+}
+
+// profileBefore applies the selected profile before the command runs
+func profileBefore(ctx *cli.Context) error {
+	profile, err := E.UnwrapError(ProfileFromContext(ctx)())
+	if err != nil {
+		return err
+	}
+	if p, ok := O.Unwrap(profile); ok {
+		return applyProfile(ctx, p)
+	}
+	return nil
+}
+
+// withProfile enables the profiles of the configuration file for a command and its subcommands. Explicit flags and
+// environment variables take precedence over the profile, the profile takes precedence over the defaults of the flags.
+func withProfile(cmd *cli.Command) *cli.Command {
+	if len(cmd.Subcommands) > 0 {
+		cmd.Subcommands = A.Map(withProfile)(cmd.Subcommands)
+		return cmd
+	}
+	cmd.Flags = append(cmd.Flags, flagProfile, flagConfig)
+	before := cmd.Before
+	cmd.Before = func(ctx *cli.Context) error {
+		if err := profileBefore(ctx); err != nil {
+			return err
+		}
+		if before != nil {
+			return before(ctx)
+		}
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	J "github.com/IBM/fp-go/json"
+	O "github.com/IBM/fp-go/option"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// writeTestConfig writes a configuration file with a dev and a prod profile, the dev profile encrypts with a test key
+func writeTestConfig(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	privKeyName, pubKeyName := createTestKeyPair(t, "TestProfile")
+
+	absPubKey, err := filepath.Abs(pubKeyName)
+	require.NoError(t, err)
+	require.NoError(t, os.Rename(absPubKey, filepath.Join(dir, "enc.pub")))
+
+	cfg := `defaultProfile: dev
+profiles:
+  dev:
+    mode: crypto
+    format: json
+    certfile: enc.pub
+  prod:
+    format: yaml
+  typo:
+    formt: yaml
+`
+	name := filepath.Join(dir, ConfigFileName)
+	require.NoError(t, os.WriteFile(name, []byte(cfg), 0600))
+
+	return name, privKeyName
+}
+
+func TestEncryptWithProfile(t *testing.T) {
+	cfgName, privKeyName := writeTestConfig(t)
+	outName := "../../build/TestEncryptWithProfile.out"
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: Commands(),
+	}
+
+	args := A.From(os.Args[0], "encrypt", fmt.Sprintf("--%s", flagInput.Name), "../samples/simple.yaml", fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagConfig.Name), cfgName)
+
+	isJson := func() bool {
+		data, err := os.ReadFile(outName)
+		require.NoError(t, err)
+		return E.IsRight(J.Unmarshal[SC.EncryptedContract](data))
+	}
+
+	// the default profile selects the format and the certificate relative to the configuration file
+	require.NoError(t, app.Run(args))
+	assert.True(t, isJson())
+
+	decArgs := A.From(os.Args[0], "decrypt", fmt.Sprintf("--%s", flagInput.Name), outName, fmt.Sprintf("--%s", flagOutput.Name), "../../build/TestEncryptWithProfile.yaml", fmt.Sprintf("--%s", flagDecryptionKeyFile.Name), privKeyName, fmt.Sprintf("--%s", flagConfig.Name), cfgName)
+	assert.NoError(t, app.Run(decArgs))
+
+	// explicit flags take precedence over the profile
+	require.NoError(t, app.Run(append(args, fmt.Sprintf("--%s", flagFormat.Name), FormatYaml)))
+	assert.False(t, isJson())
+
+	// the profile is selected by flag or by environment variable
+	require.NoError(t, app.Run(append(args, fmt.Sprintf("--%s", flagProfile.Name), "prod")))
+	assert.False(t, isJson())
+
+	t.Setenv(EnvProfile, "prod")
+	require.NoError(t, app.Run(args))
+	assert.False(t, isJson())
+
+	// unknown profiles and settings are errors
+	assert.Error(t, app.Run(append(args, fmt.Sprintf("--%s", flagProfile.Name), "unknown")))
+	assert.Error(t, app.Run(append(args, fmt.Sprintf("--%s", flagProfile.Name), "typo")))
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "a", "b")
+	require.NoError(t, os.MkdirAll(nested, os.ModePerm))
+
+	name, err := findProjectConfig(nested)
+	require.NoError(t, err)
+	assert.NotEqual(t, O.Of(filepath.Join(dir, ConfigFileName)), name)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("profiles: {}\n"), 0600))
+
+	name, err = findProjectConfig(nested)
+	require.NoError(t, err)
+	assert.Equal(t, O.Of(filepath.Join(dir, ConfigFileName)), name)
+}
+
+func TestProfileScopedToCommand(t *testing.T) {
+	dir := t.TempDir()
+	_, pubKeyName := createTestKeyPair(t, "TestProfileScopedToCommand")
+	absPubKey, err := filepath.Abs(pubKeyName)
+	require.NoError(t, err)
+
+	encName := "../../build/TestProfileScopedToCommand.encrypted"
+	reportName := "../../build/TestProfileScopedToCommand.sarif.json"
+
+	// the shared format is not valid for validate, the scoped one is not valid for encrypt
+	cfg := fmt.Sprintf(`profiles:
+  ci:
+    format: json
+    certfile: %s
+    validate:
+      format: sarif
+  broken:
+    encrypt:
+      formt: json
+  unknown:
+    encrpyt:
+      format: json
+`, absPubKey)
+	cfgName := filepath.Join(dir, ConfigFileName)
+	require.NoError(t, os.WriteFile(cfgName, []byte(cfg), 0600))
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: Commands(),
+	}
+
+	encArgs := A.From(os.Args[0], "encrypt", fmt.Sprintf("--%s", flagInput.Name), "../samples/simple.yaml", fmt.Sprintf("--%s", flagOutput.Name), encName, fmt.Sprintf("--%s", flagConfig.Name), cfgName)
+	require.NoError(t, app.Run(append(encArgs, fmt.Sprintf("--%s", flagProfile.Name), "ci")))
+	data, err := os.ReadFile(encName)
+	require.NoError(t, err)
+	assert.True(t, E.IsRight(J.Unmarshal[SC.EncryptedContract](data)))
+
+	require.NoError(t, app.Run(A.From(os.Args[0], "validate", fmt.Sprintf("--%s", flagInput.Name), "../samples/simple.yaml", fmt.Sprintf("--%s", flagOutput.Name), reportName, fmt.Sprintf("--%s", flagConfig.Name), cfgName, fmt.Sprintf("--%s", flagProfile.Name), "ci")))
+	data, err = os.ReadFile(reportName)
+	require.NoError(t, err)
+	log, err := E.UnwrapError(J.Unmarshal[SarifLog](data))
+	require.NoError(t, err)
+	assert.Equal(t, SarifVersion, log.Version)
+
+	// scoped settings must denote flags of the command and scopes must denote commands
+	assert.Error(t, app.Run(append(encArgs, fmt.Sprintf("--%s", flagProfile.Name), "broken")))
+	assert.Error(t, app.Run(append(encArgs, fmt.Sprintf("--%s", flagProfile.Name), "unknown")))
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	A "github.com/IBM/fp-go/array"
+	F "github.com/IBM/fp-go/function"
+	CF "github.com/ibm-hyper-protect/contract-go/file"
+	"github.com/ibm-hyper-protect/contract-go/types"
+)
+
+const (
+	// SarifSchema is the JSON schema of the SARIF log format
+	SarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+	// SarifVersion is the version of the SARIF log format
+	SarifVersion = "2.1.0"
+	// SarifToolName is the name of the tool that produces the report
+	SarifToolName = "contract-cli"
+	// SarifToolUri is the information URI of the tool that produces the report
+	SarifToolUri = "https://github.com/ibm-hyper-protect/contract-go"
+)
+
+type (
+	// SarifLog is the root object of a SARIF 2.1.0 report, restricted to the properties required to annotate findings
+	SarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []SarifRun `json:"runs"`
+	}
+
+	SarifRun struct {
+		Tool    SarifTool     `json:"tool"`
+		Results []SarifResult `json:"results"`
+	}
+
+	SarifTool struct {
+		Driver SarifDriver `json:"driver"`
+	}
+
+	SarifDriver struct {
+		Name           string      `json:"name"`
+		InformationUri string      `json:"informationUri"`
+		Rules          []SarifRule `json:"rules"`
+	}
+
+	SarifRule struct {
+		Id string `json:"id"`
+	}
+
+	SarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	SarifResult struct {
+		RuleId    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   SarifMessage    `json:"message"`
+		Locations []SarifLocation `json:"locations"`
+	}
+
+	SarifLocation struct {
+		PhysicalLocation *SarifPhysicalLocation `json:"physicalLocation,omitempty"`
+		LogicalLocations []SarifLogicalLocation `json:"logicalLocations"`
+	}
+
+	SarifPhysicalLocation struct {
+		ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+		Region           *SarifRegion          `json:"region,omitempty"`
+	}
+
+	SarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+	}
+
+	SarifArtifactLocation struct {
+		Uri string `json:"uri"`
+	}
+
+	SarifLogicalLocation struct {
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+	}
+)
+
+// sarifRules returns the distinct keywords of the violations as rules
+func sarifRules(vs []types.Violation) []SarifRule {
+	rules := A.Empty[SarifRule]()
+	known := make(map[string]bool)
+	for _, v := range vs {
+		if !known[v.Keyword] {
+			known[v.Keyword] = true
+			rules = append(rules, SarifRule{Id: v.Keyword})
+		}
+	}
+	return rules
+}
+
+// sarifRegion references the position of a violation, if known
+func sarifRegion(v types.Violation) *SarifRegion {
+	if v.Line <= 0 {
+		return nil
+	}
+	return &SarifRegion{
+		StartLine:   v.Line,
+		StartColumn: v.Column,
+	}
+}
+
+// sarifPhysicalLocation references the input file and the position of a violation, stdin has no physical location
+func sarifPhysicalLocation(input string, v types.Violation) *SarifPhysicalLocation {
+	if input == CF.StdInOutIdentifier {
+		return nil
+	}
+	return &SarifPhysicalLocation{
+		ArtifactLocation: SarifArtifactLocation{Uri: input},
+		Region:           sarifRegion(v),
+	}
+}
+
+// sarifResult converts a violation into a SARIF result
+func sarifResult(input string) func(types.Violation) SarifResult {
+	return func(v types.Violation) SarifResult {
+		return SarifResult{
+			RuleId:  v.Keyword,
+			Level:   "error",
+			Message: SarifMessage{Text: v.Message},
+			Locations: A.Of(SarifLocation{
+				PhysicalLocation: sarifPhysicalLocation(input, v),
+				LogicalLocations: A.Of(SarifLogicalLocation{FullyQualifiedName: v.Path}),
+			}),
+		}
+	}
+}
+
+// sarifReport renders a validation report as a SARIF log
+func sarifReport(input string) func(*ValidateResult) *SarifLog {
+	return func(res *ValidateResult) *SarifLog {
+		return &SarifLog{
+			Schema:  SarifSchema,
+			Version: SarifVersion,
+			Runs: A.Of(SarifRun{
+				Tool: SarifTool{
+					Driver: SarifDriver{
+						Name:           SarifToolName,
+						InformationUri: SarifToolUri,
+						Rules:          sarifRules(res.Violations),
+					},
+				},
+				Results: F.Pipe1(
+					res.Violations,
+					A.Map(sarifResult(input)),
+				),
+			}),
+		}
+	}
+}
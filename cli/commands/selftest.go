@@ -0,0 +1,104 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
+	"github.com/urfave/cli/v2"
+)
+
+// SelfTestCommand returns a command that checks the compatibility of the crypto and the openSSL backend
+func SelfTestCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "selftest",
+		Usage:       "check that the crypto and the openSSL backend are compatible",
+		Description: "Generates a key pair, a certificate and a random payload, encrypts with each backend and decrypts with the other, signs with one backend and verifies with the other and compares the fingerprints. Fails if the local openSSL build is incompatible with the golang crypto implementation",
+		Flags: []cli.Flag{
+			flagOutput,
+			flagInspectFormat,
+			flagPayloadSize,
+		},
+		Action: F.Flow2(
+			SelfTestAndWriteFromContext,
+			U.RunIOEither[[]byte],
+		),
+	}
+}
+
+// SelfTestFromContext runs the self-test across the crypto and the openSSL backend
+func SelfTestFromContext(ctx *cli.Context) IOE.IOEither[error, *Encrypt.SelfTestReport] {
+	return Encrypt.SelfTest(lookupPayloadSize(ctx))
+}
+
+// SelfTestAndWriteFromContext runs the self-test and writes the report. The operation fails after writing the report if
+// the backends are incompatible
+func SelfTestAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe1(
+		SelfTestFromContext(ctx),
+		IOE.Chain(func(report *Encrypt.SelfTestReport) IOE.IOEither[error, []byte] {
+			return F.Pipe3(
+				report,
+				getSelfTestSerializer(lookupFormat(ctx)),
+				IOE.FromEither[error, []byte],
+				IOE.Chain(F.Flow2(
+					getWriter(lookupOutput(ctx)),
+					IOE.ChainEitherK(func(data []byte) E.Either[error, []byte] {
+						return E.TryCatchError(data, report.ToError())
+					}),
+				)),
+			)
+		}),
+	)
+}
+
+// getSelfTestSerializer returns a serializer for a self-test report
+func getSelfTestSerializer(format string) func(*Encrypt.SelfTestReport) E.Either[error, []byte] {
+	if format == FormatText {
+		return F.Flow2(
+			textSelfTest,
+			E.Of[error, []byte],
+		)
+	}
+	return getSerializer[*Encrypt.SelfTestReport](format)
+}
+
+// textSelfTest renders a self-test report as text, one line per check
+func textSelfTest(res *Encrypt.SelfTestReport) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "default backend: %s\n", res.Backend)
+	openSSL := "unavailable"
+	if res.OpenSSL != "" {
+		openSSL = res.OpenSSL
+	}
+	fmt.Fprintf(&buf, "openssl: %s\n", openSSL)
+	fmt.Fprintf(&buf, "payload: %d bytes\n", res.PayloadSize)
+	for _, check := range res.Checks {
+		if check.Error == "" {
+			fmt.Fprintf(&buf, "ok: %s\n", check.Name)
+		} else {
+			fmt.Fprintf(&buf, "failed: %s: %s\n", check.Name, check.Error)
+		}
+	}
+	fmt.Fprintf(&buf, "passed %d of %d check(s)\n", len(res.Checks)-len(res.Failed()), len(res.Checks))
+	return buf.Bytes()
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	J "github.com/IBM/fp-go/json"
+	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// runSelfTest runs the selftest command
+func runSelfTest(args ...string) error {
+	cmd := SelfTestCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	return app.Run(append(A.From(os.Args[0], cmd.Name), args...))
+}
+
+func TestSelfTestCommand(t *testing.T) {
+	outName := "../../build/TestSelfTestCommand.json"
+
+	require.NoError(t, runSelfTest(fmt.Sprintf("--%s", flagFormat.Name), FormatJson, fmt.Sprintf("--%s", flagPayloadSize.Name), "512", fmt.Sprintf("--%s", flagOutput.Name), outName))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+	report, err := E.UnwrapError(J.Unmarshal[Encrypt.SelfTestReport](data))
+	require.NoError(t, err)
+
+	assert.Equal(t, 512, report.PayloadSize)
+	assert.NotEmpty(t, report.Checks)
+	assert.True(t, report.Passed())
+}
+
+func TestSelfTestCommandInvalidPayloadSize(t *testing.T) {
+	assert.Error(t, runSelfTest(fmt.Sprintf("--%s", flagPayloadSize.Name), "0"))
+}
+
+func TestTextSelfTest(t *testing.T) {
+	text := string(textSelfTest(&Encrypt.SelfTestReport{
+		Backend:     Encrypt.BackendCrypto,
+		PayloadSize: 16,
+		Checks: []Encrypt.SelfTestCheck{
+			{Name: "certificate fingerprint"},
+			{Name: "sign with openssl, verify with crypto", Error: "verification failure"},
+		},
+	}))
+
+	assert.Contains(t, text, "default backend: crypto\n")
+	assert.Contains(t, text, "openssl: unavailable\n")
+	assert.Contains(t, text, "ok: certificate fingerprint\n")
+	assert.Contains(t, text, "failed: sign with openssl, verify with crypto: verification failure\n")
+	assert.Contains(t, text, "passed 1 of 2 check(s)\n")
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// ServeCommand returns a command that exposes the encryption pipeline as a local HTTP service
+func ServeCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "serve",
+		Usage:       "run a local HTTP encryption service",
+		Description: "Serves the endpoints /validate, /encrypt, /encrypt-string, /certificates and /verify with JSON or YAML bodies, described at /openapi.yaml. The signing key is held by the server, requests select the encryption certificate via the hpcr-version query parameter. The service shuts down gracefully on an interrupt",
+		Flags: []cli.Flag{
+			flagListen,
+			flagMaxBodySize,
+			flagShutdownTimeout,
+			flagMode,
+			flagPrivKey,
+			flagPrivKeyFile,
+			flagCert,
+			flagCertFile,
+			flagHpcrVersion,
+			flagCacheDir,
+			flagVerifyCert,
+			flagCertBundle,
+			flagCRL,
+			flagMinKeySize,
+		},
+		Action: ServeFromContext,
+	}
+}
@@ -0,0 +1,583 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	A "github.com/IBM/fp-go/array"
+	B "github.com/IBM/fp-go/bytes"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IO "github.com/IBM/fp-go/io"
+	IOE "github.com/IBM/fp-go/ioeither"
+	J "github.com/IBM/fp-go/json"
+	O "github.com/IBM/fp-go/option"
+	S "github.com/IBM/fp-go/string"
+	T "github.com/IBM/fp-go/tuple"
+	"github.com/Masterminds/semver"
+	C "github.com/ibm-hyper-protect/contract-go/certificates"
+	CE "github.com/ibm-hyper-protect/contract-go/certificates/either"
+	CIOE "github.com/ibm-hyper-protect/contract-go/certificates/ioeither"
+	D "github.com/ibm-hyper-protect/contract-go/data"
+	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	SVIOE "github.com/ibm-hyper-protect/contract-go/service/ioeither"
+	"github.com/ibm-hyper-protect/contract-go/types"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// endpoints of the encryption service
+	PathValidate      = "/validate"
+	PathEncrypt       = "/encrypt"
+	PathEncryptString = "/encrypt-string"
+	PathCertificates  = "/certificates"
+	PathVerify        = "/verify"
+	PathOpenAPIYaml   = "/openapi.yaml"
+	PathOpenAPIJson   = "/openapi.json"
+
+	// query parameters of the encryption service
+	QueryHpcrVersion = "hpcr-version"
+	QuerySection     = "section"
+	QuerySpec        = "spec"
+)
+
+type (
+	// httpError is an error that is reported with a specific HTTP status
+	httpError struct {
+		status int
+		err    error
+	}
+
+	// ErrorResult is the response of a failed request
+	ErrorResult struct {
+		Error      string            `json:"error" yaml:"error"`
+		Violations []types.Violation `json:"violations,omitempty" yaml:"violations,omitempty"`
+	}
+
+	// EncryptStringResult is the response of the encryption of a single value
+	EncryptStringResult struct {
+		Token string `json:"token" yaml:"token"`
+	}
+
+	// CertificateInfo describes an encryption certificate known to the service
+	CertificateInfo struct {
+		Version     string `json:"version" yaml:"version"`
+		Checksum    string `json:"checksum" yaml:"checksum"`
+		Certificate string `json:"certificate,omitempty" yaml:"certificate,omitempty"` // PEM encoded certificate, only reported for a single certificate
+	}
+
+	// VerifyRequest is the request of a signature verification
+	VerifyRequest struct {
+		Contract   SC.EncryptedContract `json:"contract" yaml:"contract"`
+		SigningKey string               `json:"signingKey,omitempty" yaml:"signingKey,omitempty"` // public signing key, defaults to the key of the server
+	}
+)
+
+var (
+	// openAPIYaml is the OpenAPI description of the encryption service
+	//go:embed openapi.yaml
+	openAPIYaml []byte
+
+	// openAPIJson is the OpenAPI description of the encryption service in JSON
+	openAPIJson = F.Pipe2(
+		openAPIYaml,
+		Y.Parse[any],
+		E.Chain(J.Marshal[any]),
+	)
+
+	// errors of malformed requests, of missing resources and of contracts that cannot be processed
+	badRequest    = withStatus(http.StatusBadRequest)
+	notFound      = withStatus(http.StatusNotFound)
+	unprocessable = withStatus(http.StatusUnprocessableEntity)
+
+	// missingServerKey is the fallback if neither the request nor the server carries a signing key
+	missingServerKey = IOE.Left[[]byte](badRequest(fmt.Errorf("a public signing key is required, the server does not hold a signing key")))
+)
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func (e *httpError) Unwrap() error {
+	return e.err
+}
+
+// withStatus reports an error with the given HTTP status
+func withStatus(status int) func(error) error {
+	return func(err error) error {
+		return &httpError{status: status, err: err}
+	}
+}
+
+// statusOf returns the HTTP status that reports an error
+func statusOf(err error) int {
+	var tooLarge *http.MaxBytesError
+	var violations types.Violations
+	var herr *httpError
+	switch {
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.As(err, &violations):
+		return http.StatusUnprocessableEntity
+	case errors.As(err, &herr):
+		return herr.status
+	}
+	return http.StatusInternalServerError
+}
+
+// errorResultOf produces the response of a failed request, schema violations are reported individually
+func errorResultOf(err error) *ErrorResult {
+	var violations types.Violations
+	if errors.As(err, &violations) {
+		return &ErrorResult{Error: err.Error(), Violations: violations}
+	}
+	return &ErrorResult{Error: err.Error()}
+}
+
+// acceptedFormat returns the serialization format of the response, YAML if the client accepts it, JSON otherwise
+func acceptedFormat(r *http.Request) string {
+	if strings.Contains(r.Header.Get("Accept"), FormatYaml) {
+		return FormatYaml
+	}
+	return FormatJson
+}
+
+// writeResult serializes a response in the given format
+func writeResult[A any](w http.ResponseWriter, format string, status int, value A) {
+	data, err := E.UnwrapError(getSerializer[A](format)(value))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/"+format)
+	w.WriteHeader(status)
+	w.Write(data) // #nosec G104 -- the client has gone away
+}
+
+// respond executes the effect of a request and writes its result or its error
+func respond[A any](handler func(*http.Request) IOE.IOEither[error, A]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := acceptedFormat(r)
+		res, err := E.UnwrapError(handler(r)())
+		if err != nil {
+			writeResult(w, format, statusOf(err), errorResultOf(err))
+			return
+		}
+		writeResult(w, format, http.StatusOK, res)
+	}
+}
+
+// allowMethod rejects requests with a method other than the given one
+func allowMethod(method string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != method {
+				w.Header().Set("Allow", method)
+				writeResult(w, acceptedFormat(r), http.StatusMethodNotAllowed, &ErrorResult{Error: fmt.Sprintf("method [%s] is not allowed, use [%s]", r.Method, method)})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limitBody rejects request bodies that exceed the given size
+func limitBody(size int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, size)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// serveContent serves static content
+func serveContent(contentType string, content E.Either[error, []byte]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := E.UnwrapError(content)
+		if err != nil {
+			writeResult(w, acceptedFormat(r), http.StatusInternalServerError, errorResultOf(err))
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(data) // #nosec G104 -- the client has gone away
+	}
+}
+
+// readBody reads the body of a request
+func readBody(r *http.Request) IOE.IOEither[error, []byte] {
+	return IOE.TryCatchError(func() ([]byte, error) {
+		return io.ReadAll(r.Body)
+	})
+}
+
+// parseBody reads the JSON or YAML body of a request
+func parseBody[A any](r *http.Request) IOE.IOEither[error, A] {
+	return F.Pipe1(
+		readBody(r),
+		IOE.ChainEitherK(F.Flow2(
+			Y.Parse[A],
+			E.MapLeft[A](badRequest),
+		)),
+	)
+}
+
+// queryOpt returns a query parameter of a request, if it is not empty
+func queryOpt(r *http.Request, name string) O.Option[string] {
+	return F.Pipe1(
+		r.URL.Query().Get(name),
+		O.FromPredicate(S.IsNonEmpty),
+	)
+}
+
+// requestEncryptConfig returns the encryption config of a request, the request may select the HPCR version and the
+// section to encrypt
+func requestEncryptConfig(cfg *ServeConfig) func(*http.Request) E.Either[error, *EncryptAndSignConfig] {
+	return func(r *http.Request) E.Either[error, *EncryptAndSignConfig] {
+		version := queryOpt(r, QueryHpcrVersion)
+		section := queryOpt(r, QuerySection)
+		return F.Pipe2(
+			E.SequenceT2(
+				F.Pipe1(
+					version,
+					O.Fold(F.Constant(E.Of[error](version)), F.Flow2(
+						CE.ParseConstraint,
+						E.Map[error](F.Constant1[*semver.Constraints](version)),
+					)),
+				),
+				F.Pipe1(
+					section,
+					O.Fold(F.Constant(E.Of[error](section)), func(value string) E.Either[error, O.Option[string]] {
+						return E.TryCatchError(section, validateSection(nil, value))
+					}),
+				),
+			),
+			E.MapLeft[T.Tuple2[O.Option[string], O.Option[string]]](badRequest),
+			E.Map[error](T.Tupled2(func(version, section O.Option[string]) *EncryptAndSignConfig {
+				enc := cfg.Encrypt
+				enc.Version = O.Alt(F.Constant(cfg.Encrypt.Version))(version)
+				enc.Section = section
+				return &enc
+			})),
+		)
+	}
+}
+
+// encrypterError reports the failure to construct an encrypter for the HPCR version range of a request as a request
+// that cannot be processed, because no certificate matches the range or the selected certificate is invalid. Without a
+// range the failure is caused by the configuration of the server.
+func encrypterError(r *http.Request) func(error) error {
+	return func(err error) error {
+		return F.Pipe2(
+			queryOpt(r, QueryHpcrVersion),
+			O.Map(func(spec string) error {
+				return unprocessable(fmt.Errorf("no valid encryption certificate for the HPCR version range [%s]: %w", spec, err))
+			}),
+			O.GetOrElse(F.Constant(err)),
+		)
+	}
+}
+
+// validateHandler reports all schema violations of the contract in the request
+func validateHandler(r *http.Request) IOE.IOEither[error, *ValidateResult] {
+	return F.Pipe2(
+		readBody(r),
+		IOE.ChainEitherK(F.Flow2(
+			types.ContractViolationsFromYAML(""),
+			E.MapLeft[[]types.Violation](badRequest),
+		)),
+		IOE.Map[error](validateResultFromViolations),
+	)
+}
+
+// validContract validates a contract against the schema before decoding it, so all violations are reported even if
+// the contract cannot be decoded
+func validContract(raw types.AnyMap) E.Either[error, *types.Contract] {
+	return F.Pipe2(
+		types.ContractViolations(raw),
+		E.Chain(func(vs []types.Violation) E.Either[error, types.AnyMap] {
+			if A.IsEmpty(vs) {
+				return E.Of[error](raw)
+			}
+			return E.Left[types.AnyMap, error](types.Violations(vs))
+		}),
+		E.Chain(types.ValidateContract),
+	)
+}
+
+// encryptHandler validates, encrypts and signs the contract in the request
+func encryptHandler(cfg *ServeConfig) func(*http.Request) IOE.IOEither[error, SC.EncryptedContract] {
+	return func(r *http.Request) IOE.IOEither[error, SC.EncryptedContract] {
+		return F.Pipe1(
+			IOE.SequenceT2(
+				F.Pipe2(
+					requestEncryptConfig(cfg)(r),
+					IOE.FromEither[error, *EncryptAndSignConfig],
+					IOE.Chain(F.Flow2(
+						ContractEncrypterFromConfig,
+						IOE.MapLeft[SVIOE.ContractEncrypter](encrypterError(r)),
+					)),
+				),
+				F.Pipe1(
+					parseBody[types.AnyMap](r),
+					IOE.ChainEitherK(validContract),
+				),
+			),
+			IOE.Chain(T.Tupled2(func(enc SVIOE.ContractEncrypter, ctr *types.Contract) IOE.IOEither[error, SC.EncryptedContract] {
+				return enc(ctr)
+			})),
+		)
+	}
+}
+
+// encryptStringHandler encrypts the body of the request into a single `hyper-protect-basic` token
+func encryptStringHandler(cfg *ServeConfig) func(*http.Request) IOE.IOEither[error, *EncryptStringResult] {
+	return func(r *http.Request) IOE.IOEither[error, *EncryptStringResult] {
+		return F.Pipe2(
+			IOE.SequenceT2(
+				F.Pipe2(
+					requestEncryptConfig(cfg)(r),
+					IOE.FromEither[error, *EncryptAndSignConfig],
+					IOE.Chain(F.Flow2(
+						StringEncrypterFromConfig,
+						IOE.MapLeft[func([]byte) IOE.IOEither[error, string]](encrypterError(r)),
+					)),
+				),
+				readBody(r),
+			),
+			IOE.Chain(T.Tupled2(func(enc func([]byte) IOE.IOEither[error, string], data []byte) IOE.IOEither[error, string] {
+				return enc(data)
+			})),
+			IOE.Map[error](func(token string) *EncryptStringResult {
+				return &EncryptStringResult{Token: token}
+			}),
+		)
+	}
+}
+
+// certificatesFromConfig lists the cached and built-in certificates, cached certificates take precedence over
+// built-in certificates of the same version
+func certificatesFromConfig(cfg *EncryptAndSignConfig) IOE.IOEither[error, []C.VersionCert] {
+	return F.Pipe3(
+		getCacheDir(cfg.CacheDir),
+		IOE.Chain(CIOE.CertificatesFromCache),
+		IOE.Map[error](func(cached map[string]string) map[string]string {
+			return CE.MergeCertificates(D.Certificates, cached)
+		}),
+		IOE.ChainEitherK(CE.ListCertificates),
+	)
+}
+
+// certificateInfo describes a certificate, optionally including its content
+func certificateInfo(withContent bool) func(C.VersionCert) *CertificateInfo {
+	return func(cert C.VersionCert) *CertificateInfo {
+		info := &CertificateInfo{
+			Version:  cert.F1.String(),
+			Checksum: CE.Checksum(cert.F2),
+		}
+		if withContent {
+			info.Certificate = cert.F2
+		}
+		return info
+	}
+}
+
+// certificatesHandler lists the certificates that match the optional version range of the request
+func certificatesHandler(cfg *ServeConfig) func(*http.Request) IOE.IOEither[error, []*CertificateInfo] {
+	return func(r *http.Request) IOE.IOEither[error, []*CertificateInfo] {
+		filter := F.Pipe2(
+			queryOpt(r, QuerySpec),
+			O.Map(F.Flow2(
+				CE.ParseConstraint,
+				E.Map[error](C.FilterCertsBySpec),
+			)),
+			O.GetOrElse(F.Constant(E.Of[error](F.Identity[[]C.VersionCert]))),
+		)
+		return F.Pipe2(
+			IOE.SequenceT2(
+				IOE.FromEither(E.MapLeft[func([]C.VersionCert) []C.VersionCert](badRequest)(filter)),
+				certificatesFromConfig(&cfg.Encrypt),
+			),
+			IOE.Map[error](T.Tupled2(func(filter func([]C.VersionCert) []C.VersionCert, certs []C.VersionCert) []C.VersionCert {
+				return filter(certs)
+			})),
+			IOE.Map[error](A.Map(certificateInfo(false))),
+		)
+	}
+}
+
+// certificateHandler returns the certificate of the version in the path of the request
+func certificateHandler(cfg *ServeConfig) func(*http.Request) IOE.IOEither[error, *CertificateInfo] {
+	return func(r *http.Request) IOE.IOEither[error, *CertificateInfo] {
+		name := strings.TrimPrefix(r.URL.Path, PathCertificates+"/")
+		return F.Pipe2(
+			IOE.SequenceT2(
+				IOE.FromEither(E.MapLeft[C.Version](badRequest)(CE.ParseVersion(name))),
+				certificatesFromConfig(&cfg.Encrypt),
+			),
+			IOE.ChainEitherK(T.Tupled2(func(version C.Version, certs []C.VersionCert) E.Either[error, C.VersionCert] {
+				return F.Pipe2(
+					certs,
+					A.FindFirst(func(cert C.VersionCert) bool {
+						return version.Equal(cert.F1)
+					}),
+					E.FromOption[C.VersionCert](func() error {
+						return notFound(fmt.Errorf("no certificate for version [%s]", name))
+					}),
+				)
+			})),
+			IOE.Map[error](certificateInfo(true)),
+		)
+	}
+}
+
+// serverSigningKey returns the public key of the signing key held by the server
+func serverSigningKey(cfg *EncryptAndSignConfig) Encrypt.Key {
+	return F.Pipe1(
+		lookupKey(cfg.PrivKey.FromDirect, cfg.PrivKey.FromFile),
+		O.Fold(F.Constant(missingServerKey), func(privKey Encrypt.Key) Encrypt.Key {
+			return F.Pipe1(
+				IOE.SequenceT2(
+					F.Pipe2(
+						cfg.Mode,
+						getEncryption,
+						IOE.FromIO[error, Encrypt.Encryption],
+					),
+					privKey,
+				),
+				IOE.ChainEitherK(T.Tupled2(func(enc Encrypt.Encryption, privKey []byte) E.Either[error, []byte] {
+					return enc.GetPubKey()(privKey)
+				})),
+			)
+		}),
+	)
+}
+
+// verifyHandler verifies the signature of the encrypted contract in the request, an invalid signature is reported in
+// the result
+func verifyHandler(cfg *ServeConfig) func(*http.Request) IOE.IOEither[error, *VerifyResult] {
+	return func(r *http.Request) IOE.IOEither[error, *VerifyResult] {
+		return F.Pipe1(
+			parseBody[VerifyRequest](r),
+			IOE.Chain(func(req VerifyRequest) IOE.IOEither[error, *VerifyResult] {
+				return F.Pipe3(
+					lookupKey(O.FromPredicate(S.IsNonEmpty)(req.SigningKey), O.None[string]()),
+					O.GetOrElse(func() Encrypt.Key {
+						return serverSigningKey(&cfg.Encrypt)
+					}),
+					contractVerifier(cfg.Encrypt.Mode),
+					IOE.ChainIOK[error](func(verify SVIOE.ContractVerifier) IO.IO[*VerifyResult] {
+						return verifyResultFromContract(verify, req.Contract)
+					}),
+				)
+			}),
+		)
+	}
+}
+
+// ServeHandler returns the [http.Handler] of the encryption service
+func ServeHandler(cfg *ServeConfig) http.Handler {
+	post := allowMethod(http.MethodPost)
+	get := allowMethod(http.MethodGet)
+
+	mux := http.NewServeMux()
+	mux.Handle(PathValidate, post(respond(validateHandler)))
+	mux.Handle(PathEncrypt, post(respond(encryptHandler(cfg))))
+	mux.Handle(PathEncryptString, post(respond(encryptStringHandler(cfg))))
+	mux.Handle(PathCertificates, get(respond(certificatesHandler(cfg))))
+	mux.Handle(PathCertificates+"/", get(respond(certificateHandler(cfg))))
+	mux.Handle(PathVerify, post(respond(verifyHandler(cfg))))
+	mux.Handle(PathOpenAPIYaml, get(serveContent("application/yaml", E.Of[error](openAPIYaml))))
+	mux.Handle(PathOpenAPIJson, get(serveContent("application/json", openAPIJson)))
+
+	return limitBody(cfg.MaxBodySize)(mux)
+}
+
+// ServeHandlerFromConfig reads the signing key of the server once and returns the [http.Handler] of the encryption
+// service
+func ServeHandlerFromConfig(cfg *ServeConfig) IOE.IOEither[error, http.Handler] {
+	return F.Pipe1(
+		optionalKeyFromConfig(cfg.Encrypt.PrivKey),
+		IOE.Map[error](func(privKey O.Option[[]byte]) http.Handler {
+			resolved := *cfg
+			resolved.Encrypt.PrivKey = KeyConfig{
+				FromDirect: O.Map(B.ToString)(privKey),
+				FromFile:   O.None[string](),
+			}
+			return ServeHandler(&resolved)
+		}),
+	)
+}
+
+// runServer serves requests from the listener until the context is done and then shuts the server down, running
+// requests may complete within the timeout
+func runServer(ctx context.Context, srv *http.Server, ln net.Listener, timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(ln)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeFromContext runs the encryption service configured on the [cli.Context] until the process is interrupted
+func ServeFromContext(ctx *cli.Context) error {
+	cfg := ServeConfigFromContext(ctx)
+	handler, err := E.UnwrapError(ServeHandlerFromConfig(cfg)())
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.App.ErrWriter, "serving on http://%s\n", ln.Addr())
+
+	sigCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return runServer(sigCtx, srv, ln, cfg.ShutdownTimeout)
+}
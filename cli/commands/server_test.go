@@ -0,0 +1,317 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	E "github.com/IBM/fp-go/either"
+	O "github.com/IBM/fp-go/option"
+	D "github.com/ibm-hyper-protect/contract-go/data"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestServer starts the encryption service with a test encryption certificate and optionally a signing key
+func createTestServer(t *testing.T, name string, withKey bool) (*httptest.Server, string) {
+	encKeyName, encPubKeyName := createTestKeyPair(t, name+"Encryption")
+	signKeyName, _ := createTestKeyPair(t, name+"Signing")
+
+	cfg := &ServeConfig{
+		MaxBodySize: 1 << 20,
+		Encrypt: EncryptAndSignConfig{
+			Mode:     ModeCrypto,
+			PubCert:  KeyConfig{FromFile: O.Of(encPubKeyName)},
+			CacheDir: O.Of(t.TempDir()),
+		},
+	}
+	if withKey {
+		cfg.Encrypt.PrivKey = KeyConfig{FromFile: O.Of(signKeyName)}
+	}
+	handler, err := E.UnwrapError(ServeHandlerFromConfig(cfg)())
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return srv, encKeyName
+}
+
+// postTestRequest sends a request to the encryption service
+func postTestRequest(t *testing.T, url, accept string, body []byte) (*http.Response, []byte) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Accept", accept)
+	return doTestRequest(t, req)
+}
+
+// getTestRequest sends a GET request to the encryption service
+func getTestRequest(t *testing.T, url string) (*http.Response, []byte) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	return doTestRequest(t, req)
+}
+
+func doTestRequest(t *testing.T, req *http.Request) (*http.Response, []byte) {
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(resp.Body)
+	require.NoError(t, err)
+
+	return resp, buf.Bytes()
+}
+
+func TestServeEncryptAndVerify(t *testing.T) {
+	srv, encKeyName := createTestServer(t, "TestServeEncryptAndVerify", true)
+
+	contract, err := os.ReadFile("../samples/simple.yaml")
+	require.NoError(t, err)
+
+	resp, data := postTestRequest(t, srv.URL+PathEncrypt, "application/json", contract)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var encrypted SC.EncryptedContract
+	require.NoError(t, json.Unmarshal(data, &encrypted))
+	assert.Contains(t, encrypted, SC.KeyEnvWorkloadSignature)
+	assert.Contains(t, decryptToken(t, encKeyName, encrypted[SC.KeyWorkload]), "compose")
+
+	// the signature is verified with the signing key of the server
+	body, err := json.Marshal(&VerifyRequest{Contract: encrypted})
+	require.NoError(t, err)
+	resp, data = postTestRequest(t, srv.URL+PathVerify, "application/json", body)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+
+	var result VerifyResult
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.True(t, result.Valid)
+
+	// a tampered contract fails the verification
+	encrypted[SC.KeyWorkload] = encrypted[SC.KeyEnv]
+	body, err = json.Marshal(&VerifyRequest{Contract: encrypted})
+	require.NoError(t, err)
+	resp, data = postTestRequest(t, srv.URL+PathVerify, "application/json", body)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+
+	var invalid VerifyResult
+	require.NoError(t, json.Unmarshal(data, &invalid))
+	assert.False(t, invalid.Valid)
+	assert.NotEmpty(t, invalid.Error)
+	assert.Equal(t, encrypted[SC.KeyEnvWorkloadSignature], invalid.EnvWorkloadSignature)
+}
+
+func TestServeEncryptSection(t *testing.T) {
+	srv, _ := createTestServer(t, "TestServeEncryptSection", true)
+
+	contract, err := os.ReadFile("../samples/simple.yaml")
+	require.NoError(t, err)
+
+	resp, data := postTestRequest(t, srv.URL+PathEncrypt+"?"+QuerySection+"="+SC.KeyWorkload, "application/json", contract)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+
+	var encrypted SC.EncryptedContract
+	require.NoError(t, json.Unmarshal(data, &encrypted))
+	assert.Len(t, encrypted, 1)
+	assert.Contains(t, encrypted, SC.KeyWorkload)
+
+	resp, _ = postTestRequest(t, srv.URL+PathEncrypt+"?"+QuerySection+"=attestation", "application/json", contract)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestServeVerifyWithoutServerKey(t *testing.T) {
+	srv, _ := createTestServer(t, "TestServeVerifyWithoutServerKey", false)
+
+	resp, data := postTestRequest(t, srv.URL+PathVerify, "application/json", []byte(`{"contract": {"workload": "hyper-protect-basic.a.b"}}`))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(data), "signing key")
+}
+
+func TestServeValidate(t *testing.T) {
+	srv, _ := createTestServer(t, "TestServeValidate", false)
+
+	contract, err := os.ReadFile("../samples/invalid.yaml")
+	require.NoError(t, err)
+
+	resp, data := postTestRequest(t, srv.URL+PathValidate, "application/yaml", contract)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+	assert.Equal(t, "application/yaml", resp.Header.Get("Content-Type"))
+
+	result, err := E.UnwrapError(Y.Parse[ValidateResult](data))
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.NotEmpty(t, result.Violations)
+
+	// an invalid contract is not encrypted
+	resp, data = postTestRequest(t, srv.URL+PathEncrypt, "application/json", contract)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var errResult ErrorResult
+	require.NoError(t, json.Unmarshal(data, &errResult))
+	assert.Len(t, errResult.Violations, len(result.Violations))
+
+	// malformed bodies are rejected
+	resp, _ = postTestRequest(t, srv.URL+PathValidate, "application/json", []byte("workload: [a"))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestServeEncryptString(t *testing.T) {
+	srv, encKeyName := createTestServer(t, "TestServeEncryptString", false)
+
+	resp, data := postTestRequest(t, srv.URL+PathEncryptString, "application/yaml", []byte("my secret"))
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+
+	result, err := E.UnwrapError(Y.Parse[EncryptStringResult](data))
+	require.NoError(t, err)
+	assert.Equal(t, "my secret", decryptToken(t, encKeyName, result.Token))
+}
+
+func TestServeCertificates(t *testing.T) {
+	srv, _ := createTestServer(t, "TestServeCertificates", false)
+
+	resp, data := getTestRequest(t, srv.URL+PathCertificates+"?"+QuerySpec+"=~1.0.10")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+
+	var certs []CertificateInfo
+	require.NoError(t, json.Unmarshal(data, &certs))
+	require.NotEmpty(t, certs)
+	assert.Equal(t, "1.0.12", certs[0].Version)
+	assert.Empty(t, certs[0].Certificate)
+
+	resp, data = getTestRequest(t, srv.URL+PathCertificates+"/1.0.10")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+
+	var cert CertificateInfo
+	require.NoError(t, json.Unmarshal(data, &cert))
+	assert.Equal(t, D.Certificates["1.0.10"], cert.Certificate)
+
+	resp, _ = getTestRequest(t, srv.URL+PathCertificates+"/2.0.0")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = getTestRequest(t, srv.URL+PathCertificates+"/latest")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = getTestRequest(t, srv.URL+PathCertificates+"?"+QuerySpec+"=not-a-range")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestServeEncryptHpcrVersion(t *testing.T) {
+	srv, _ := createTestServer(t, "TestServeEncryptHpcrVersion", false)
+
+	resp, data := postTestRequest(t, srv.URL+PathEncryptString+"?"+QueryHpcrVersion+"=~1.0.10", "application/json", []byte("my secret"))
+	assert.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+
+	resp, _ = postTestRequest(t, srv.URL+PathEncryptString+"?"+QueryHpcrVersion+"=not-a-range", "application/json", []byte("my secret"))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestServeEncryptHpcrVersionNotFound(t *testing.T) {
+	// select the certificate from the catalog rather than from the configuration
+	cfg := &ServeConfig{
+		MaxBodySize: 1 << 20,
+		Encrypt: EncryptAndSignConfig{
+			Mode:     ModeCrypto,
+			CacheDir: O.Of(t.TempDir()),
+		},
+	}
+	handler, err := E.UnwrapError(ServeHandlerFromConfig(cfg)())
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	contract, err := os.ReadFile("../samples/simple.yaml")
+	require.NoError(t, err)
+
+	resp, data := postTestRequest(t, srv.URL+PathEncrypt+"?"+QueryHpcrVersion+"=>9.0", "application/json", contract)
+	require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode, string(data))
+	assertErrorContains(t, data, "[>9.0]")
+
+	resp, data = postTestRequest(t, srv.URL+PathEncryptString+"?"+QueryHpcrVersion+"=>9.0", "application/json", []byte("my secret"))
+	require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode, string(data))
+	assertErrorContains(t, data, "[>9.0]")
+}
+
+// assertErrorContains checks the message of an error response
+func assertErrorContains(t *testing.T, data []byte, msg string) {
+	var result ErrorResult
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.Contains(t, result.Error, msg)
+}
+
+func TestServeLimits(t *testing.T) {
+	srv, _ := createTestServer(t, "TestServeLimits", false)
+
+	resp, _ := postTestRequest(t, srv.URL+PathEncryptString, "application/json", bytes.Repeat([]byte("a"), 2<<20))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	resp, _ = getTestRequest(t, srv.URL+PathEncrypt)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, http.MethodPost, resp.Header.Get("Allow"))
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	srv, _ := createTestServer(t, "TestServeOpenAPI", false)
+
+	resp, data := getTestRequest(t, srv.URL+PathOpenAPIJson)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var spec map[string]any
+	require.NoError(t, json.Unmarshal(data, &spec))
+	assert.Contains(t, spec["paths"], PathEncrypt)
+
+	resp, data = getTestRequest(t, srv.URL+PathOpenAPIYaml)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, strings.HasPrefix(string(data), "openapi:"))
+}
+
+func TestRunServerShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- runServer(ctx, &http.Server{Handler: http.NotFoundHandler()}, ln, time.Second)
+	}()
+
+	resp, _ := getTestRequest(t, "http://"+ln.Addr().String()+PathCertificates)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not shut down")
+	}
+
+	_, err = net.Dial("tcp", ln.Addr().String())
+	assert.Error(t, err)
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IO "github.com/IBM/fp-go/io"
+	IOE "github.com/IBM/fp-go/ioeither"
+	O "github.com/IBM/fp-go/option"
+	RR "github.com/IBM/fp-go/record"
+	T "github.com/IBM/fp-go/tuple"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
+	CFIOE "github.com/ibm-hyper-protect/contract-go/file/ioeither"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	SVIOE "github.com/ibm-hyper-protect/contract-go/service/ioeither"
+	"github.com/ibm-hyper-protect/contract-go/types"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/urfave/cli/v2"
+)
+
+// SignCommand returns a command that signs the combination of a separately encrypted workload and env
+func SignCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sign",
+		Usage: "sign a separately encrypted workload and env",
+		Description: `Combines an encrypted workload with a plaintext or encrypted env and computes the envWorkloadSignature over the encrypted sections.
+A plaintext env is encrypted after the public signing key has been inserted, an encrypted env must have been created by 'encrypt --section env' with the same signing key, which must then be given explicitly.
+The workload provider never sees the env and the deployer never sees the plaintext workload.`,
+		Flags: []cli.Flag{
+			flagWorkload,
+			flagEnv,
+			flagOutput,
+			flagFormat,
+			flagMode,
+			flagPrivKey,
+			flagPrivKeyFile,
+			flagCert,
+			flagCertFile,
+			flagHpcrVersion,
+			flagCacheDir,
+			flagVerifyCert,
+			flagCertBundle,
+			flagCRL,
+			flagMinKeySize,
+		},
+		Action: F.Flow2(
+			SignAndWriteFromContext,
+			U.RunIOEither[[]byte],
+		),
+	}
+}
+
+// ContractSignerFromConfig constructs a function that signs a contract assembled from separately encrypted sections,
+// see [SVIOE.SignContract]
+func ContractSignerFromConfig(cfg *EncryptAndSignConfig) IOE.IOEither[error, func(types.AnyMap) IOE.IOEither[error, SC.EncryptedContract]] {
+	// encryption module
+	encryption := F.Pipe2(
+		cfg.Mode,
+		getEncryption,
+		IO.Memoize[Encrypt.Encryption],
+	)
+	// signing function
+	signer := F.Pipe2(
+		encryption,
+		IO.Map(Encrypt.Encryption.GetSignDigest),
+		IOE.FromIO[error, Encrypt.SignDigestFunc],
+	)
+	// public key extractor
+	pubkey := F.Pipe2(
+		encryption,
+		IO.Map(Encrypt.Encryption.GetPubKey),
+		IOE.FromIO[error, Encrypt.PubKeyFunc],
+	)
+
+	return F.Pipe3(
+		IOE.SequenceTuple3(T.MakeTuple3(basicEncrypterFromConfig(cfg)(encryption), signer, pubkey)),
+		IOE.Map[error](T.Tupled3(SVIOE.SignContract)),
+		IOE.Ap[func(types.AnyMap) IOE.IOEither[error, SC.EncryptedContract]](signingKeyFromConfig(cfg)(encryption)),
+		IOE.Map[error](requireSigningKey(cfg.PrivKey)),
+	)
+}
+
+// requireSigningKey refuses to sign a contract with an encrypted env without an explicit signing key. The encrypted env
+// already carries the public key that verifies the signature, so a signature with a transient key can never be verified
+func requireSigningKey(key KeyConfig) func(func(types.AnyMap) IOE.IOEither[error, SC.EncryptedContract]) func(types.AnyMap) IOE.IOEither[error, SC.EncryptedContract] {
+	explicit := O.IsSome(lookupKey(key.FromDirect, key.FromFile))
+	return func(sign func(types.AnyMap) IOE.IOEither[error, SC.EncryptedContract]) func(types.AnyMap) IOE.IOEither[error, SC.EncryptedContract] {
+		return func(ctr types.AnyMap) IOE.IOEither[error, SC.EncryptedContract] {
+			if !explicit && SC.IsToken(ctr[SC.KeyEnv]) {
+				return IOE.Left[SC.EncryptedContract](fmt.Errorf("the [%s] section is already encrypted, signing requires the signing key it was encrypted with, use [--%s] or [--%s]", SC.KeyEnv, flagPrivKey.Name, flagPrivKeyFile.Name))
+			}
+			return sign(ctr)
+		}
+	}
+}
+
+// sectionsFromInput reads the sections of a contract for signing from a file or stdin, a bare token is taken as the
+// given section
+func sectionsFromInput(key string) func(input string) IOE.IOEither[error, types.AnyMap] {
+	return F.Flow2(
+		CFIOE.ReadFromInput,
+		IOE.ChainEitherK(F.Flow2(
+			Y.Parse[any],
+			E.Chain(func(value any) E.Either[error, types.AnyMap] {
+				switch v := value.(type) {
+				case types.AnyMap:
+					return E.Of[error](v)
+				case string:
+					return E.Of[error](types.AnyMap{key: v})
+				}
+				return E.Left[types.AnyMap](fmt.Errorf("expected a contract or a token for the [%s] section, got [%T]", key, value))
+			}),
+		)),
+	)
+}
+
+// SignInputFromContext assembles the contract to sign from the workload and env inputs on the [cli.Context]. Only the
+// workload is taken from the workload input, all other sections are taken from the env input
+func SignInputFromContext(ctx *cli.Context) IOE.IOEither[error, types.AnyMap] {
+	return F.Pipe1(
+		IOE.SequenceT2(
+			sectionsFromInput(SC.KeyWorkload)(lookupWorkload(ctx)),
+			sectionsFromInput(SC.KeyEnv)(lookupEnv(ctx)),
+		),
+		IOE.ChainEitherK(T.Tupled2(func(workload, env types.AnyMap) E.Either[error, types.AnyMap] {
+			value, ok := workload[SC.KeyWorkload]
+			if !ok {
+				return E.Left[types.AnyMap](fmt.Errorf("the workload input is missing the [%s] section", SC.KeyWorkload))
+			}
+			if _, ok := env[SC.KeyEnv]; !ok {
+				return E.Left[types.AnyMap](fmt.Errorf("the env input is missing the [%s] section", SC.KeyEnv))
+			}
+			return E.Of[error](F.Pipe2(
+				env,
+				RR.DeleteAt[string, any](SC.KeyWorkload),
+				RR.UpsertAt(SC.KeyWorkload, value),
+			))
+		})),
+	)
+}
+
+// SignFromContext signs the combination of an encrypted workload and an env from information on the [cli.Context]
+func SignFromContext(ctx *cli.Context) IOE.IOEither[error, SC.EncryptedContract] {
+	return F.Pipe1(
+		IOE.SequenceT2(
+			ContractSignerFromConfig(EncryptAndSignConfigFromContext(ctx)),
+			SignInputFromContext(ctx),
+		),
+		IOE.Chain(T.Tupled2(func(sign func(types.AnyMap) IOE.IOEither[error, SC.EncryptedContract], ctr types.AnyMap) IOE.IOEither[error, SC.EncryptedContract] {
+			return sign(ctr)
+		})),
+	)
+}
+
+// SignAndWriteFromContext signs the combination of an encrypted workload and an env and writes the signed contract
+func SignAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe1(
+		SignFromContext(ctx),
+		IOE.Chain(writeFromContext[SC.EncryptedContract](ctx)),
+	)
+}
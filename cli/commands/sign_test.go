@@ -0,0 +1,137 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestSignCommand(t *testing.T) {
+
+	inName := "../samples/simple.yaml"
+	workloadName := "../../build/TestSignCommand.workload.yaml"
+	envName := "../../build/TestSignCommand.env.yaml"
+	signedName := "../../build/TestSignCommand.yaml"
+	outName := "../../build/TestSignCommand.verify.yaml"
+
+	// key pairs of the encryption certificate and of the deployer's signing key
+	_, encKeyName := createTestKeyPair(t, "TestSignCommandEncryption")
+	signKeyName, signPubKeyName := createTestKeyPair(t, "TestSignCommandSigning")
+
+	encCmd := EncryptAndSignCommand()
+	signCmd := SignCommand()
+	verifyCmd := VerifyCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.From(encCmd, signCmd, verifyCmd),
+	}
+
+	// the workload provider encrypts the workload
+	require.NoError(t, app.Run(A.From(os.Args[0], encCmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), workloadName, fmt.Sprintf("--%s", flagCertFile.Name), encKeyName, fmt.Sprintf("--%s", flagSection.Name), SC.KeyWorkload)))
+
+	data, err := os.ReadFile(workloadName)
+	require.NoError(t, err)
+	workload, err := E.UnwrapError(Y.Parse[SC.EncryptedContract](data))
+	require.NoError(t, err)
+	assert.Len(t, workload, 1)
+	assert.Contains(t, workload, SC.KeyWorkload)
+
+	// the deployer encrypts the env with the signing key
+	require.NoError(t, app.Run(A.From(os.Args[0], encCmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), envName, fmt.Sprintf("--%s", flagCertFile.Name), encKeyName, fmt.Sprintf("--%s", flagSection.Name), SC.KeyEnv, fmt.Sprintf("--%s", flagPrivKeyFile.Name), signKeyName)))
+
+	signArgs := A.From(os.Args[0], signCmd.Name, fmt.Sprintf("--%s", flagOutput.Name), signedName, fmt.Sprintf("--%s", flagCertFile.Name), encKeyName, fmt.Sprintf("--%s", flagPrivKeyFile.Name), signKeyName)
+	verifyArgs := A.From(os.Args[0], verifyCmd.Name, fmt.Sprintf("--%s", flagInput.Name), signedName, fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagSigningKeyFile.Name), signPubKeyName)
+
+	// sign with the encrypted env
+	require.NoError(t, app.Run(append(signArgs, fmt.Sprintf("--%s", flagWorkload.Name), workloadName, fmt.Sprintf("--%s", flagEnv.Name), envName)))
+	assert.NoError(t, app.Run(verifyArgs))
+
+	// sign with the plaintext env
+	require.NoError(t, app.Run(append(signArgs, fmt.Sprintf("--%s", flagWorkload.Name), workloadName, fmt.Sprintf("--%s", flagEnv.Name), inName)))
+	assert.NoError(t, app.Run(verifyArgs))
+
+	// the workload must be encrypted
+	assert.Error(t, app.Run(append(signArgs, fmt.Sprintf("--%s", flagWorkload.Name), inName, fmt.Sprintf("--%s", flagEnv.Name), inName)))
+}
+
+func TestSignCommandBareToken(t *testing.T) {
+
+	workloadName := "../../build/TestSignCommandBareToken.workload.txt"
+	signedName := "../../build/TestSignCommandBareToken.yaml"
+
+	_, encKeyName := createTestKeyPair(t, "TestSignCommandBareToken")
+
+	strCmd := EncryptStringCommand()
+	signCmd := SignCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.From(strCmd, signCmd),
+	}
+
+	// a bare token is taken as the workload
+	require.NoError(t, app.Run(A.From(os.Args[0], strCmd.Name, fmt.Sprintf("--%s", flagText.Name), "type: workload\ncompose:\n  archive: MA==\n", fmt.Sprintf("--%s", flagOutput.Name), workloadName, fmt.Sprintf("--%s", flagCertFile.Name), encKeyName)))
+	require.NoError(t, app.Run(A.From(os.Args[0], signCmd.Name, fmt.Sprintf("--%s", flagWorkload.Name), workloadName, fmt.Sprintf("--%s", flagEnv.Name), "../samples/simple.yaml", fmt.Sprintf("--%s", flagOutput.Name), signedName, fmt.Sprintf("--%s", flagCertFile.Name), encKeyName)))
+
+	data, err := os.ReadFile(signedName)
+	require.NoError(t, err)
+	signed, err := E.UnwrapError(Y.Parse[SC.EncryptedContract](data))
+	require.NoError(t, err)
+
+	assert.Contains(t, signed, SC.KeyWorkload)
+	assert.Contains(t, signed, SC.KeyEnv)
+	assert.Contains(t, signed, SC.KeyEnvWorkloadSignature)
+}
+
+func TestSignCommandEncryptedEnvRequiresKey(t *testing.T) {
+
+	inName := "../samples/simple.yaml"
+	workloadName := "../../build/TestSignCommandEncryptedEnvRequiresKey.workload.yaml"
+	envName := "../../build/TestSignCommandEncryptedEnvRequiresKey.env.yaml"
+	signedName := "../../build/TestSignCommandEncryptedEnvRequiresKey.yaml"
+	require.NoError(t, os.RemoveAll(signedName))
+
+	_, encKeyName := createTestKeyPair(t, "TestSignCommandEncryptedEnvRequiresKey")
+
+	encCmd := EncryptAndSignCommand()
+	signCmd := SignCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.From(encCmd, signCmd),
+	}
+
+	// both sections are encrypted without a signing key
+	require.NoError(t, app.Run(A.From(os.Args[0], encCmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), workloadName, fmt.Sprintf("--%s", flagCertFile.Name), encKeyName, fmt.Sprintf("--%s", flagSection.Name), SC.KeyWorkload)))
+	require.NoError(t, app.Run(A.From(os.Args[0], encCmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), envName, fmt.Sprintf("--%s", flagCertFile.Name), encKeyName, fmt.Sprintf("--%s", flagSection.Name), SC.KeyEnv)))
+
+	// a transient signing key would produce a signature that can never be verified
+	err := app.Run(A.From(os.Args[0], signCmd.Name, fmt.Sprintf("--%s", flagWorkload.Name), workloadName, fmt.Sprintf("--%s", flagEnv.Name), envName, fmt.Sprintf("--%s", flagOutput.Name), signedName, fmt.Sprintf("--%s", flagCertFile.Name), encKeyName))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already encrypted")
+	assert.NoFileExists(t, signedName)
+}
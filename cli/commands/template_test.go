@@ -0,0 +1,87 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	cmd := ValidateCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	args := A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), "../samples/template/contract.yaml", fmt.Sprintf("--%s", flagOutput.Name), "../../build/TestValidateTemplate.txt")
+
+	// variables from a file and from a .env file
+	assert.NoError(t, app.Run(append(args, fmt.Sprintf("--%s", flagVarFile.Name), "../samples/template/vars.yaml", fmt.Sprintf("--%s", flagDotEnv.Name), "../samples/template")))
+
+	// variables given directly take precedence and are checked by the schema
+	assert.Error(t, app.Run(append(args, fmt.Sprintf("--%s", flagVarFile.Name), "../samples/template/vars.yaml", fmt.Sprintf("--%s", flagDotEnv.Name), "../samples/template", fmt.Sprintf("--%s", flagVar.Name), "INGESTION_KEY=invalid")))
+}
+
+func TestValidateTemplatePosition(t *testing.T) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	cmd := ValidateCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	outName := "../../build/TestValidateTemplatePosition.txt"
+
+	args := A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), "../samples/template/contract.yaml", fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagReportFormat.Name), FormatText, fmt.Sprintf("--%s", flagVar.Name), "LOG_HOSTNAME=logs.example.com", fmt.Sprintf("--%s", flagVar.Name), "INGESTION_KEY=invalid")
+	assert.Error(t, app.Run(args))
+
+	// positions refer to the lines of the template, including the parameter declaration
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "../samples/template/contract.yaml:15:7: /env/logging/logDNA/ingestionKey")
+}
+
+func TestEncryptTemplateMissingVars(t *testing.T) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	cmd := EncryptAndSignCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	args := A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), "../samples/template/contract.yaml", fmt.Sprintf("--%s", flagOutput.Name), "../../build/TestEncryptTemplate.yaml")
+
+	// all missing values are reported together
+	err := app.Run(args)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[INGESTION_KEY, LOG_HOSTNAME]")
+
+	assert.NoError(t, app.Run(append(args, fmt.Sprintf("--%s", flagVar.Name), "LOG_HOSTNAME=logs.example.com", fmt.Sprintf("--%s", flagVar.Name), "INGESTION_KEY=0123456789abcdef0123456789abcdef")))
+}
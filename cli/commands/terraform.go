@@ -0,0 +1,182 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	I "github.com/IBM/fp-go/identity"
+	IOE "github.com/IBM/fp-go/ioeither"
+	J "github.com/IBM/fp-go/json"
+	O "github.com/IBM/fp-go/option"
+	RR "github.com/IBM/fp-go/record"
+	S "github.com/IBM/fp-go/string"
+	T "github.com/IBM/fp-go/tuple"
+	U "github.com/ibm-hyper-protect/contract-go/cli/utils"
+	Common "github.com/ibm-hyper-protect/contract-go/common"
+	CF "github.com/ibm-hyper-protect/contract-go/file"
+	CFIOE "github.com/ibm-hyper-protect/contract-go/file/ioeither"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	"github.com/ibm-hyper-protect/contract-go/types"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/urfave/cli/v2"
+)
+
+// TerraformExternalCommand returns a command that implements the protocol of the Terraform external data source
+func TerraformExternalCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "terraform-external",
+		Usage:       "encrypt a contract as a Terraform external data source",
+		Description: "Reads a JSON object with the string keys contract, cert, privkey and hpcr_version from stdin, encrypts and signs the contract and writes a JSON object with the rendered contract, its sha256 checksum and the certificate used to stdout. Keys of the query take precedence over the flags",
+		Flags: []cli.Flag{
+			flagInput,
+			flagOutput,
+			flagMode,
+			flagPrivKey,
+			flagPrivKeyFile,
+			flagCert,
+			flagCertFile,
+			flagHpcrVersion,
+			flagCacheDir,
+			flagVerifyCert,
+			flagCertBundle,
+			flagCRL,
+			flagMinKeySize,
+		},
+		Action: F.Flow2(
+			TerraformExternalAndWriteFromContext,
+			U.RunIOEither[[]byte],
+		),
+	}
+}
+
+// validateTerraformQuery checks that the query of the Terraform external data source carries a contract and no unknown
+// keys
+func validateTerraformQuery(query map[string]string) E.Either[error, map[string]string] {
+	for _, key := range RR.Keys(query) {
+		if err := validateOneOfMany(validTerraformKeys)(nil, key); err != nil {
+			return E.Left[map[string]string](fmt.Errorf("invalid key in the query: %w", err))
+		}
+	}
+	if S.IsEmpty(query[Common.KeyContract]) {
+		return E.Left[map[string]string](fmt.Errorf("the query is missing the [%s] key", Common.KeyContract))
+	}
+	return E.Of[error](query)
+}
+
+// TerraformQueryFromInput reads the query of the Terraform external data source, a JSON object with string values
+func TerraformQueryFromInput(input string) IOE.IOEither[error, map[string]string] {
+	return F.Pipe2(
+		input,
+		CFIOE.ReadFromInput,
+		IOE.ChainEitherK(F.Flow2(
+			J.Unmarshal[map[string]string],
+			E.Chain(validateTerraformQuery),
+		)),
+	)
+}
+
+// terraformConfig applies the query of the Terraform external data source to an encryption config, values of the
+// query take precedence over the flags. Terraform passes optional inputs as empty strings, so these count as absent
+func terraformConfig(cfg *EncryptAndSignConfig) func(query map[string]string) *EncryptAndSignConfig {
+	return func(query map[string]string) *EncryptAndSignConfig {
+		lookup := func(key string, def O.Option[string]) O.Option[string] {
+			return F.Pipe3(
+				query,
+				RR.Lookup[string](key),
+				O.Chain(O.FromPredicate(S.IsNonEmpty)),
+				O.Alt(F.Constant(def)),
+			)
+		}
+		resolved := *cfg
+		resolved.PubCert.FromDirect = lookup(Common.KeyCert, cfg.PubCert.FromDirect)
+		resolved.PrivKey.FromDirect = lookup(Common.KeyPrivKey, cfg.PrivKey.FromDirect)
+		resolved.Version = lookup(Common.KeyHpcrVersion, cfg.Version)
+		return &resolved
+	}
+}
+
+// terraformResult produces the result of the Terraform external data source from the rendered contract and the
+// certificate used for the encryption
+func terraformResult(cert []byte) func(rendered []byte) map[string]string {
+	return func(rendered []byte) map[string]string {
+		sum := sha256.Sum256(rendered)
+		return map[string]string{
+			Common.KeyRendered: string(rendered),
+			Common.KeySha256:   hex.EncodeToString(sum[:]),
+			Common.KeyCert:     string(cert),
+		}
+	}
+}
+
+// encryptWithCertificate encrypts and signs a contract with the given certificate
+func encryptWithCertificate(cfg *EncryptAndSignConfig) func(cert []byte, ctr *types.Contract) IOE.IOEither[error, SC.EncryptedContract] {
+	return func(cert []byte, ctr *types.Contract) IOE.IOEither[error, SC.EncryptedContract] {
+		// the certificate has already been selected and verified
+		resolved := *cfg
+		resolved.PubCert = KeyConfig{FromDirect: O.Of(string(cert))}
+		resolved.Version = O.None[string]()
+		resolved.VerifyCert = false
+
+		return F.Pipe1(
+			ContractEncrypterFromConfig(&resolved),
+			IOE.Chain(I.Ap[IOE.IOEither[error, SC.EncryptedContract]](ctr)),
+		)
+	}
+}
+
+// TerraformExternalFromContext answers the query of the Terraform external data source on the [cli.Context] with the
+// rendered encrypted contract, its checksum and the certificate used for the encryption
+func TerraformExternalFromContext(ctx *cli.Context) IOE.IOEither[error, map[string]string] {
+	return F.Pipe1(
+		TerraformQueryFromInput(lookupInput(ctx)),
+		IOE.Chain(func(query map[string]string) IOE.IOEither[error, map[string]string] {
+			cfg := terraformConfig(EncryptAndSignConfigFromContext(ctx))(query)
+			// the contract in the query is resolved relative to the working directory
+			contract := F.Pipe2(
+				IOE.Of[error](S.ToBytes(query[Common.KeyContract])),
+				inlinedContractFromSource(CF.StdInOutIdentifier),
+				IOE.ChainEitherK(types.ValidateContract),
+			)
+			cert := encryptionCertFromConfig(cfg)
+
+			return F.Pipe1(
+				IOE.SequenceT2(cert, contract),
+				IOE.Chain(T.Tupled2(func(cert []byte, ctr *types.Contract) IOE.IOEither[error, map[string]string] {
+					return F.Pipe2(
+						encryptWithCertificate(cfg)(cert, ctr),
+						IOE.ChainEitherK(Y.Stringify[SC.EncryptedContract]),
+						IOE.Map[error](terraformResult(cert)),
+					)
+				})),
+			)
+		}),
+	)
+}
+
+// TerraformExternalAndWriteFromContext answers the query of the Terraform external data source and writes the result
+// as a flat JSON object
+func TerraformExternalAndWriteFromContext(ctx *cli.Context) IOE.IOEither[error, []byte] {
+	return F.Pipe2(
+		TerraformExternalFromContext(ctx),
+		IOE.ChainEitherK(J.Marshal[map[string]string]),
+		IOE.Chain(getWriter(lookupOutput(ctx))),
+	)
+}
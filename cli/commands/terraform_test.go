@@ -0,0 +1,148 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	"github.com/ibm-hyper-protect/contract-go/common"
+	D "github.com/ibm-hyper-protect/contract-go/data"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// runTerraformExternal runs the terraform-external command with a query and returns the parsed result
+func runTerraformExternal(t *testing.T, name string, query string, extra ...string) (map[string]string, error) {
+	require.NoError(t, os.MkdirAll("../../build", os.ModePerm))
+
+	inName := fmt.Sprintf("../../build/%s.query.json", name)
+	outName := fmt.Sprintf("../../build/%s.json", name)
+	require.NoError(t, os.WriteFile(inName, []byte(query), 0600))
+
+	cmd := TerraformExternalCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	args := append(A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), outName), extra...)
+	if err := app.Run(args); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	var result map[string]string
+	require.NoError(t, json.Unmarshal(data, &result))
+	return result, nil
+}
+
+func TestTerraformExternalCommand(t *testing.T) {
+	encKeyName, encPubKeyName := createTestKeyPair(t, "TestTerraformExternalCommand")
+
+	contract, err := os.ReadFile("../samples/simple.yaml")
+	require.NoError(t, err)
+	cert, err := os.ReadFile(encPubKeyName)
+	require.NoError(t, err)
+
+	query, err := json.Marshal(map[string]string{
+		common.KeyContract: string(contract),
+		common.KeyCert:     string(cert),
+	})
+	require.NoError(t, err)
+
+	result, err := runTerraformExternal(t, "TestTerraformExternalCommand", string(query))
+	require.NoError(t, err)
+
+	assert.Len(t, result, 3)
+	assert.Equal(t, string(cert), result[common.KeyCert])
+
+	sum := sha256.Sum256([]byte(result[common.KeyRendered]))
+	assert.Equal(t, hex.EncodeToString(sum[:]), result[common.KeySha256])
+
+	rendered, err := E.UnwrapError(Y.Parse[SC.EncryptedContract]([]byte(result[common.KeyRendered])))
+	require.NoError(t, err)
+	assert.Contains(t, rendered, SC.KeyEnvWorkloadSignature)
+	assert.Contains(t, decryptToken(t, encKeyName, rendered[SC.KeyWorkload]), "compose")
+}
+
+func TestTerraformExternalCommandHpcrVersion(t *testing.T) {
+	contract, err := os.ReadFile("../samples/simple.yaml")
+	require.NoError(t, err)
+
+	query, err := json.Marshal(map[string]string{
+		common.KeyContract:    string(contract),
+		common.KeyHpcrVersion: "1.0.10",
+	})
+	require.NoError(t, err)
+
+	result, err := runTerraformExternal(t, "TestTerraformExternalCommandHpcrVersion", string(query), fmt.Sprintf("--%s", flagCacheDir.Name), t.TempDir())
+	require.NoError(t, err)
+
+	assert.Equal(t, D.Certificates["1.0.10"], result[common.KeyCert])
+}
+
+func TestTerraformExternalCommandEmptyValues(t *testing.T) {
+	encKeyName, encPubKeyName := createTestKeyPair(t, "TestTerraformExternalCommandEmptyValues")
+
+	contract, err := os.ReadFile("../samples/simple.yaml")
+	require.NoError(t, err)
+	cert, err := os.ReadFile(encPubKeyName)
+	require.NoError(t, err)
+
+	// optional inputs arrive as empty strings and fall back to the flags
+	query, err := json.Marshal(map[string]string{
+		common.KeyContract:    string(contract),
+		common.KeyPrivKey:     "",
+		common.KeyCert:        "",
+		common.KeyHpcrVersion: "",
+	})
+	require.NoError(t, err)
+
+	result, err := runTerraformExternal(t, "TestTerraformExternalCommandEmptyValues", string(query), fmt.Sprintf("--%s", flagCertFile.Name), encPubKeyName)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(cert), result[common.KeyCert])
+	rendered, err := E.UnwrapError(Y.Parse[SC.EncryptedContract]([]byte(result[common.KeyRendered])))
+	require.NoError(t, err)
+	assert.Contains(t, decryptToken(t, encKeyName, rendered[SC.KeyWorkload]), "compose")
+}
+
+func TestTerraformExternalCommandInvalidQuery(t *testing.T) {
+	// unknown keys
+	_, err := runTerraformExternal(t, "TestTerraformExternalCommandInvalidQuery", `{"contract": "env: {}", "certificate": "x"}`)
+	assert.Error(t, err)
+
+	// the protocol only allows string values
+	_, err = runTerraformExternal(t, "TestTerraformExternalCommandInvalidQuery", `{"contract": {"env": {}}}`)
+	assert.Error(t, err)
+
+	// the contract is required
+	_, err = runTerraformExternal(t, "TestTerraformExternalCommandInvalidQuery", `{}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	J "github.com/IBM/fp-go/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func runValidate(t *testing.T, inName, outName, format string) error {
+	cmd := ValidateCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.Of(cmd),
+	}
+
+	args := A.From(os.Args[0], cmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), outName, fmt.Sprintf("--%s", flagReportFormat.Name), format)
+	return app.Run(args)
+}
+
+func TestValidateCommand(t *testing.T) {
+	outName := "../../build/TestValidateCommand.txt"
+
+	require.NoError(t, runValidate(t, "../samples/simple.yaml", outName, FormatText))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "valid")
+}
+
+func TestValidateCommandText(t *testing.T) {
+	outName := "../../build/TestValidateCommandText.txt"
+
+	assert.Error(t, runValidate(t, "../samples/invalid.yaml", outName, FormatText))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "../samples/invalid.yaml:15:3: /env/type: [const]")
+	assert.Contains(t, string(data), "../samples/invalid.yaml:20:5: /workload/compose/archive: [type]")
+}
+
+func TestValidateCommandNested(t *testing.T) {
+	outName := "../../build/TestValidateCommandNested.txt"
+
+	assert.Error(t, runValidate(t, "../samples/invalid-nested.yaml", outName, FormatText))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+	// positions inside of the `workload: |` section refer to the outer file
+	assert.Contains(t, string(data), "../samples/invalid-nested.yaml:20:5: /workload/compose/archive: [type]")
+}
+
+func TestValidateCommandJson(t *testing.T) {
+	outName := "../../build/TestValidateCommandJson.json"
+
+	assert.Error(t, runValidate(t, "../samples/invalid.yaml", outName, FormatJson))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	res, err := E.UnwrapError(J.Unmarshal[ValidateResult](data))
+	require.NoError(t, err)
+
+	assert.False(t, res.Valid)
+	assert.Len(t, res.Violations, 2)
+}
+
+func TestValidateCommandSarif(t *testing.T) {
+	inName := "../samples/invalid.yaml"
+	outName := "../../build/TestValidateCommandSarif.json"
+
+	assert.Error(t, runValidate(t, inName, outName, FormatSarif))
+
+	data, err := os.ReadFile(outName)
+	require.NoError(t, err)
+
+	log, err := E.UnwrapError(J.Unmarshal[SarifLog](data))
+	require.NoError(t, err)
+
+	assert.Equal(t, SarifVersion, log.Version)
+	require.Len(t, log.Runs, 1)
+	require.Len(t, log.Runs[0].Results, 2)
+
+	res := log.Runs[0].Results[0]
+	assert.Equal(t, "const", res.RuleId)
+	assert.Equal(t, inName, res.Locations[0].PhysicalLocation.ArtifactLocation.Uri)
+	assert.Equal(t, "/env/type", res.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assert.Equal(t, &SarifRegion{StartLine: 15, StartColumn: 3}, res.Locations[0].PhysicalLocation.Region)
+}
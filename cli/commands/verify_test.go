@@ -0,0 +1,78 @@
+// Copyright (c) 2023 IBM Corp.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestVerifyCommand(t *testing.T) {
+
+	inName := "../samples/simple.yaml"
+	encName := "../../build/TestVerifyCommand.encrypted.yaml"
+	plainName := "../../build/TestVerifyCommand.decrypted.yaml"
+	outName := "../../build/TestVerifyCommand.yaml"
+
+	// the key pair is used for encryption as well as for signing
+	privKeyName, pubKeyName := createTestKeyPair(t, "TestVerifyCommand")
+	_, otherKeyName := createTestKeyPair(t, "TestVerifyCommandOther")
+
+	encCmd := EncryptAndSignCommand()
+	decCmd := DecryptCommand()
+	verifyCmd := VerifyCommand()
+
+	app := &cli.App{
+		Name:     "contract-cli",
+		Commands: A.From(encCmd, decCmd, verifyCmd),
+	}
+
+	encArgs := A.From(os.Args[0], encCmd.Name, fmt.Sprintf("--%s", flagInput.Name), inName, fmt.Sprintf("--%s", flagOutput.Name), encName, fmt.Sprintf("--%s", flagCertFile.Name), pubKeyName, fmt.Sprintf("--%s", flagPrivKeyFile.Name), privKeyName)
+	require.NoError(t, app.Run(encArgs))
+
+	decArgs := A.From(os.Args[0], decCmd.Name, fmt.Sprintf("--%s", flagInput.Name), encName, fmt.Sprintf("--%s", flagOutput.Name), plainName, fmt.Sprintf("--%s", flagDecryptionKeyFile.Name), privKeyName)
+	require.NoError(t, app.Run(decArgs))
+
+	verifyArgs := A.From(os.Args[0], verifyCmd.Name, fmt.Sprintf("--%s", flagInput.Name), encName, fmt.Sprintf("--%s", flagOutput.Name), outName)
+
+	// key from the signing key file
+	assert.NoError(t, app.Run(append(verifyArgs, fmt.Sprintf("--%s", flagSigningKeyFile.Name), pubKeyName)))
+	// key from the plaintext contract
+	assert.NoError(t, app.Run(append(verifyArgs, fmt.Sprintf("--%s", flagPlaintext.Name), plainName)))
+	report, err := os.ReadFile(outName)
+	require.NoError(t, err)
+	valid, err := E.UnwrapError(Y.Parse[VerifyResult](report))
+	require.NoError(t, err)
+	assert.True(t, valid.Valid)
+	// wrong key, the report is written nonetheless
+	assert.Error(t, app.Run(append(verifyArgs, fmt.Sprintf("--%s", flagSigningKeyFile.Name), otherKeyName)))
+	report, err = os.ReadFile(outName)
+	require.NoError(t, err)
+	invalid, err := E.UnwrapError(Y.Parse[VerifyResult](report))
+	require.NoError(t, err)
+	assert.False(t, invalid.Valid)
+	assert.NotEmpty(t, invalid.Error)
+	// no key at all
+	assert.Error(t, app.Run(verifyArgs))
+}
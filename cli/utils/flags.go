@@ -16,6 +16,8 @@
 package utils
 
 import (
+	"time"
+
 	F "github.com/IBM/fp-go/function"
 	O "github.com/IBM/fp-go/option"
 	S "github.com/IBM/fp-go/string"
@@ -69,3 +71,29 @@ func LookupStringFlag(name string) func(ctx *cli.Context) string {
 func LookupStringSliceFlag(name string) func(ctx *cli.Context) []string {
 	return F.Bind2nd((*cli.Context).StringSlice, name)
 }
+
+// LookupBoolFlag returns a bool flag from the [cli.Context]
+func LookupBoolFlag(name string) func(ctx *cli.Context) bool {
+	return F.Bind2nd((*cli.Context).Bool, name)
+}
+
+// LookupDurationFlag returns a duration flag from the [cli.Context]
+func LookupDurationFlag(name string) func(ctx *cli.Context) time.Duration {
+	return F.Bind2nd((*cli.Context).Duration, name)
+}
+
+// LookupIntFlag returns an int flag from the [cli.Context]
+func LookupIntFlag(name string) func(ctx *cli.Context) int {
+	return F.Bind2nd((*cli.Context).Int, name)
+}
+
+// LookupTimestampFlagOpt returns a timestamp flag from the [cli.Context] as an [O.Option[time.Time]]
+func LookupTimestampFlagOpt(name string) func(ctx *cli.Context) O.Option[time.Time] {
+	return func(ctx *cli.Context) O.Option[time.Time] {
+		return F.Pipe2(
+			ctx.Timestamp(name),
+			O.FromNillable[time.Time],
+			O.Map(F.Deref[time.Time]),
+		)
+	}
+}
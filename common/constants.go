@@ -24,5 +24,8 @@ const (
 	KeyContract = "contract"
 	KeyJSON     = "json"
 
+	// KeyHpcrVersion is the semantic version range of the HPCR image in the query of a Terraform external data source
+	KeyHpcrVersion = "hpcr_version"
+
 	PrefixBasicEncoding = "hyper-protect-basic"
 )
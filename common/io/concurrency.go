@@ -0,0 +1,35 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io
+
+import (
+	IO "github.com/IBM/fp-go/io"
+)
+
+// LimitConcurrency returns an operator that restricts the number of operations that run at the same time, operations
+// beyond the limit wait until a running one completes. A limit below one is treated as one.
+func LimitConcurrency[A any](n int) func(IO.IO[A]) IO.IO[A] {
+	if n < 1 {
+		n = 1
+	}
+	sem := make(chan struct{}, n)
+	return func(op IO.IO[A]) IO.IO[A] {
+		return func() A {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			return op()
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	A "github.com/IBM/fp-go/array"
+	IO "github.com/IBM/fp-go/io"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitConcurrency(t *testing.T) {
+	var running, peak int32
+
+	limit := LimitConcurrency[int](2)
+	op := func(i int) IO.IO[int] {
+		return limit(func() int {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				observed := atomic.LoadInt32(&peak)
+				if current <= observed || atomic.CompareAndSwapInt32(&peak, observed, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return i
+		})
+	}
+
+	res := IO.TraverseArray(op)(A.From(1, 2, 3, 4, 5, 6))()
+
+	assert.Equal(t, A.From(1, 2, 3, 4, 5, 6), res)
+	assert.LessOrEqual(t, peak, int32(2))
+}
@@ -15,11 +15,44 @@
 package Data
 
 import (
-	_ "embed"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
 )
 
+// certificateName matches the filename of an HPCR encryption certificate and captures major, minor and patch version
+var certificateName = regexp.MustCompile(`^ibm-hyper-protect-container-runtime-(\d+)-(\d+)-s390x-(\d+)-encrypt\.crt$`)
+
+//go:embed ibm-hyper-protect-container-runtime-*-encrypt.crt
+var certificateFS embed.FS
+
+// Certificates is the catalog of all built-in HPCR encryption certificates, indexed by the HPCR version, e.g. `1.0.10`
+var Certificates = certificatesFromFS(certificateFS)
+
 //go:embed ibm-hyper-protect-container-runtime-1-0-s390x-12-encrypt.crt
 var DefaultCertificate string
 
 //go:embed hpse-contract-schema-1.0.51.json
 var ContractSchema string
+
+// certificatesFromFS indexes the certificates of a filesystem by the version encoded in their filename
+func certificatesFromFS(fsys fs.FS) map[string]string {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		panic(err)
+	}
+	certs := make(map[string]string)
+	for _, entry := range entries {
+		match := certificateName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		certs[fmt.Sprintf("%s.%s.%s", match[1], match[2], match[3])] = string(data)
+	}
+	return certs
+}
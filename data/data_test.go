@@ -0,0 +1,28 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package Data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertificates(t *testing.T) {
+	assert.Len(t, Certificates, 9)
+	assert.Contains(t, Certificates, "1.0.2")
+	assert.Contains(t, Certificates, "1.0.10")
+	assert.Equal(t, DefaultCertificate, Certificates["1.0.12"])
+}
@@ -0,0 +1,258 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	R "github.com/IBM/fp-go/record"
+	S "github.com/IBM/fp-go/string"
+	AE "github.com/ibm-hyper-protect/contract-go/archive/either"
+	DF "github.com/ibm-hyper-protect/contract-go/diff"
+	ENV "github.com/ibm-hyper-protect/contract-go/environment"
+	"github.com/ibm-hyper-protect/contract-go/types"
+)
+
+type (
+	// leaf is a single comparable value of a contract
+	leaf struct {
+		section string
+		value   string
+		secret  bool // the value is masked in reports
+		file    bool // the value is the content of a file in an archive
+		opaque  bool // the value is an archive that cannot be unpacked
+	}
+
+	// leaves maps the JSON pointer of a value to the value
+	leaves map[string]leaf
+)
+
+// pointerEscaper escapes a key according to RFC 6901
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// pointer appends a key to a JSON pointer
+func pointer(parent, key string) string {
+	return fmt.Sprintf("%s/%s", parent, pointerEscaper.Replace(key))
+}
+
+func (l leaves) add(section, path, value string, secret bool) {
+	l[path] = leaf{section: section, value: value, secret: secret}
+}
+
+func (l leaves) addRef(section, path string, value *string, secret bool) {
+	if value != nil {
+		l.add(section, path, *value, secret)
+	}
+}
+
+func (l leaves) addInt(section, path string, value *int) {
+	if value != nil {
+		l.add(section, path, strconv.Itoa(*value), false)
+	}
+}
+
+// addEnv records environment variables, their values may carry secrets
+func (l leaves) addEnv(path string, env ENV.Env) {
+	for key, value := range env {
+		l.add(DF.SectionEnv, pointer(path, key), value, true)
+	}
+}
+
+func (l leaves) addAuths(path string, auths types.Auths) {
+	for registry, cred := range auths {
+		base := pointer(path, registry)
+		l.add(DF.SectionAuths, pointer(base, "username"), cred.Username, false)
+		l.add(DF.SectionAuths, pointer(base, "password"), cred.Password, true)
+	}
+}
+
+func (l leaves) addWorkloadVolumes(path string, volumes types.WorkloadVolumes) {
+	for name, volume := range volumes {
+		base := pointer(path, name)
+		l.add(DF.SectionVolumes, pointer(base, "seed"), volume.Seed, true)
+		l.addRef(DF.SectionVolumes, pointer(base, "filesystem"), volume.Filesystem, false)
+		l.addRef(DF.SectionVolumes, pointer(base, "mount"), volume.Mount, false)
+	}
+}
+
+func (l leaves) addEnvVolumes(path string, volumes types.EnvVolumes) {
+	for name, volume := range volumes {
+		l.add(DF.SectionVolumes, pointer(pointer(path, name), "seed"), volume.Seed, true)
+	}
+}
+
+func (l leaves) addLogging(path string, logging *types.Logging) {
+	if logging == nil {
+		return
+	}
+	if logDNA := logging.LogDNA; logDNA != nil {
+		base := pointer(path, "logDNA")
+		l.add(DF.SectionLogging, pointer(base, "hostname"), logDNA.Hostname, false)
+		l.add(DF.SectionLogging, pointer(base, "ingestionKey"), logDNA.IngestionKey, true)
+		l.addInt(DF.SectionLogging, pointer(base, "port"), logDNA.Port)
+		if len(logDNA.Tags) > 0 {
+			l.add(DF.SectionLogging, pointer(base, "tags"), strings.Join(logDNA.Tags, ", "), false)
+		}
+	}
+	if sysLog := logging.SysLog; sysLog != nil {
+		base := pointer(path, "syslog")
+		l.add(DF.SectionLogging, pointer(base, "server"), sysLog.Server, false)
+		l.add(DF.SectionLogging, pointer(base, "hostname"), sysLog.Hostname, false)
+		l.addInt(DF.SectionLogging, pointer(base, "port"), sysLog.Port)
+		l.addRef(DF.SectionLogging, pointer(base, "cert"), sysLog.Cert, false)
+		l.addRef(DF.SectionLogging, pointer(base, "key"), sysLog.Key, true)
+	}
+}
+
+func (l leaves) addImages(path string, images *types.Images) {
+	if images == nil {
+		return
+	}
+	for image, dct := range images.DockerContentTrust {
+		if dct != nil {
+			base := pointer(pointer(path, "dct"), image)
+			l.add(DF.SectionImages, pointer(base, "notary"), dct.Notary, false)
+			l.add(DF.SectionImages, pointer(base, "publicKey"), dct.PublicKey, false)
+		}
+	}
+	for image, rhs := range images.RedHatSigning {
+		if rhs != nil {
+			l.add(DF.SectionImages, pointer(pointer(pointer(path, "rhs"), image), "publicKey"), rhs.PublicKey, false)
+		}
+	}
+}
+
+// addArchive records the files of an archive, archives that cannot be unpacked are compared as a whole
+func (l leaves) addArchive(path, archive string) {
+	files, err := E.UnwrapError(AE.Base64TgzToFileList(archive))
+	if err != nil {
+		l[path] = leaf{section: DF.SectionArchive, value: archive, file: true, opaque: true}
+		return
+	}
+	for name, data := range files {
+		l[pointer(path, name)] = leaf{section: DF.SectionArchive, value: string(data), file: true}
+	}
+}
+
+func (l leaves) addWorkload(workload *types.Workload) {
+	if workload == nil {
+		return
+	}
+	const path = "/workload"
+	l.add(DF.SectionContract, pointer(path, "type"), workload.Type, false)
+	l.addEnv(pointer(path, "env"), workload.Env)
+	l.addAuths(pointer(path, "auths"), workload.Auths)
+	l.addWorkloadVolumes(pointer(path, "volumes"), workload.Volumes)
+	l.addImages(pointer(path, "images"), workload.Images)
+	if workload.Compose != nil {
+		l.addArchive(pointer(pointer(path, "compose"), "archive"), workload.Compose.Archive)
+	}
+	if workload.Play != nil {
+		l.addArchive(pointer(pointer(path, "play"), "archive"), workload.Play.Archive)
+	}
+}
+
+func (l leaves) addEnvSection(env *types.Env) {
+	if env == nil {
+		return
+	}
+	const path = "/env"
+	l.add(DF.SectionContract, pointer(path, "type"), env.Type, false)
+	l.addEnv(pointer(path, "env"), env.Env)
+	l.addEnvVolumes(pointer(path, "volumes"), env.Volumes)
+	l.addLogging(pointer(path, "logging"), env.Logging)
+	l.addRef(DF.SectionContract, pointer(path, "signingKey"), env.SigningKey, false)
+}
+
+// contractLeaves flattens a contract into its comparable values
+func contractLeaves(contract *types.Contract) leaves {
+	l := make(leaves)
+	if contract == nil {
+		return l
+	}
+	l.addWorkload(contract.Workload)
+	l.addEnvSection(contract.Env)
+	l.addRef(DF.SectionContract, "/attestationPublicKey", contract.AttestationPublicKey, false)
+	l.addRef(DF.SectionContract, "/envWorkloadSignature", contract.EnvWorkloadSignature, false)
+	return l
+}
+
+// displayValue returns the value as shown in a report, secrets are masked and files are represented by their lines
+func displayValue(mask bool, value leaf) string {
+	switch {
+	case value.file:
+		return ""
+	case mask && value.secret:
+		return DF.Mask
+	default:
+		return value.value
+	}
+}
+
+// compareLeaf computes the change of a single value, if any
+func compareLeaf(mask bool, path string, old, cur leaves) (DF.Change, bool) {
+	left, inOld := old[path]
+	right, inCur := cur[path]
+	change := DF.Change{Path: path}
+	switch {
+	case inOld && !inCur:
+		change.Kind, change.Section = DF.KindRemoved, left.section
+	case !inOld && inCur:
+		change.Kind, change.Section = DF.KindAdded, right.section
+	case left.value != right.value:
+		change.Kind, change.Section = DF.KindChanged, right.section
+	default:
+		return change, false
+	}
+	if (left.file || right.file) && !left.opaque && !right.opaque {
+		change.Lines = diffLines([]byte(left.value), []byte(right.value))
+	}
+	if inOld {
+		change.Old = displayValue(mask, left)
+	}
+	if inCur {
+		change.New = displayValue(mask, right)
+	}
+	change.Masked = mask && (left.secret || right.secret)
+	return change, true
+}
+
+// DiffContracts computes the semantic difference between two contracts. Values are compared per environment variable,
+// registry credential, volume, logging endpoint and image trust entry. The files of `compose` and `play` archives are
+// unpacked and compared individually.
+//
+// - mask replaces secret values such as passwords, volume seeds and environment variables by [DF.Mask]
+func DiffContracts(mask bool) func(old, cur *types.Contract) *DF.Report {
+	return func(old, cur *types.Contract) *DF.Report {
+		oldLeaves, curLeaves := contractLeaves(old), contractLeaves(cur)
+		paths := F.Pipe2(
+			R.UnionLastMonoid[string, leaf]().Concat(oldLeaves, curLeaves),
+			R.Keys[string, leaf],
+			A.Sort(S.Ord),
+		)
+		changes := A.Empty[DF.Change]()
+		for _, path := range paths {
+			if change, ok := compareLeaf(mask, path, oldLeaves, curLeaves); ok {
+				changes = append(changes, change)
+			}
+		}
+		return &DF.Report{Changes: changes}
+	}
+}
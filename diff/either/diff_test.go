@@ -0,0 +1,139 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	AIOE "github.com/ibm-hyper-protect/contract-go/archive/ioeither"
+	DF "github.com/ibm-hyper-protect/contract-go/diff"
+	"github.com/ibm-hyper-protect/contract-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findChange(report *DF.Report, path string) DF.Change {
+	for _, change := range report.Changes {
+		if change.Path == path {
+			return change
+		}
+	}
+	return DF.Change{}
+}
+
+func TestDiffContracts(t *testing.T) {
+	old := &types.Contract{
+		Workload: &types.Workload{
+			Type: types.TypeWorkload,
+			Auths: types.Auths{
+				"docker.io": {Username: "user", Password: "old"},
+			},
+			Env: map[string]string{"KEEP": "same", "GONE": "value"},
+		},
+		Env: &types.Env{
+			Type: types.TypeEnv,
+			Logging: &types.Logging{
+				LogDNA: &types.LogDNA{Hostname: "old.example.com", IngestionKey: "key"},
+			},
+		},
+	}
+	cur := &types.Contract{
+		Workload: &types.Workload{
+			Type: types.TypeWorkload,
+			Auths: types.Auths{
+				"docker.io": {Username: "user", Password: "new"},
+			},
+			Env: map[string]string{"KEEP": "same", "NEW": "value"},
+			Images: &types.Images{
+				RedHatSigning: types.RedHatSignings{"us.icr.io/app": {PublicKey: "key"}},
+			},
+		},
+		Env: &types.Env{
+			Type: types.TypeEnv,
+			Logging: &types.Logging{
+				LogDNA: &types.LogDNA{Hostname: "new.example.com", IngestionKey: "key"},
+			},
+		},
+	}
+
+	report := DiffContracts(true)(old, cur)
+
+	assert.Equal(t, A.From(
+		"/env/logging/logDNA/hostname",
+		"/workload/auths/docker.io/password",
+		"/workload/env/GONE",
+		"/workload/env/NEW",
+		"/workload/images/rhs/us.icr.io~1app/publicKey",
+	), A.Map(func(c DF.Change) string { return c.Path })(report.Changes))
+
+	password := findChange(report, "/workload/auths/docker.io/password")
+	assert.Equal(t, DF.KindChanged, password.Kind)
+	assert.Equal(t, DF.SectionAuths, password.Section)
+	assert.Equal(t, DF.Mask, password.Old)
+	assert.Equal(t, DF.Mask, password.New)
+	assert.True(t, password.Masked)
+
+	hostname := findChange(report, "/env/logging/logDNA/hostname")
+	assert.Equal(t, "old.example.com", hostname.Old)
+	assert.Equal(t, "new.example.com", hostname.New)
+	assert.False(t, hostname.Masked)
+
+	assert.Equal(t, DF.KindRemoved, findChange(report, "/workload/env/GONE").Kind)
+	assert.Equal(t, DF.KindAdded, findChange(report, "/workload/env/NEW").Kind)
+	assert.Equal(t, DF.SectionImages, findChange(report, "/workload/images/rhs/us.icr.io~1app/publicKey").Section)
+
+	// secrets are revealed on request
+	revealed := findChange(DiffContracts(false)(old, cur), "/workload/auths/docker.io/password")
+	assert.Equal(t, "old", revealed.Old)
+	assert.Equal(t, "new", revealed.New)
+}
+
+func TestDiffContractsArchive(t *testing.T) {
+	archive, err := E.UnwrapError(AIOE.Base64TarFolder("../../samples/hello-world")())
+	require.NoError(t, err)
+
+	old := &types.Contract{
+		Workload: &types.Workload{Type: types.TypeWorkload},
+	}
+	cur := &types.Contract{
+		Workload: &types.Workload{Type: types.TypeWorkload, Compose: &types.Compose{Archive: archive}},
+	}
+
+	report := DiffContracts(true)(old, cur)
+	require.Len(t, report.Changes, 1)
+
+	change := report.Changes[0]
+	assert.Equal(t, "/workload/compose/archive/docker-compose.yml", change.Path)
+	assert.Equal(t, DF.KindAdded, change.Kind)
+	assert.Equal(t, DF.SectionArchive, change.Section)
+	assert.Empty(t, change.New)
+	assert.Contains(t, change.Lines, "+services:")
+}
+
+func TestDiffContractsIdentical(t *testing.T) {
+	contract, err := E.UnwrapError(F.Pipe1(
+		AIOE.Base64TarFolder("../../samples/hello-world"),
+		IOE.Map[error](func(archive string) *types.Contract {
+			return &types.Contract{Workload: &types.Workload{Type: types.TypeWorkload, Compose: &types.Compose{Archive: archive}}}
+		}),
+	)())
+	require.NoError(t, err)
+
+	assert.Empty(t, DiffContracts(true)(contract, contract).Changes)
+}
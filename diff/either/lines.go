@@ -0,0 +1,124 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// number of unchanged lines shown around a change
+	contextLines = 2
+	// upper bound for the size of the table of the line diff, larger files are reported without lines
+	maxDiffCells = 1 << 22
+	// separates hunks of a line diff
+	hunkSeparator = "..."
+)
+
+// isText tests if the content of a file can be diffed line by line
+func isText(data []byte) bool {
+	return utf8.Valid(data) && bytes.IndexByte(data, 0) < 0
+}
+
+// splitLines splits text into lines, a trailing line break does not produce an empty line
+func splitLines(data []byte) []string {
+	text := strings.TrimSuffix(string(data), "\n")
+	if len(text) == 0 {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// editScript computes the lines of a diff based on the longest common subsequence, each line is prefixed by ' ', '-' or '+'
+func editScript(left, right []string) []string {
+	n, m := len(left), len(right)
+	// lcs[i][j] is the length of the longest common subsequence of left[i:] and right[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	script := make([]string, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case left[i] == right[j]:
+			script = append(script, " "+left[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			script = append(script, "-"+left[i])
+			i++
+		default:
+			script = append(script, "+"+right[j])
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		script = append(script, "-"+left[i])
+	}
+	for ; j < m; j++ {
+		script = append(script, "+"+right[j])
+	}
+	return script
+}
+
+// withContext reduces an edit script to the changed lines and their context, omitted lines are replaced by a separator
+func withContext(script []string) []string {
+	keep := make([]bool, len(script))
+	for idx, line := range script {
+		if line[0] == ' ' {
+			continue
+		}
+		for ctx := idx - contextLines; ctx <= idx+contextLines; ctx++ {
+			if ctx >= 0 && ctx < len(script) {
+				keep[ctx] = true
+			}
+		}
+	}
+	var result []string
+	for idx, line := range script {
+		if keep[idx] {
+			result = append(result, line)
+		} else if len(result) > 0 && result[len(result)-1] != hunkSeparator && idx+1 < len(script) && keep[idx+1] {
+			result = append(result, hunkSeparator)
+		}
+	}
+	return result
+}
+
+// diffLines returns the line based diff of two text files, nil if the files are binary or too large
+func diffLines(left, right []byte) []string {
+	if !isText(left) || !isText(right) {
+		return nil
+	}
+	leftLines, rightLines := splitLines(left), splitLines(right)
+	if (len(leftLines)+1)*(len(rightLines)+1) > maxDiffCells {
+		return nil
+	}
+	return withContext(editScript(leftLines, rightLines))
+}
@@ -0,0 +1,32 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	left := []byte("a\nb\nc\nd\ne\nf\ng\nh\n")
+	right := []byte("a\nb\nC\nd\ne\nf\ng\nh\ni\n")
+
+	assert.Equal(t, []string{" a", " b", "-c", "+C", " d", " e", "...", " g", " h", "+i"}, diffLines(left, right))
+}
+
+func TestDiffLinesBinary(t *testing.T) {
+	assert.Nil(t, diffLines([]byte{0, 1}, []byte("text")))
+}
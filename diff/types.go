@@ -0,0 +1,52 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+const (
+	// kinds of changes
+	KindAdded   = "added"
+	KindRemoved = "removed"
+	KindChanged = "changed"
+
+	// sections of a contract that changes are grouped by
+	SectionContract = "contract"
+	SectionEnv      = "env"
+	SectionAuths    = "auths"
+	SectionVolumes  = "volumes"
+	SectionLogging  = "logging"
+	SectionImages   = "images"
+	SectionArchive  = "archive"
+
+	// Mask replaces secret values in a report
+	Mask = "********"
+)
+
+type (
+	// Change describes a single difference between two contracts
+	Change struct {
+		Path    string   `json:"path" yaml:"path"`                         // JSON pointer to the value, files in archives are appended to the pointer of the archive
+		Section string   `json:"section" yaml:"section"`                   // section of the contract the value belongs to
+		Kind    string   `json:"kind" yaml:"kind"`                         // one of added, removed or changed
+		Old     string   `json:"old,omitempty" yaml:"old,omitempty"`       // previous value, masked for secrets
+		New     string   `json:"new,omitempty" yaml:"new,omitempty"`       // current value, masked for secrets
+		Masked  bool     `json:"masked,omitempty" yaml:"masked,omitempty"` // true if the values have been masked
+		Lines   []string `json:"lines,omitempty" yaml:"lines,omitempty"`   // line based diff of a changed text file in an archive
+	}
+
+	// Report is the semantic difference between two contracts
+	Report struct {
+		Changes []Change `json:"changes" yaml:"changes"`
+	}
+)
@@ -26,6 +26,8 @@ import (
 const (
 	TypePublicKey   = "PUBLIC KEY"
 	TypeCertificate = "CERTIFICATE"
+	// TypeEncryptedPrivateKey is the PEM type of a passphrase protected PKCS#8 private key
+	TypeEncryptedPrivateKey = "ENCRYPTED PRIVATE KEY"
 )
 
 func GetTypeFromBlock(block *pem.Block) string {
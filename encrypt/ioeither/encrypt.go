@@ -19,6 +19,7 @@ import (
 	F "github.com/IBM/fp-go/function"
 	IO "github.com/IBM/fp-go/io"
 	IOE "github.com/IBM/fp-go/ioeither"
+	IOO "github.com/IBM/fp-go/iooption"
 )
 
 type (
@@ -28,6 +29,8 @@ type (
 	Key                    = IOE.IOEither[error, []byte]
 	PubKeyFunc             = func([]byte) E.Either[error, []byte]
 	SignDigestFunc         = func([]byte) func([]byte) IOE.IOEither[error, []byte]
+	VerifyDigestFunc       = func([]byte) func([]byte) func([]byte) IOO.IOOption[error]
+	EncryptPrivKeyFunc     = func([]byte) func([]byte) IOE.IOEither[error, []byte]
 )
 
 // Encryption captures the crypto functions required to implement the source providers
@@ -44,6 +47,10 @@ type Encryption struct {
 	PubKey PubKeyFunc
 	// SignDigest computes the sha256 signature using a private key (side effect because of RSA blinding)
 	SignDigest SignDigestFunc
+	// VerifyDigest verifies the sha256 signature using a public key
+	VerifyDigest VerifyDigestFunc
+	// EncryptPrivKey protects a private key with a passphrase (side effect because of random salt)
+	EncryptPrivKey EncryptPrivKeyFunc
 }
 
 var (
@@ -56,6 +63,8 @@ var (
 			PrivKey:            OpenSSLPrivateKey,
 			PubKey:             OpenSSLPublicKey,
 			SignDigest:         OpenSSLSignDigest,
+			VerifyDigest:       OpenSSLVerifyDigest,
+			EncryptPrivKey:     OpenSSLEncryptPrivKey,
 		}
 	})
 
@@ -68,6 +77,8 @@ var (
 			PrivKey:            CryptoPrivateKey,
 			PubKey:             CryptoPublicKey,
 			SignDigest:         CryptoSignDigest,
+			VerifyDigest:       CryptoVerifyDigest,
+			EncryptPrivKey:     CryptoEncryptPrivKey,
 		}
 	})
 
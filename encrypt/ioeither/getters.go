@@ -43,3 +43,13 @@ func (enc Encryption) GetPubKey() PubKeyFunc {
 func (enc Encryption) GetSignDigest() SignDigestFunc {
 	return enc.SignDigest
 }
+
+// VerifyDigest verifies the sha256 signature using a public key
+func (enc Encryption) GetVerifyDigest() VerifyDigestFunc {
+	return enc.VerifyDigest
+}
+
+// EncryptPrivKey protects a private key with a passphrase (side effect because of random salt)
+func (enc Encryption) GetEncryptPrivKey() EncryptPrivKeyFunc {
+	return enc.EncryptPrivKey
+}
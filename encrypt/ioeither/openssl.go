@@ -75,6 +75,9 @@ var (
 
 	OpenSSLSymmetricEncrypt = handle(symmetricEncrypt)
 
+	// OpenSSLEncryptPrivKey protects a private key with a passphrase, the result is an encrypted PKCS#8 key
+	OpenSSLEncryptPrivKey = handle(encryptPrivKey)
+
 	// openSSLPublicKeyFromPrivateKey gets the public key from a private key
 	openSSLPublicKeyFromPrivateKey = F.Flow2(
 		OpenSSL("rsa", "-pubout"),
@@ -234,6 +237,13 @@ func signDigest(keyFile string) func([]byte) IOE.IOEither[error, []byte] {
 	)
 }
 
+func encryptPrivKey(keyFile string) func([]byte) IOE.IOEither[error, []byte] {
+	return F.Flow2(
+		OpenSSL("pkcs8", "-topk8", "-v2", "aes-256-cbc", "-v2prf", "hmacWithSHA256", "-iter", fmt.Sprintf("%d", pkcs8Iterations), "-in", keyFile, "-passout", "stdin"),
+		mapStdout,
+	)
+}
+
 func asymmetricDecrypt(keyFile string) func(string) IOE.IOEither[error, []byte] {
 	return F.Flow4(
 		Common.Base64DecodeE,
@@ -0,0 +1,150 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+
+	RA "github.com/IBM/fp-go/array"
+	B "github.com/IBM/fp-go/bytes"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	T "github.com/IBM/fp-go/tuple"
+	EC "github.com/ibm-hyper-protect/contract-go/encrypt/common"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// pkcs8Iterations is the PBKDF2 iteration count used to protect private keys
+const pkcs8Iterations = 100000
+
+type (
+	// pbkdf2Params are the parameters of the PBKDF2 key derivation, see RFC 8018
+	pbkdf2Params struct {
+		Salt           []byte
+		IterationCount int
+		PRF            pkix.AlgorithmIdentifier
+	}
+
+	// pbes2Params are the parameters of the PBES2 encryption scheme, see RFC 8018
+	pbes2Params struct {
+		KeyDerivationFunc pkix.AlgorithmIdentifier
+		EncryptionScheme  pkix.AlgorithmIdentifier
+	}
+
+	// encryptedPrivateKeyInfo is the envelope of an encrypted PKCS#8 private key, see RFC 5958
+	encryptedPrivateKeyInfo struct {
+		EncryptionAlgorithm pkix.AlgorithmIdentifier
+		EncryptedData       []byte
+	}
+)
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+
+	marshalPKCS8PrivateKeyE = E.Eitherize1(x509.MarshalPKCS8PrivateKey)
+	asn1MarshalE            = E.Eitherize1(asn1.Marshal)
+
+	// randomPkcs8SaltIOE produces the salt and the initialization vector
+	randomPkcs8SaltIOE = cryptoRandomIOE(2 * aes.BlockSize)
+)
+
+// pkcs7Pad pads the data to a multiple of the AES block size
+func pkcs7Pad(data []byte) []byte {
+	bytesToPad := aes.BlockSize - (len(data) % aes.BlockSize)
+	return B.Monoid.Concat(data, RA.Replicate(bytesToPad, byte(bytesToPad)))
+}
+
+// algorithmIdentifier creates an algorithm identifier with DER encoded parameters
+func algorithmIdentifier(oid asn1.ObjectIdentifier, params any) E.Either[error, pkix.AlgorithmIdentifier] {
+	return F.Pipe1(
+		asn1MarshalE(params),
+		E.Map[error](func(der []byte) pkix.AlgorithmIdentifier {
+			return pkix.AlgorithmIdentifier{
+				Algorithm:  oid,
+				Parameters: asn1.RawValue{FullBytes: der},
+			}
+		}),
+	)
+}
+
+// pbes2Algorithm describes the PBES2 scheme with PBKDF2-HMAC-SHA256 and AES-256-CBC
+func pbes2Algorithm(salt, iv []byte) E.Either[error, pkix.AlgorithmIdentifier] {
+	kdfE := algorithmIdentifier(oidPBKDF2, pbkdf2Params{
+		Salt:           salt,
+		IterationCount: pkcs8Iterations,
+		PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	encE := algorithmIdentifier(oidAES256CBC, iv)
+	return F.Pipe1(
+		E.SequenceT2(kdfE, encE),
+		E.Chain(T.Tupled2(func(kdf, enc pkix.AlgorithmIdentifier) E.Either[error, pkix.AlgorithmIdentifier] {
+			return algorithmIdentifier(oidPBES2, pbes2Params{KeyDerivationFunc: kdf, EncryptionScheme: enc})
+		})),
+	)
+}
+
+// encryptPKCS8 encrypts a DER encoded PKCS#8 private key with a passphrase
+func encryptPKCS8(passphrase, salt, iv []byte) func(der []byte) E.Either[error, []byte] {
+	key := pbkdf2.Key(passphrase, salt, pkcs8Iterations, keylen, sha256.New)
+	return func(der []byte) E.Either[error, []byte] {
+		return F.Pipe2(
+			E.SequenceT2(aesCipherE(key), pbes2Algorithm(salt, iv)),
+			E.Chain(T.Tupled2(func(block cipher.Block, alg pkix.AlgorithmIdentifier) E.Either[error, []byte] {
+				return asn1MarshalE(encryptedPrivateKeyInfo{
+					EncryptionAlgorithm: alg,
+					EncryptedData:       cbcEncrypt(block, iv)(pkcs7Pad(der)),
+				})
+			})),
+			E.Map[error](func(data []byte) []byte {
+				return pem.EncodeToMemory(&pem.Block{Type: EC.TypeEncryptedPrivateKey, Bytes: data})
+			}),
+		)
+	}
+}
+
+// CryptoEncryptPrivKey protects a private key with a passphrase, the result is an encrypted PKCS#8 key
+// (PBES2 with PBKDF2-HMAC-SHA256 and AES-256-CBC) using the crypto library
+func CryptoEncryptPrivKey(privKey []byte) func(passphrase []byte) IOE.IOEither[error, []byte] {
+	derE := F.Pipe2(
+		privKey,
+		privToRsaKey,
+		E.Chain(F.Flow2(
+			F.ToAny[*rsa.PrivateKey],
+			marshalPKCS8PrivateKeyE,
+		)),
+	)
+	return func(passphrase []byte) IOE.IOEither[error, []byte] {
+		return F.Pipe1(
+			randomPkcs8SaltIOE,
+			IOE.ChainEitherK(func(random []byte) E.Either[error, []byte] {
+				return F.Pipe1(
+					derE,
+					E.Chain(encryptPKCS8(passphrase, random[:aes.BlockSize], random[aes.BlockSize:])),
+				)
+			}),
+		)
+	}
+}
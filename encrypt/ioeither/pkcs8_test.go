@@ -0,0 +1,70 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	EC "github.com/ibm-hyper-protect/contract-go/encrypt/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encryptPrivKeyTest protects the test key with a passphrase and makes sure that openSSL can unlock it again
+func encryptPrivKeyTest(encPrivKey EncryptPrivKeyFunc) func(t *testing.T) {
+	return func(t *testing.T) {
+		passphrase := []byte("my secret passphrase")
+
+		key, err := E.UnwrapError(privKey)
+		require.NoError(t, err)
+
+		encrypted, err := E.UnwrapError(encPrivKey(key)(passphrase)())
+		require.NoError(t, err)
+
+		block := EC.PemDecodeAll(encrypted)
+		require.Len(t, block, 1)
+		assert.Equal(t, EC.TypeEncryptedPrivateKey, block[0].Type)
+
+		// unlock the key again
+		decrypted := F.Pipe2(
+			encrypted,
+			OpenSSL("pkey", "-passin", "pass:my secret passphrase"),
+			mapStdout,
+		)
+
+		fpDecrypted, err := E.UnwrapError(F.Pipe1(
+			decrypted,
+			IOE.ChainEitherK(CryptoPrivKeyFingerprint),
+		)())
+		require.NoError(t, err)
+
+		fpOriginal, err := E.UnwrapError(CryptoPrivKeyFingerprint(key))
+		require.NoError(t, err)
+
+		assert.Equal(t, fpOriginal, fpDecrypted)
+
+		// a wrong passphrase must fail
+		_, err = E.UnwrapError(OpenSSL("pkey", "-passin", "pass:wrong")(encrypted)())
+		assert.Error(t, err)
+	}
+}
+
+func TestEncryptPrivKey(t *testing.T) {
+	t.Run("crypto", encryptPrivKeyTest(CryptoEncryptPrivKey))
+	t.Run("openssl", encryptPrivKeyTest(OpenSSLEncryptPrivKey))
+}
@@ -0,0 +1,262 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IO "github.com/IBM/fp-go/io"
+	IOE "github.com/IBM/fp-go/ioeither"
+	T "github.com/IBM/fp-go/tuple"
+	EC "github.com/ibm-hyper-protect/contract-go/encrypt/common"
+)
+
+const (
+	// BackendCrypto is the name of the backend based on golang crypto
+	BackendCrypto = "crypto"
+	// BackendOpenSSL is the name of the backend based on the openSSL binary
+	BackendOpenSSL = "openssl"
+)
+
+type (
+	// SelfTestCheck is the outcome of a single check of the self-test, the error is empty if the check passed
+	SelfTestCheck struct {
+		Name  string `json:"name" yaml:"name"`
+		Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	}
+
+	// SelfTestReport is the result of the self-test across the crypto and the openSSL backend
+	SelfTestReport struct {
+		// Backend is the backend selected by [DefaultEncryption]
+		Backend string `json:"backend" yaml:"backend"`
+		// OpenSSL is the version of the openSSL binary, empty if the binary cannot be executed
+		OpenSSL     string          `json:"openssl,omitempty" yaml:"openssl,omitempty"`
+		PayloadSize int             `json:"payloadSize" yaml:"payloadSize"`
+		Checks      []SelfTestCheck `json:"checks" yaml:"checks"`
+	}
+
+	// backend bundles the decryption with the encryption functions of a backend
+	backend struct {
+		name    string
+		enc     Encryption
+		decrypt func([]byte) func(string) IOE.IOEither[error, []byte]
+	}
+)
+
+var (
+	cryptoBackend = backend{
+		name:    BackendCrypto,
+		enc:     CryptoEncryption(),
+		decrypt: CryptoDecryptBasic,
+	}
+
+	openSSLBackend = backend{
+		name:    BackendOpenSSL,
+		enc:     OpenSSLEncryption(),
+		decrypt: OpenSSLDecryptBasic,
+	}
+
+	// name of the backend selected by [DefaultEncryption]
+	defaultBackend = F.Pipe1(
+		validOpenSSL,
+		IOE.Fold(F.Constant1[error](IO.Of(BackendCrypto)), F.Constant1[string](IO.Of(BackendOpenSSL))),
+	)
+
+	// version of the openSSL binary or the empty string
+	openSSLVersionString = F.Pipe1(
+		openSSLVersion,
+		IOE.Fold(F.Constant1[error](IO.Of("")), F.Flow2(EC.GetVersion, IO.Of[string])),
+	)
+)
+
+// Passed tests if all checks of the self-test passed
+func (r *SelfTestReport) Passed() bool {
+	return A.IsEmpty(r.Failed())
+}
+
+// Failed returns the checks that did not pass
+func (r *SelfTestReport) Failed() []SelfTestCheck {
+	return A.Filter(func(check SelfTestCheck) bool {
+		return check.Error != ""
+	})(r.Checks)
+}
+
+// ToError returns an error that lists the failed checks or nil if all checks passed
+func (r *SelfTestReport) ToError() error {
+	failed := r.Failed()
+	if A.IsEmpty(failed) {
+		return nil
+	}
+	return fmt.Errorf("%d of %d self-test check(s) failed: %s", len(failed), len(r.Checks), strings.Join(A.Map(func(check SelfTestCheck) string {
+		return fmt.Sprintf("%s: %s", check.Name, check.Error)
+	})(failed), "; "))
+}
+
+// selfSignedCertificate issues a short lived self-signed certificate for a private key (side effect because of the
+// random serial number)
+func selfSignedCertificate(privKey []byte) IOE.IOEither[error, []byte] {
+	return F.Pipe4(
+		privKey,
+		pemDecodeE,
+		E.Chain(parsePrivateKeyE),
+		IOE.FromEither[error, *rsa.PrivateKey],
+		IOE.ChainEitherK(func(key *rsa.PrivateKey) E.Either[error, []byte] {
+			return E.TryCatchError(func() ([]byte, error) {
+				serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+				if err != nil {
+					return nil, err
+				}
+				now := time.Now()
+				template := &x509.Certificate{
+					SerialNumber: serial,
+					Subject:      pkix.Name{CommonName: "contract-go self-test"},
+					NotBefore:    now.Add(-time.Minute),
+					NotAfter:     now.Add(time.Hour),
+					KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+				}
+				der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+				if err != nil {
+					return nil, err
+				}
+				return pem.EncodeToMemory(&pem.Block{Type: EC.TypeCertificate, Bytes: der}), nil
+			}())
+		}),
+	)
+}
+
+// sameBytes returns an error if the actual bytes differ from the expected ones
+func sameBytes(msg string) func(expected, actual []byte) E.Either[error, []byte] {
+	return func(expected, actual []byte) E.Either[error, []byte] {
+		if bytes.Equal(expected, actual) {
+			return E.Of[error](actual)
+		}
+		return E.Left[[]byte](errors.New(msg))
+	}
+}
+
+// runCheck executes a check and records its outcome
+func runCheck[A any](name string, check IOE.IOEither[error, A]) IO.IO[SelfTestCheck] {
+	return F.Pipe1(
+		check,
+		IOE.Fold(func(err error) IO.IO[SelfTestCheck] {
+			return IO.Of(SelfTestCheck{Name: name, Error: err.Error()})
+		}, F.Constant1[A](IO.Of(SelfTestCheck{Name: name}))),
+	)
+}
+
+// crossDecrypt encrypts the payload for the recipient with one backend and decrypts it with the other one
+func crossDecrypt(from, to backend, kind string, recipient, privKey, payload []byte) IO.IO[SelfTestCheck] {
+	return runCheck(
+		fmt.Sprintf("encrypt for a %s with %s, decrypt with %s", kind, from.name, to.name),
+		F.Pipe2(
+			from.enc.EncryptBasic(recipient)(payload),
+			IOE.Chain(to.decrypt(privKey)),
+			IOE.ChainEitherK(F.Bind1st(sameBytes("the decrypted payload differs from the original payload"), payload)),
+		),
+	)
+}
+
+// crossSign signs the payload with one backend and verifies the signature with the other one
+func crossSign(from, to backend, privKey, pubKey, payload []byte) IO.IO[SelfTestCheck] {
+	return runCheck(
+		fmt.Sprintf("sign with %s, verify with %s", from.name, to.name),
+		F.Pipe1(
+			from.enc.SignDigest(privKey)(payload),
+			IOE.Chain(func(signature []byte) IOE.IOEither[error, []byte] {
+				return F.Pipe2(
+					to.enc.VerifyDigest(pubKey)(payload)(signature),
+					IOE.FromIOOption[error](F.Constant(signature)),
+					IOE.Swap[[]byte, error],
+				)
+			}),
+		),
+	)
+}
+
+// sameResult computes a value from the same input with both backends and compares the results
+func sameResult(name string, crypto, openSSL func([]byte) E.Either[error, []byte], data []byte) IO.IO[SelfTestCheck] {
+	return runCheck(
+		name,
+		F.Pipe2(
+			E.SequenceT2(crypto(data), openSSL(data)),
+			E.Chain(T.Tupled2(sameBytes(fmt.Sprintf("%s and %s disagree", BackendCrypto, BackendOpenSSL)))),
+			IOE.FromEither[error, []byte],
+		),
+	)
+}
+
+// selfTestChecks returns the checks across both backends for a key pair, a certificate and a payload
+func selfTestChecks(privKey, pubKey, cert, payload []byte) []IO.IO[SelfTestCheck] {
+	return A.From(
+		runCheck("openssl binary is supported", validOpenSSL),
+		crossDecrypt(cryptoBackend, openSSLBackend, "certificate", cert, privKey, payload),
+		crossDecrypt(openSSLBackend, cryptoBackend, "certificate", cert, privKey, payload),
+		crossDecrypt(cryptoBackend, openSSLBackend, "public key", pubKey, privKey, payload),
+		crossDecrypt(openSSLBackend, cryptoBackend, "public key", pubKey, privKey, payload),
+		crossSign(cryptoBackend, openSSLBackend, privKey, pubKey, payload),
+		crossSign(openSSLBackend, cryptoBackend, privKey, pubKey, payload),
+		sameResult("certificate fingerprint", CryptoCertFingerprint, OpenSSLCertFingerprint, cert),
+		sameResult("private key fingerprint", CryptoPrivKeyFingerprint, OpenSSLPrivKeyFingerprint, privKey),
+		sameResult("public key", F.Flow2(CryptoPublicKey, E.Chain(pemDecodeE)), F.Flow2(OpenSSLPublicKey, E.Chain(pemDecodeE)), privKey),
+	)
+}
+
+// SelfTest checks that the crypto and the openSSL backend are compatible. For a random payload of the given size and a
+// generated key pair and certificate it encrypts with each backend and decrypts with the other, signs with one backend and
+// verifies with the other and compares the fingerprints. Incompatibilities are recorded in the report, the operation only
+// fails if the test material cannot be generated
+func SelfTest(payloadSize int) IOE.IOEither[error, *SelfTestReport] {
+	return F.Pipe2(
+		IOE.SequenceT2(CryptoPrivateKey, cryptoRandomIOE(payloadSize)),
+		IOE.Chain(T.Tupled2(func(privKey, payload []byte) IOE.IOEither[error, T.Tuple4[[]byte, []byte, []byte, []byte]] {
+			return IOE.SequenceT4(
+				IOE.Of[error](privKey),
+				IOE.FromEither(CryptoPublicKey(privKey)),
+				selfSignedCertificate(privKey),
+				IOE.Of[error](payload),
+			)
+		})),
+		IOE.ChainIOK[error](T.Tupled4(func(privKey, pubKey, cert, payload []byte) IO.IO[*SelfTestReport] {
+			return F.Pipe1(
+				IO.SequenceT3(
+					defaultBackend,
+					openSSLVersionString,
+					IO.SequenceArraySeq(selfTestChecks(privKey, pubKey, cert, payload)),
+				),
+				IO.Map(T.Tupled3(func(backend, version string, checks []SelfTestCheck) *SelfTestReport {
+					return &SelfTestReport{
+						Backend:     backend,
+						OpenSSL:     version,
+						PayloadSize: payloadSize,
+						Checks:      checks,
+					}
+				})),
+			)
+		})),
+	)
+}
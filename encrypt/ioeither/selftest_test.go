@@ -0,0 +1,96 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"bytes"
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTest(t *testing.T) {
+	report, err := E.UnwrapError(SelfTest(1024)())
+	require.NoError(t, err)
+
+	assert.Equal(t, defaultBackend(), report.Backend)
+	assert.Equal(t, 1024, report.PayloadSize)
+	assert.Len(t, report.Checks, 10)
+
+	if E.IsLeft(validOpenSSL()) {
+		t.Skip("the cross checks require a supported openssl binary")
+	}
+	assert.Contains(t, report.OpenSSL, "OpenSSL")
+	assert.Empty(t, report.Failed())
+	assert.True(t, report.Passed())
+	assert.NoError(t, report.ToError())
+}
+
+func TestSelfTestReportToError(t *testing.T) {
+	report := &SelfTestReport{
+		Checks: []SelfTestCheck{
+			{Name: "sign with crypto, verify with openssl"},
+			{Name: "sign with openssl, verify with crypto", Error: "verification failure"},
+		},
+	}
+
+	assert.False(t, report.Passed())
+	assert.EqualError(t, report.ToError(), "1 of 2 self-test check(s) failed: sign with openssl, verify with crypto: verification failure")
+}
+
+// tamper flips the first byte of the successful result of an operation
+func tamper(data IOE.IOEither[error, []byte]) IOE.IOEither[error, []byte] {
+	return F.Pipe1(
+		data,
+		IOE.Map[error](func(data []byte) []byte {
+			tampered := bytes.Clone(data)
+			tampered[0] ^= 0xff
+			return tampered
+		}),
+	)
+}
+
+func TestSelfTestDetectsTampering(t *testing.T) {
+	privKey, err := E.UnwrapError(CryptoPrivateKey())
+	require.NoError(t, err)
+	pubKey, err := E.UnwrapError(CryptoPublicKey(privKey))
+	require.NoError(t, err)
+	payload := []byte("self-test payload")
+
+	// signatures are tampered before the verification
+	tamperedSign := cryptoBackend
+	tamperedSign.enc.SignDigest = F.Flow2(cryptoBackend.enc.SignDigest, func(sign func([]byte) IOE.IOEither[error, []byte]) func([]byte) IOE.IOEither[error, []byte] {
+		return F.Flow2(sign, tamper)
+	})
+	check := crossSign(tamperedSign, cryptoBackend, privKey, pubKey, payload)()
+	assert.Equal(t, "sign with crypto, verify with crypto", check.Name)
+	assert.NotEmpty(t, check.Error)
+
+	// decrypted payloads are tampered before the comparison
+	tamperedDecrypt := cryptoBackend
+	tamperedDecrypt.decrypt = func(privKey []byte) func(string) IOE.IOEither[error, []byte] {
+		return F.Flow2(cryptoBackend.decrypt(privKey), tamper)
+	}
+	check = crossDecrypt(cryptoBackend, tamperedDecrypt, "public key", pubKey, privKey, payload)()
+	assert.Equal(t, "the decrypted payload differs from the original payload", check.Error)
+
+	// untampered backends pass
+	assert.Empty(t, crossSign(cryptoBackend, cryptoBackend, privKey, pubKey, payload)().Error)
+	assert.Empty(t, crossDecrypt(cryptoBackend, cryptoBackend, "public key", pubKey, privKey, payload)().Error)
+}
@@ -35,3 +35,19 @@ var WriteToOutput = F.Flow2(
 	U.IsNotStdinNorStdout,
 	O.Fold(F.Constant(WriteToStdOut), F.Bind2nd(IOEF.WriteFile, os.ModePerm)),
 )
+
+// WriteToNewFile writes data into a file that must not exist, yet. The file is created with the given permissions, so
+// it is suitable for sensitive data such as private keys.
+func WriteToNewFile(name string, perm os.FileMode) func([]byte) IOE.IOEither[error, []byte] {
+	return func(data []byte) IOE.IOEither[error, []byte] {
+		return IOE.TryCatchError(func() ([]byte, error) {
+			f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			_, err = f.Write(data)
+			return data, err
+		})
+	}
+}
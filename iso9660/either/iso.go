@@ -0,0 +1,487 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	E "github.com/IBM/fp-go/either"
+	ISO "github.com/ibm-hyper-protect/contract-go/iso9660"
+	TA "github.com/ibm-hyper-protect/contract-go/tar"
+)
+
+const (
+	// sectors reserved for the system area at the start of the image
+	systemAreaSectors = 16
+
+	// volume descriptor types
+	typePrimary       = 1
+	typeSupplementary = 2
+	typeTerminator    = 255
+
+	// file flags of a directory record
+	flagDirectory = 2
+
+	// size of the path table, it only holds the root directory
+	pathTableSize = 10
+
+	// POSIX file modes recorded by Rock Ridge
+	modeFile      = 0100444
+	modeDirectory = 040555
+
+	// identification of the Rock Ridge extension in the `ER` entry
+	rockRidgeID           = "RRIP_1991A"
+	rockRidgeDescriptor   = "THE ROCK RIDGE INTERCHANGE PROTOCOL PROVIDES SUPPORT FOR POSIX FILE SYSTEM SEMANTICS"
+	applicationIdentifier = "CONTRACT-GO"
+)
+
+var (
+	// identifiers of the `.` and `..` entries of a directory
+	idSelf   = []byte{0}
+	idParent = []byte{1}
+
+	// characters that are not allowed in Joliet file names
+	jolietReserved = "*/:;?\\"
+)
+
+// file is a file in the root directory of the image
+type file struct {
+	name    string // name as recorded by Joliet and Rock Ridge
+	primary string // ISO9660 level 1 identifier
+	data    []byte
+	extent  uint32
+}
+
+// layout holds the location of the structures of an image
+type layout struct {
+	primaryRoot  uint32 // extent of the primary root directory
+	primarySize  uint32 // size of the primary root directory in bytes
+	jolietRoot   uint32 // extent of the Joliet root directory
+	jolietSize   uint32 // size of the Joliet root directory in bytes
+	totalSectors uint32
+}
+
+func sectors(size int) uint32 {
+	return uint32((size + ISO.SectorSize - 1) / ISO.SectorSize)
+}
+
+func putBoth16(buf []byte, value uint16) {
+	binary.LittleEndian.PutUint16(buf[0:2], value)
+	binary.BigEndian.PutUint16(buf[2:4], value)
+}
+
+func putBoth32(buf []byte, value uint32) {
+	binary.LittleEndian.PutUint32(buf[0:4], value)
+	binary.BigEndian.PutUint32(buf[4:8], value)
+}
+
+// ucs2 encodes a string in big endian UCS-2 as required by Joliet
+func ucs2(value string) []byte {
+	codes := utf16.Encode([]rune(value))
+	buf := make([]byte, 2*len(codes))
+	for idx, code := range codes {
+		binary.BigEndian.PutUint16(buf[2*idx:], code)
+	}
+	return buf
+}
+
+// putPadded copies the value into the buffer and fills the remainder with (UCS-2) spaces
+func putPadded(buf []byte, value string, joliet bool) {
+	if joliet {
+		for idx := 0; idx+1 < len(buf); idx += 2 {
+			buf[idx], buf[idx+1] = 0, ' '
+		}
+		copy(buf, ucs2(value))
+		return
+	}
+	copy(buf, bytes.Repeat([]byte{' '}, len(buf)))
+	copy(buf, value)
+}
+
+// putRecordingTime encodes the 7 byte timestamp of a directory record
+func putRecordingTime(buf []byte, t time.Time) {
+	utc := t.UTC()
+	buf[0] = byte(utc.Year() - 1900)
+	buf[1] = byte(utc.Month())
+	buf[2] = byte(utc.Day())
+	buf[3] = byte(utc.Hour())
+	buf[4] = byte(utc.Minute())
+	buf[5] = byte(utc.Second())
+	buf[6] = 0
+}
+
+// putDecDateTime encodes the 17 byte timestamp of a volume descriptor
+func putDecDateTime(buf []byte, t time.Time) {
+	utc := t.UTC()
+	copy(buf, fmt.Sprintf("%04d%02d%02d%02d%02d%02d%02d", utc.Year(), utc.Month(), utc.Day(), utc.Hour(), utc.Minute(), utc.Second(), utc.Nanosecond()/10000000))
+	buf[16] = 0
+}
+
+// putUnspecifiedDateTime encodes a volume descriptor timestamp that is not specified
+func putUnspecifiedDateTime(buf []byte) {
+	copy(buf, strings.Repeat("0", 16))
+	buf[16] = 0
+}
+
+// primaryIdentifier converts a file name into an ISO9660 level 1 identifier, i.e. an upper case 8.3 name
+func primaryIdentifier(name string, suffix int) string {
+	mapChars := func(value string) string {
+		return strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+				return r
+			case r >= 'a' && r <= 'z':
+				return r - 'a' + 'A'
+			default:
+				return '_'
+			}
+		}, value)
+	}
+	base, ext := name, ""
+	if idx := strings.LastIndex(name, "."); idx > 0 {
+		base, ext = name[:idx], name[idx+1:]
+	}
+	base, ext = mapChars(base), mapChars(ext)
+	if len(ext) > 3 {
+		ext = ext[:3]
+	}
+	if len(base) > 8 {
+		base = base[:8]
+	}
+	if suffix > 0 {
+		tag := fmt.Sprintf("%d", suffix)
+		if len(base)+len(tag) > 8 {
+			base = base[:8-len(tag)]
+		}
+		base = base + tag
+	}
+	return fmt.Sprintf("%s.%s;1", base, ext)
+}
+
+// validateName checks if a file name can be recorded in the root directory of the image
+func validateName(name string) error {
+	runes := []rune(name)
+	if len(runes) == 0 || len(runes) > ISO.MaxNameLength {
+		return fmt.Errorf("the file name [%s] must have between 1 and %d characters", name, ISO.MaxNameLength)
+	}
+	for _, r := range runes {
+		if r < ' ' || r > 0xFFFF || strings.ContainsRune(jolietReserved, r) {
+			return fmt.Errorf("the file name [%s] contains the invalid character [%q], only files in the root directory are supported", name, r)
+		}
+	}
+	return nil
+}
+
+// validateVolumeID checks if the volume identifier can be recorded in the image
+func validateVolumeID(volumeID string) error {
+	if len([]rune(volumeID)) > ISO.MaxVolumeIDLength {
+		return fmt.Errorf("the volume identifier [%s] must not exceed %d characters", volumeID, ISO.MaxVolumeIDLength)
+	}
+	return nil
+}
+
+// filesFromList validates the file list and assigns unique ISO9660 identifiers, sorted by name
+func filesFromList(files TA.FileList) ([]*file, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if err := validateName(name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	used := make(map[string]bool)
+	result := make([]*file, len(names))
+	for idx, name := range names {
+		primary := primaryIdentifier(name, 0)
+		for suffix := 1; used[primary]; suffix++ {
+			primary = primaryIdentifier(name, suffix)
+		}
+		used[primary] = true
+		result[idx] = &file{name: name, primary: primary, data: files[name]}
+	}
+	return result, nil
+}
+
+// directoryRecord encodes a directory record, the system use field carries Rock Ridge entries
+func directoryRecord(id []byte, extent, size uint32, flags byte, modTime time.Time, systemUse []byte) ([]byte, error) {
+	offset := 33 + len(id)
+	if len(id)%2 == 0 {
+		offset++
+	}
+	length := offset + len(systemUse)
+	if length%2 == 1 {
+		length++
+	}
+	if length > 255 {
+		return nil, fmt.Errorf("the directory record for [%s] exceeds the maximum length", id)
+	}
+	rec := make([]byte, length)
+	rec[0] = byte(length)
+	putBoth32(rec[2:10], extent)
+	putBoth32(rec[10:18], size)
+	putRecordingTime(rec[18:25], modTime)
+	rec[25] = flags
+	putBoth16(rec[28:32], 1)
+	rec[32] = byte(len(id))
+	copy(rec[33:], id)
+	copy(rec[offset:], systemUse)
+	return rec, nil
+}
+
+// susp encodes a system use entry
+func susp(signature string, data []byte) []byte {
+	return append([]byte{signature[0], signature[1], byte(4 + len(data)), 1}, data...)
+}
+
+// rrPosix encodes the Rock Ridge `PX` entry with the POSIX file attributes
+func rrPosix(mode uint32, links uint32) []byte {
+	data := make([]byte, 32)
+	putBoth32(data[0:8], mode)
+	putBoth32(data[8:16], links)
+	return susp("PX", data)
+}
+
+// rrName encodes the Rock Ridge `NM` entry with the alternate name of a file
+func rrName(name string) []byte {
+	return susp("NM", append([]byte{0}, name...))
+}
+
+// rrRoot encodes the entries that identify the Rock Ridge extension, they are recorded in the `.` entry of the root
+func rrRoot() []byte {
+	sp := susp("SP", []byte{0xBE, 0xEF, 0})
+	er := susp("ER", append([]byte{byte(len(rockRidgeID)), byte(len(rockRidgeDescriptor)), 0, 1}, rockRidgeID+rockRidgeDescriptor...))
+	return bytes.Join([][]byte{sp, rrPosix(modeDirectory, 2), er}, nil)
+}
+
+// packDirectory lays out directory records in sectors, a record must not cross a sector boundary
+func packDirectory(records [][]byte) []byte {
+	var buf []byte
+	for _, rec := range records {
+		remaining := ISO.SectorSize - len(buf)%ISO.SectorSize
+		if len(rec) > remaining {
+			buf = append(buf, make([]byte, remaining)...)
+		}
+		buf = append(buf, rec...)
+	}
+	return append(buf, make([]byte, int(sectors(len(buf)))*ISO.SectorSize-len(buf))...)
+}
+
+// primaryDirectory encodes the root directory of the primary volume, including the Rock Ridge entries
+func primaryDirectory(files []*file, root, size uint32, modTime time.Time) ([]byte, error) {
+	self, err := directoryRecord(idSelf, root, size, flagDirectory, modTime, rrRoot())
+	if err != nil {
+		return nil, err
+	}
+	parent, err := directoryRecord(idParent, root, size, flagDirectory, modTime, rrPosix(modeDirectory, 2))
+	if err != nil {
+		return nil, err
+	}
+	sorted := append([]*file{}, files...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].primary < sorted[j].primary
+	})
+	records := [][]byte{self, parent}
+	for _, f := range sorted {
+		rec, err := directoryRecord([]byte(f.primary), f.extent, uint32(len(f.data)), 0, modTime, append(rrPosix(modeFile, 1), rrName(f.name)...))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return packDirectory(records), nil
+}
+
+// jolietDirectory encodes the root directory of the Joliet volume
+func jolietDirectory(files []*file, root, size uint32, modTime time.Time) ([]byte, error) {
+	self, err := directoryRecord(idSelf, root, size, flagDirectory, modTime, nil)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := directoryRecord(idParent, root, size, flagDirectory, modTime, nil)
+	if err != nil {
+		return nil, err
+	}
+	records := [][]byte{self, parent}
+	// files are sorted by name, which matches the order of their UCS-2 encoding
+	for _, f := range files {
+		rec, err := directoryRecord(ucs2(f.name+";1"), f.extent, uint32(len(f.data)), 0, modTime, nil)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return packDirectory(records), nil
+}
+
+// pathTable encodes a path table that only holds the root directory
+func pathTable(root uint32, order binary.ByteOrder) []byte {
+	buf := make([]byte, ISO.SectorSize)
+	buf[0] = 1
+	order.PutUint32(buf[2:6], root)
+	order.PutUint16(buf[6:8], 1)
+	return buf
+}
+
+// volumeDescriptor encodes the primary or the Joliet supplementary volume descriptor
+func volumeDescriptor(joliet bool, volumeID string, pathTables, root, rootSize, totalSectors uint32, modTime time.Time) ([]byte, error) {
+	buf := make([]byte, ISO.SectorSize)
+	buf[0] = typePrimary
+	if joliet {
+		buf[0] = typeSupplementary
+		copy(buf[88:91], "%/E")
+	}
+	copy(buf[1:6], "CD001")
+	buf[6] = 1
+	putPadded(buf[8:40], "", joliet)
+	putPadded(buf[40:72], volumeID, joliet)
+	putBoth32(buf[80:88], totalSectors)
+	putBoth16(buf[120:124], 1)
+	putBoth16(buf[124:128], 1)
+	putBoth16(buf[128:132], ISO.SectorSize)
+	putBoth32(buf[132:140], pathTableSize)
+	binary.LittleEndian.PutUint32(buf[140:144], pathTables)
+	binary.BigEndian.PutUint32(buf[148:152], pathTables+1)
+	rec, err := directoryRecord(idSelf, root, rootSize, flagDirectory, modTime, nil)
+	if err != nil {
+		return nil, err
+	}
+	copy(buf[156:190], rec)
+	putPadded(buf[190:318], "", joliet)
+	putPadded(buf[318:446], "", joliet)
+	putPadded(buf[446:574], "", joliet)
+	putPadded(buf[574:702], applicationIdentifier, joliet)
+	putPadded(buf[702:739], "", joliet)
+	putPadded(buf[739:776], "", joliet)
+	putPadded(buf[776:813], "", joliet)
+	putDecDateTime(buf[813:830], modTime)
+	putDecDateTime(buf[830:847], modTime)
+	putUnspecifiedDateTime(buf[847:864])
+	putDecDateTime(buf[864:881], modTime)
+	buf[881] = 1
+	return buf, nil
+}
+
+// terminator encodes the volume descriptor set terminator
+func terminator() []byte {
+	buf := make([]byte, ISO.SectorSize)
+	buf[0] = typeTerminator
+	copy(buf[1:6], "CD001")
+	buf[6] = 1
+	return buf
+}
+
+// computeLayout assigns the extents of the directories and files
+func computeLayout(files []*file, modTime time.Time) (*layout, error) {
+	// the size of the directories does not depend on the extents, so compute it upfront
+	primary, err := primaryDirectory(files, 0, 0, modTime)
+	if err != nil {
+		return nil, err
+	}
+	joliet, err := jolietDirectory(files, 0, 0, modTime)
+	if err != nil {
+		return nil, err
+	}
+	// system area, three volume descriptors and four path tables
+	next := uint32(systemAreaSectors + 3 + 4)
+	l := &layout{
+		primaryRoot: next,
+		primarySize: uint32(len(primary)),
+	}
+	next += sectors(len(primary))
+	l.jolietRoot = next
+	l.jolietSize = uint32(len(joliet))
+	next += sectors(len(joliet))
+	for _, f := range files {
+		f.extent = next
+		next += sectors(len(f.data))
+	}
+	l.totalSectors = next
+	return l, nil
+}
+
+// marshal encodes the files into an ISO9660 image
+func marshal(volumeID string, modTime time.Time, list TA.FileList) ([]byte, error) {
+	if err := validateVolumeID(volumeID); err != nil {
+		return nil, err
+	}
+	files, err := filesFromList(list)
+	if err != nil {
+		return nil, err
+	}
+	l, err := computeLayout(files, modTime)
+	if err != nil {
+		return nil, err
+	}
+	const pathTables = systemAreaSectors + 3
+	pvd, err := volumeDescriptor(false, volumeID, pathTables, l.primaryRoot, l.primarySize, l.totalSectors, modTime)
+	if err != nil {
+		return nil, err
+	}
+	svd, err := volumeDescriptor(true, volumeID, pathTables+2, l.jolietRoot, l.jolietSize, l.totalSectors, modTime)
+	if err != nil {
+		return nil, err
+	}
+	primary, err := primaryDirectory(files, l.primaryRoot, l.primarySize, modTime)
+	if err != nil {
+		return nil, err
+	}
+	joliet, err := jolietDirectory(files, l.jolietRoot, l.jolietSize, modTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	buffer.Grow(int(l.totalSectors) * ISO.SectorSize)
+	buffer.Write(make([]byte, systemAreaSectors*ISO.SectorSize))
+	buffer.Write(pvd)
+	buffer.Write(svd)
+	buffer.Write(terminator())
+	buffer.Write(pathTable(l.primaryRoot, binary.LittleEndian))
+	buffer.Write(pathTable(l.primaryRoot, binary.BigEndian))
+	buffer.Write(pathTable(l.jolietRoot, binary.LittleEndian))
+	buffer.Write(pathTable(l.jolietRoot, binary.BigEndian))
+	buffer.Write(primary)
+	buffer.Write(joliet)
+	for _, f := range files {
+		buffer.Write(f.data)
+		buffer.Write(make([]byte, int(sectors(len(f.data)))*ISO.SectorSize-len(f.data)))
+	}
+	return buffer.Bytes(), nil
+}
+
+// MarshalAt encodes a file list into an ISO9660 image with Joliet and Rock Ridge extensions. All files are placed
+// in the root directory and carry the given modification time, which makes the image reproducible.
+//
+// - volumeID is the volume identifier, e.g. [ISO.VolumeIDCidata]
+// - modTime is the time recorded for the volume and all files
+func MarshalAt(volumeID string, modTime time.Time) func(TA.FileList) E.Either[error, []byte] {
+	return func(files TA.FileList) E.Either[error, []byte] {
+		return E.TryCatchError(marshal(volumeID, modTime, files))
+	}
+}
+
+// Marshal encodes a file list into an ISO9660 image with Joliet and Rock Ridge extensions, using the current time
+func Marshal(volumeID string) func(TA.FileList) E.Either[error, []byte] {
+	return func(files TA.FileList) E.Either[error, []byte] {
+		return MarshalAt(volumeID, time.Now())(files)
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	E "github.com/IBM/fp-go/either"
+	ISO "github.com/ibm-hyper-protect/contract-go/iso9660"
+	TA "github.com/ibm-hyper-protect/contract-go/tar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2023, 5, 17, 10, 20, 30, 0, time.UTC)
+
+// testEntry is a directory record parsed back from an image
+type testEntry struct {
+	id        []byte
+	extent    uint32
+	size      uint32
+	systemUse []byte
+}
+
+func sector(image []byte, idx uint32) []byte {
+	return image[int(idx)*ISO.SectorSize : int(idx+1)*ISO.SectorSize]
+}
+
+// readDirectory parses the records of a directory, skipping the `.` and `..` entries
+func readDirectory(image []byte, extent, size uint32) []testEntry {
+	var entries []testEntry
+	data := image[int(extent)*ISO.SectorSize : int(extent)*ISO.SectorSize+int(size)]
+	for offset := 0; offset < len(data); {
+		length := int(data[offset])
+		if length == 0 {
+			// skip the padding to the next sector
+			offset = (offset/ISO.SectorSize + 1) * ISO.SectorSize
+			continue
+		}
+		rec := data[offset : offset+length]
+		idLen := int(rec[32])
+		suOffset := 33 + idLen
+		if idLen%2 == 0 {
+			suOffset++
+		}
+		entries = append(entries, testEntry{
+			id:        rec[33 : 33+idLen],
+			extent:    binary.LittleEndian.Uint32(rec[2:6]),
+			size:      binary.LittleEndian.Uint32(rec[10:14]),
+			systemUse: rec[suOffset:],
+		})
+		offset += length
+	}
+	return entries[2:]
+}
+
+// readRoot parses the root directory referenced by a volume descriptor
+func readRoot(image, vd []byte) []testEntry {
+	return readDirectory(image, binary.LittleEndian.Uint32(vd[158:162]), binary.LittleEndian.Uint32(vd[166:170]))
+}
+
+// rockRidgeName extracts the name from the `NM` entry of the system use field
+func rockRidgeName(su []byte) string {
+	for offset := 0; offset+4 <= len(su) && su[offset+2] > 0; offset += int(su[offset+2]) {
+		if string(su[offset:offset+2]) == "NM" {
+			return string(su[offset+5 : offset+int(su[offset+2])])
+		}
+	}
+	return ""
+}
+
+func jolietName(id []byte) string {
+	codes := make([]uint16, len(id)/2)
+	for idx := range codes {
+		codes[idx] = binary.BigEndian.Uint16(id[2*idx:])
+	}
+	return string(utf16.Decode(codes))
+}
+
+func content(image []byte, e testEntry) []byte {
+	start := int(e.extent) * ISO.SectorSize
+	return image[start : start+int(e.size)]
+}
+
+func TestMarshal(t *testing.T) {
+	files := TA.FileList{
+		ISO.FileUserData:   []byte("hyper-protect-basic.some.token"),
+		ISO.FileMetaData:   []byte("local-hostname: test\n"),
+		ISO.FileVendorData: []byte{},
+		"large.bin":        bytes.Repeat([]byte{0x42}, 3*ISO.SectorSize+1),
+	}
+
+	image, err := E.UnwrapError(MarshalAt(ISO.VolumeIDCidata, testTime)(files))
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(image)%ISO.SectorSize)
+
+	pvd := sector(image, 16)
+	svd := sector(image, 17)
+	assert.Equal(t, []byte("\x01CD001\x01"), pvd[0:7])
+	assert.Equal(t, []byte("\x02CD001\x01"), svd[0:7])
+	assert.Equal(t, []byte("\xffCD001\x01"), sector(image, 18)[0:7])
+	assert.Equal(t, uint32(len(image)/ISO.SectorSize), binary.LittleEndian.Uint32(pvd[80:84]))
+
+	// volume identifiers
+	assert.Equal(t, ISO.VolumeIDCidata, strings.TrimRight(string(pvd[40:72]), " "))
+	assert.Equal(t, ISO.VolumeIDCidata, strings.TrimRight(jolietName(svd[40:72]), " "))
+	assert.Equal(t, []byte("%/E"), svd[88:91])
+
+	t.Run("rock ridge", func(t *testing.T) {
+		entries := readRoot(image, pvd)
+		require.Len(t, entries, len(files))
+		for _, e := range entries {
+			name := rockRidgeName(e.systemUse)
+			require.Contains(t, files, name)
+			assert.Equal(t, files[name], content(image, e))
+			assert.True(t, strings.HasSuffix(string(e.id), ";1"))
+		}
+	})
+
+	t.Run("joliet", func(t *testing.T) {
+		entries := readRoot(image, svd)
+		require.Len(t, entries, len(files))
+		for _, e := range entries {
+			name := strings.TrimSuffix(jolietName(e.id), ";1")
+			require.Contains(t, files, name)
+			assert.Equal(t, files[name], content(image, e))
+		}
+	})
+}
+
+func TestMarshalReproducible(t *testing.T) {
+	files := TA.FileList{
+		ISO.FileUserData: []byte("data"),
+		ISO.FileMetaData: []byte{},
+	}
+	marshal := MarshalAt(ISO.VolumeIDCidata, testTime)
+
+	assert.Equal(t, marshal(files), marshal(files))
+}
+
+func TestPrimaryIdentifierUnique(t *testing.T) {
+	files, err := filesFromList(TA.FileList{
+		"user-data":   nil,
+		"user-data-1": nil,
+		"user-data-2": nil,
+	})
+	require.NoError(t, err)
+
+	ids := make(map[string]bool)
+	for _, f := range files {
+		assert.False(t, ids[f.primary], f.primary)
+		ids[f.primary] = true
+	}
+	assert.Contains(t, ids, "USER_DAT.;1")
+}
+
+func TestMarshalInvalidName(t *testing.T) {
+	marshal := MarshalAt(ISO.VolumeIDCidata, testTime)
+
+	assert.True(t, E.IsLeft(marshal(TA.FileList{"folder/user-data": nil})))
+	assert.True(t, E.IsLeft(marshal(TA.FileList{"": nil})))
+	assert.True(t, E.IsLeft(marshal(TA.FileList{strings.Repeat("a", ISO.MaxNameLength+1): nil})))
+}
+
+func TestMarshalInvalidVolumeID(t *testing.T) {
+	res := MarshalAt(strings.Repeat("v", ISO.MaxVolumeIDLength+1), testTime)(TA.FileList{})
+
+	assert.True(t, E.IsLeft(res))
+}
@@ -0,0 +1,33 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iso9660
+
+const (
+	// SectorSize is the size of a logical sector of an ISO9660 image
+	SectorSize = 2048
+	// MaxNameLength is the maximum number of characters of a file name, limited by the Joliet extension
+	MaxNameLength = 64
+	// MaxVolumeIDLength is the maximum number of characters of a volume identifier, limited by the Joliet extension
+	MaxVolumeIDLength = 16
+
+	// VolumeIDCidata is the volume identifier of a NoCloud seed image
+	VolumeIDCidata = "cidata"
+	// FileUserData is the name of the file on a NoCloud seed image that carries the contract
+	FileUserData = "user-data"
+	// FileMetaData is the name of the file on a NoCloud seed image that carries the instance meta data
+	FileMetaData = "meta-data"
+	// FileVendorData is the name of the file on a NoCloud seed image that carries the vendor data
+	FileVendorData = "vendor-data"
+)
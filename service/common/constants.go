@@ -5,6 +5,12 @@ const (
 	KeyEnv                  = "env"
 	KeyAttestationPublicKey = "attestationPublicKey"
 	KeyEnvWorkloadSignature = "envWorkloadSignature"
+
+	// keys of the compose and play sections of a workload
+	KeyCompose = "compose"
+	KeyPlay    = "play"
+	KeyArchive = "archive"
+	KeyFolder  = "folder"
 )
 
 type (
@@ -0,0 +1,42 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+type (
+	// TokenInspection describes the structure of a `hyper-protect-basic` token, without decrypting it
+	TokenInspection struct {
+		RsaCiphertextLength int    `json:"rsaCiphertextLength" yaml:"rsaCiphertextLength"` // length of the RSA encrypted password in bytes
+		KeySize             int    `json:"keySize" yaml:"keySize"`                         // size of the RSA key in bits, derived from the ciphertext length
+		Salted              bool   `json:"salted" yaml:"salted"`                           // true if the payload starts with the OpenSSL `Salted__` header
+		Salt                string `json:"salt,omitempty" yaml:"salt,omitempty"`           // hex encoded salt of the payload
+		PayloadSize         int    `json:"payloadSize" yaml:"payloadSize"`                 // size of the AES encrypted payload in bytes, excluding the header
+	}
+
+	// SectionInspection describes a top level key of an encrypted contract
+	SectionInspection struct {
+		Key       string           `json:"key" yaml:"key"`
+		Token     bool             `json:"token" yaml:"token"`                         // true if the value is a valid `hyper-protect-basic` token
+		Plaintext bool             `json:"plaintext" yaml:"plaintext"`                 // true if the value is not a token but is expected to be encrypted
+		Details   *TokenInspection `json:"details,omitempty" yaml:"details,omitempty"` // structure of the token
+		Problems  []string         `json:"problems,omitempty" yaml:"problems,omitempty"`
+	}
+
+	// ContractInspection describes an encrypted contract, without decrypting it
+	ContractInspection struct {
+		Sections          []SectionInspection `json:"sections" yaml:"sections"`
+		Signed            bool                `json:"signed" yaml:"signed"`                       // true if the contract carries an `envWorkloadSignature`
+		PlaintextSections []string            `json:"plaintextSections" yaml:"plaintextSections"` // keys of sections that are expected to be encrypted but are not
+	}
+)
@@ -0,0 +1,27 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"strings"
+
+	Common "github.com/ibm-hyper-protect/contract-go/common"
+)
+
+// IsToken tests if the value of a section is a `hyper-protect-basic` token
+func IsToken(value any) bool {
+	s, ok := value.(string)
+	return ok && strings.HasPrefix(s, Common.PrefixBasicEncoding+".")
+}
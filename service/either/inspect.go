@@ -0,0 +1,154 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	R "github.com/IBM/fp-go/record"
+	S "github.com/IBM/fp-go/string"
+	Common "github.com/ibm-hyper-protect/contract-go/common"
+	EC "github.com/ibm-hyper-protect/contract-go/encrypt/common"
+	C "github.com/ibm-hyper-protect/contract-go/service/common"
+	Types "github.com/ibm-hyper-protect/contract-go/types"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+)
+
+const (
+	// prefix of a `hyper-protect-basic` token
+	tokenPrefix = Common.PrefixBasicEncoding + "."
+	// header and salt length of a payload encrypted by `openssl enc`
+	saltedHeader = "Salted__"
+	saltLength   = 8
+)
+
+var (
+	// sections that may legitimately be plaintext, all other sections are expected to be encrypted
+	plaintextSections = map[string]bool{
+		C.KeyEnvWorkloadSignature: true,
+		C.KeyAttestationPublicKey: true,
+	}
+
+	// RSA key sizes in bits that are used with HPCR
+	knownKeySizes = map[int]bool{
+		2048: true,
+		3072: true,
+		4096: true,
+	}
+)
+
+// inspectPayload describes the AES encrypted payload of a token
+func inspectPayload(payload []byte, details *C.TokenInspection) []string {
+	var problems []string
+	if !bytes.HasPrefix(payload, []byte(saltedHeader)) || len(payload) < len(saltedHeader)+saltLength {
+		details.PayloadSize = len(payload)
+		problems = append(problems, fmt.Sprintf("the payload does not start with the [%s] header", saltedHeader))
+	} else {
+		details.Salted = true
+		details.Salt = hex.EncodeToString(payload[len(saltedHeader) : len(saltedHeader)+saltLength])
+		details.PayloadSize = len(payload) - len(saltedHeader) - saltLength
+	}
+	if details.PayloadSize%aes.BlockSize != 0 {
+		problems = append(problems, fmt.Sprintf("the payload size [%d] is not a multiple of the AES block size", details.PayloadSize))
+	}
+	return problems
+}
+
+// inspectToken describes the structure of a valid `hyper-protect-basic` token
+func inspectToken(token EC.SplitToken) (*C.TokenInspection, []string) {
+	var problems []string
+	details := &C.TokenInspection{}
+	// the password is RSA encrypted, so its length is the size of the key
+	pwd, err := E.UnwrapError(Common.Base64DecodeE(EC.GetPwd(token)))
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("the encrypted password is not base64 encoded: %v", err))
+	} else {
+		details.RsaCiphertextLength = len(pwd)
+		details.KeySize = 8 * len(pwd)
+		if !knownKeySizes[details.KeySize] {
+			problems = append(problems, fmt.Sprintf("the RSA ciphertext length [%d] does not match a common key size", len(pwd)))
+		}
+	}
+	payload, err := E.UnwrapError(Common.Base64DecodeE(EC.GetToken(token)))
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("the payload is not base64 encoded: %v", err))
+	} else {
+		problems = append(problems, inspectPayload(payload, details)...)
+	}
+	return details, problems
+}
+
+// inspectSection describes a single top level key of an encrypted contract
+func inspectSection(key, value string) C.SectionInspection {
+	section := C.SectionInspection{Key: key}
+	token, err := E.UnwrapError(EC.SplitHyperProtectToken(value))
+	if err != nil {
+		section.Plaintext = !plaintextSections[key]
+		if strings.HasPrefix(value, tokenPrefix) {
+			section.Problems = A.Of(fmt.Sprintf("the value looks like a token but is malformed: %v", err))
+		}
+		return section
+	}
+	section.Token = true
+	section.Details, section.Problems = inspectToken(token)
+	return section
+}
+
+// InspectContract describes the sections of an encrypted contract without decrypting it. It reports for each top level
+// key if it is a valid `hyper-protect-basic` token and the structure of that token, if the contract is signed and
+// which sections remain in plaintext.
+func InspectContract(contract C.EncryptedContract) *C.ContractInspection {
+	sections := R.CollectOrd[string, C.SectionInspection](S.Ord)(inspectSection)(contract)
+	_, signed := contract[C.KeyEnvWorkloadSignature]
+	return &C.ContractInspection{
+		Sections: sections,
+		Signed:   signed,
+		PlaintextSections: F.Pipe2(
+			sections,
+			A.Filter(func(section C.SectionInspection) bool {
+				return section.Plaintext
+			}),
+			A.Map(func(section C.SectionInspection) string {
+				return section.Key
+			}),
+		),
+	}
+}
+
+// sectionToString keeps string values and serializes all other values as YAML
+func sectionToString(value any) E.Either[error, string] {
+	if str, ok := value.(string); ok {
+		return E.Of[error](str)
+	}
+	return F.Pipe1(
+		Y.Stringify(value),
+		E.Map[error](func(data []byte) string {
+			return string(data)
+		}),
+	)
+}
+
+// EncryptedContractFromAnyMap converts a parsed contract into an [C.EncryptedContract]. Sections that are not strings,
+// e.g. plaintext sections of a partially encrypted contract, are serialized as YAML so they can be inspected.
+func EncryptedContractFromAnyMap(raw Types.AnyMap) E.Either[error, C.EncryptedContract] {
+	return E.TraverseRecord[string](sectionToString)(raw)
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	C "github.com/ibm-hyper-protect/contract-go/service/common"
+	Types "github.com/ibm-hyper-protect/contract-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = `hyper-protect-basic.UMs93kGaZrzYa6oeoYk8CyaCnsTtRPVdyT+zWBRKKaQD9H71G8bN3PQzbWVx/N84OeyorvERI9RVnpuWwlvnhXj5mu7KZdMXrPoLzW13/zB9HaKYLh64yV3fBsZbGkhlyyjW5n/dcoJ7zbAF5ZRe4m2unpsDUne2cLs27s1FD08oj7iWw/BrzNqqcyOayQnH1WUtHN2OhR4T3k+qSdj3XtnD6t+dsrxg9XFue0zciNQqxDfayBPiUWGpmtOKF2sc+Dp4cq9bV8SsF1crs3dXBsWc21Zl7nVcwt3bmQET++rBdgwI9TZDMa7gjB9Iu/JbjgbPHuBdIycWJMfIH4mseAH6r+HFg5Wq2t/s3FrWg5qdkwCWjzT3r5OoMOafiG06U0SFp29mND1t0kVypf3nEQJQjb6+WoIGcDvKzvUMz5NcRFi8zubziXg0wAJoSZWFL+/gXiDyg9ZbfR8/Ukx52CVLTYGW/IATChfIw51c57b2EddKT3aS/ZksZpyLfLdiLRxLn6X/lEmVGCUojAhmgiFQZzEjeREAV9HMNRnymiyq+qtK+zSMsfZMMdhesHalaRqK9ORqUgBaYII+AG7sWC1xS0FD5LNtN739SjY18/NAY0OznQWI8Yvfu0BoMRSVNIrZl4QWYHdmNHywSfkktc/Bk6qlkgTy392RbfgbcPw=.U2FsdGVkX1/DbyZBRupGSoukxfU91ywFu5HTUsqs8+LLU+MkGP3PJY1XxwaioHoq`
+
+func TestInspectContract(t *testing.T) {
+	res := InspectContract(C.EncryptedContract{
+		C.KeyWorkload:             testToken,
+		C.KeyEnv:                  "type: env\n",
+		C.KeyEnvWorkloadSignature: "c2lnbmF0dXJl",
+	})
+
+	assert.True(t, res.Signed)
+	assert.Equal(t, []string{C.KeyEnv}, res.PlaintextSections)
+	require.Len(t, res.Sections, 3)
+
+	// sections are sorted by key
+	env, signature, workload := res.Sections[0], res.Sections[1], res.Sections[2]
+
+	assert.Equal(t, C.KeyEnv, env.Key)
+	assert.False(t, env.Token)
+	assert.True(t, env.Plaintext)
+	assert.Empty(t, env.Problems)
+
+	assert.Equal(t, C.KeyEnvWorkloadSignature, signature.Key)
+	assert.False(t, signature.Token)
+	assert.False(t, signature.Plaintext)
+
+	assert.Equal(t, C.KeyWorkload, workload.Key)
+	assert.True(t, workload.Token)
+	assert.Empty(t, workload.Problems)
+	require.NotNil(t, workload.Details)
+	assert.Equal(t, 512, workload.Details.RsaCiphertextLength)
+	assert.Equal(t, 4096, workload.Details.KeySize)
+	assert.True(t, workload.Details.Salted)
+	assert.Len(t, workload.Details.Salt, 16)
+	assert.Equal(t, 32, workload.Details.PayloadSize)
+}
+
+func TestInspectMalformedToken(t *testing.T) {
+	res := InspectContract(C.EncryptedContract{
+		C.KeyEnv: "hyper-protect-basic.not a token",
+	})
+
+	assert.False(t, res.Signed)
+	require.Len(t, res.Sections, 1)
+	assert.False(t, res.Sections[0].Token)
+	assert.True(t, res.Sections[0].Plaintext)
+	assert.NotEmpty(t, res.Sections[0].Problems)
+}
+
+func TestInspectUnsaltedPayload(t *testing.T) {
+	res := InspectContract(C.EncryptedContract{
+		C.KeyEnv: "hyper-protect-basic.AAAA.AAAA",
+	})
+
+	require.Len(t, res.Sections, 1)
+	assert.True(t, res.Sections[0].Token)
+	assert.False(t, res.Sections[0].Details.Salted)
+	assert.NotEmpty(t, res.Sections[0].Problems)
+}
+
+func TestEncryptedContractFromAnyMap(t *testing.T) {
+	res, err := E.UnwrapError(EncryptedContractFromAnyMap(Types.AnyMap{
+		C.KeyWorkload: testToken,
+		C.KeyEnv:      Types.AnyMap{"type": "env"},
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, testToken, res[C.KeyWorkload])
+	assert.Equal(t, "type: env\n", res[C.KeyEnv])
+}
@@ -0,0 +1,40 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package either
+
+import (
+	E "github.com/IBM/fp-go/either"
+	"github.com/IBM/fp-go/errors"
+	F "github.com/IBM/fp-go/function"
+	L "github.com/IBM/fp-go/optics/lens"
+	O "github.com/IBM/fp-go/option"
+	S "github.com/IBM/fp-go/string"
+	T "github.com/ibm-hyper-protect/contract-go/types"
+)
+
+var (
+	// signingKeyFromContract focuses on the public signing key in the env section of a contract
+	signingKeyFromContract = F.Pipe1(
+		T.OpticContract.Env,
+		L.ComposeOptions[*T.Contract, string](T.MonoidContract.Env.Empty())(T.OpticEnv.SigningKey),
+	)
+
+	// SigningKeyFromContract extracts the public signing key from the env section of a plaintext contract
+	SigningKeyFromContract = F.Flow3(
+		signingKeyFromContract.Get,
+		O.Map(S.ToBytes),
+		E.FromOption[[]byte](errors.OnNone("the env section of the contract does not contain a signing key")),
+	)
+)
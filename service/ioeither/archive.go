@@ -0,0 +1,101 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	R "github.com/IBM/fp-go/record"
+	AIOE "github.com/ibm-hyper-protect/contract-go/archive/ioeither"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	T "github.com/ibm-hyper-protect/contract-go/types"
+)
+
+// resolveFolder resolves a folder relative to the base directory and makes sure it exists
+func resolveFolder(baseDir, folder string) IOE.IOEither[error, string] {
+	return IOE.TryCatchError(func() (string, error) {
+		if !filepath.IsAbs(folder) {
+			folder = filepath.Join(baseDir, folder)
+		}
+		status, err := os.Stat(folder)
+		if err != nil {
+			return folder, err
+		}
+		if !status.IsDir() {
+			return folder, fmt.Errorf("[%s] is not a folder", folder)
+		}
+		return folder, nil
+	})
+}
+
+// inlineSection replaces the `folder` of the given section of a workload by the archive of that folder
+func inlineSection(baseDir, key string) func(T.AnyMap) IOE.IOEither[error, T.AnyMap] {
+	return func(workload T.AnyMap) IOE.IOEither[error, T.AnyMap] {
+		section, ok := workload[key].(T.AnyMap)
+		if !ok {
+			return IOE.Of[error](workload)
+		}
+		folder, ok := section[SC.KeyFolder].(string)
+		if !ok {
+			return IOE.Of[error](workload)
+		}
+		if _, ok := section[SC.KeyArchive]; ok {
+			return IOE.Left[T.AnyMap](fmt.Errorf("[%s.%s] must not specify both [%s] and [%s]", SC.KeyWorkload, key, SC.KeyFolder, SC.KeyArchive))
+		}
+		return F.Pipe2(
+			resolveFolder(baseDir, folder),
+			IOE.Chain(AIOE.Base64TarFolder),
+			IOE.Map[error](func(archive string) T.AnyMap {
+				inlined := R.Copy(section)
+				delete(inlined, SC.KeyFolder)
+				inlined[SC.KeyArchive] = archive
+
+				res := R.Copy(workload)
+				res[key] = inlined
+				return res
+			}),
+		)
+	}
+}
+
+// InlineArchives replaces the `folder` fields of the `compose` and `play` sections of a plaintext workload by the
+// base64 encoded tgz archive of that folder, so the contract can be validated and encrypted as usual
+//
+// - baseDir is the folder relative folders are resolved against, typically the folder of the contract file
+func InlineArchives(baseDir string) func(T.AnyMap) IOE.IOEither[error, T.AnyMap] {
+	inlineCompose := inlineSection(baseDir, SC.KeyCompose)
+	inlinePlay := inlineSection(baseDir, SC.KeyPlay)
+
+	return func(contract T.AnyMap) IOE.IOEither[error, T.AnyMap] {
+		workload, ok := contract[SC.KeyWorkload].(T.AnyMap)
+		if !ok {
+			return IOE.Of[error](contract)
+		}
+		return F.Pipe3(
+			workload,
+			inlineCompose,
+			IOE.Chain(inlinePlay),
+			IOE.Map[error](func(inlined T.AnyMap) T.AnyMap {
+				res := R.Copy(contract)
+				res[SC.KeyWorkload] = inlined
+				return res
+			}),
+		)
+	}
+}
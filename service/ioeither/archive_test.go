@@ -0,0 +1,71 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	T "github.com/ibm-hyper-protect/contract-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInlineArchives(t *testing.T) {
+	contract := T.AnyMap{
+		SC.KeyWorkload: T.AnyMap{
+			"type": "workload",
+			SC.KeyPlay: T.AnyMap{
+				SC.KeyFolder: "hello-world",
+			},
+		},
+	}
+
+	inlined, err := E.UnwrapError(InlineArchives("../../samples")(contract)())
+	require.NoError(t, err)
+
+	play := inlined[SC.KeyWorkload].(T.AnyMap)[SC.KeyPlay].(T.AnyMap)
+	assert.NotContains(t, play, SC.KeyFolder)
+	assert.NotEmpty(t, play[SC.KeyArchive])
+
+	// the original contract is unchanged
+	assert.Contains(t, contract[SC.KeyWorkload].(T.AnyMap)[SC.KeyPlay], SC.KeyFolder)
+
+	// the result validates against the schema
+	_, err = E.UnwrapError(T.ValidateContract(inlined))
+	assert.NoError(t, err)
+}
+
+func TestInlineArchivesErrors(t *testing.T) {
+	missing := T.AnyMap{
+		SC.KeyWorkload: T.AnyMap{
+			SC.KeyCompose: T.AnyMap{
+				SC.KeyFolder: "does-not-exist",
+			},
+		},
+	}
+	assert.True(t, E.IsLeft(InlineArchives("../../samples")(missing)()))
+
+	both := T.AnyMap{
+		SC.KeyWorkload: T.AnyMap{
+			SC.KeyCompose: T.AnyMap{
+				SC.KeyFolder:  "hello-world",
+				SC.KeyArchive: "MA==",
+			},
+		},
+	}
+	assert.True(t, E.IsLeft(InlineArchives("../../samples")(both)()))
+}
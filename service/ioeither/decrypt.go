@@ -0,0 +1,73 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	B "github.com/IBM/fp-go/bytes"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	EC "github.com/ibm-hyper-protect/contract-go/encrypt/common"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	T "github.com/ibm-hyper-protect/contract-go/types"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+)
+
+type (
+	// ContractDecrypter is the type of a function that takes an encrypted contract and that decrypts it
+	ContractDecrypter = func(ctr SC.EncryptedContract) IOE.IOEither[error, T.AnyMap]
+)
+
+// parseSection converts the decrypted content of a section into its plaintext representation. The workload and env
+// sections are YAML documents, all other sections are kept as strings
+func parseSection(key string) func([]byte) E.Either[error, any] {
+	switch key {
+	case SC.KeyWorkload, SC.KeyEnv:
+		return F.Flow2(
+			Y.Parse[T.AnyMap],
+			E.Map[error](F.ToAny[T.AnyMap]),
+		)
+	default:
+		return F.Flow3(
+			B.ToString,
+			F.ToAny[string],
+			E.Of[error, any],
+		)
+	}
+}
+
+// decryptSection returns a function that decrypts a single section of an encrypted contract. Values that are not
+// hyper protect tokens (e.g. the `envWorkloadSignature`) are returned unchanged
+func decryptSection(dec func(string) IOE.IOEither[error, []byte]) func(key, value string) IOE.IOEither[error, any] {
+	return func(key, value string) IOE.IOEither[error, any] {
+		if !EC.IsHyperProtectBasic(value) {
+			return IOE.Of[error, any](value)
+		}
+		return F.Pipe2(
+			value,
+			dec,
+			IOE.ChainEitherK(parseSection(key)),
+		)
+	}
+}
+
+// DecryptContract returns a function that decrypts all `hyper-protect-basic` tokens in an encrypted contract
+//
+// - decBasic decrypts a token given the private key
+func DecryptContract(decBasic func(privKey []byte) func(string) IOE.IOEither[error, []byte]) func(privKey []byte) ContractDecrypter {
+	return func(privKey []byte) ContractDecrypter {
+		return IOE.TraverseRecordWithIndex[string](decryptSection(decBasic(privKey)))
+	}
+}
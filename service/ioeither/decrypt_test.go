@@ -0,0 +1,86 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	I "github.com/IBM/fp-go/identity"
+	IOE "github.com/IBM/fp-go/ioeither"
+	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	T "github.com/ibm-hyper-protect/contract-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecryptContract(t *testing.T) {
+	// let's create a contract from scratch
+	attestationKey := "attestation key"
+	contract := &T.Contract{
+		Env: &T.Env{
+			Type: "env",
+			Logging: &T.Logging{
+				LogDNA: &T.LogDNA{
+					IngestionKey: "key",
+					Hostname:     "example.com",
+				},
+			},
+		},
+		Workload: &T.Workload{
+			Type: "workload",
+		},
+		AttestationPublicKey: &attestationKey,
+	}
+
+	// encrypt and sign with the public key of the test key pair
+	encryptedIOE := F.Pipe3(
+		pubKeyE,
+		E.Map[error](func(pubKey []byte) func(privKey []byte) ContractEncrypter {
+			return EncryptAndSignContract(Encrypt.CryptoEncryptBasic(pubKey), Encrypt.CryptoSignDigest, Encrypt.CryptoPublicKey)
+		}),
+		E.Ap[ContractEncrypter](privKeyE),
+		IOE.FromEither[error, ContractEncrypter],
+	)
+
+	// decrypt with the private key of the test key pair
+	decrypter := F.Pipe1(
+		privKeyE,
+		E.Map[error](DecryptContract(Encrypt.CryptoDecryptBasic)),
+	)
+
+	decryptedIOE := F.Pipe2(
+		encryptedIOE,
+		IOE.Chain(I.Ap[IOE.IOEither[error, SC.EncryptedContract]](contract)),
+		IOE.Chain(func(enc SC.EncryptedContract) IOE.IOEither[error, T.AnyMap] {
+			return F.Pipe2(
+				decrypter,
+				IOE.FromEither[error, ContractDecrypter],
+				IOE.Chain(I.Ap[IOE.IOEither[error, T.AnyMap]](enc)),
+			)
+		}),
+	)
+
+	decrypted, err := E.UnwrapError(decryptedIOE())
+	require.NoError(t, err)
+
+	assert.Equal(t, "workload", decrypted[SC.KeyWorkload].(T.AnyMap)["type"])
+	assert.Equal(t, "env", decrypted[SC.KeyEnv].(T.AnyMap)["type"])
+	assert.Contains(t, decrypted[SC.KeyEnv], "signingKey")
+	assert.Equal(t, attestationKey, decrypted[SC.KeyAttestationPublicKey])
+	assert.Contains(t, decrypted, SC.KeyEnvWorkloadSignature)
+}
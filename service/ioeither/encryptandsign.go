@@ -38,6 +38,18 @@ var (
 	getSigningKey = R.Lookup[any](Contract.KeySigningKey)
 )
 
+// envWorkloadData returns the data that is covered by the `envWorkloadSignature`, i.e. the concatenation of the encrypted workload and env sections
+func envWorkloadData(contract SC.EncryptedContract) E.Either[error, []byte] {
+	return F.Pipe3(
+		O.SequenceT2(getWorkload(contract), getEnv(contract)),
+		O.Map(T.Tupled2(S.Monoid.Concat)),
+		O.Map(S.ToBytes),
+		E.FromOption[[]byte](func() error {
+			return fmt.Errorf("the contract is missing [%s] or [%s] or both", Contract.KeyEnv, Contract.KeyWorkload)
+		}),
+	)
+}
+
 // upsertPubKeyIntoEnv adds the public signing key to the env section of the contract
 // TODO write using optics
 func upsertPubKeyIntoEnv(pubKey []byte) func(ctr *Types.Env) *Types.Env {
@@ -87,19 +99,12 @@ func createEnvWorkloadSignature(signer func([]byte) func([]byte) IOE.IOEither[er
 		// callback to construct the digest
 		sign := signer(privKey)
 		// combine into a digest
-		return func(contract SC.EncryptedContract) IOE.IOEither[error, string] {
-			// lookup the
-			return F.Pipe5(
-				O.SequenceT2(getWorkload(contract), getEnv(contract)),
-				O.Map(T.Tupled2(S.Monoid.Concat)),
-				O.Map(S.ToBytes),
-				IOE.FromOption[[]byte](func() error {
-					return fmt.Errorf("the contract is missing [%s] or [%s] or both", Contract.KeyEnv, Contract.KeyWorkload)
-				}),
-				IOE.Chain(sign),
-				IOE.Map[error](Common.Base64Encode),
-			)
-		}
+		return F.Flow4(
+			envWorkloadData,
+			IOE.FromEither[error, []byte],
+			IOE.Chain(sign),
+			IOE.Map[error](Common.Base64Encode),
+		)
 	}
 }
 
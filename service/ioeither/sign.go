@@ -0,0 +1,162 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"fmt"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	O "github.com/IBM/fp-go/option"
+	R "github.com/IBM/fp-go/record"
+	S "github.com/IBM/fp-go/string"
+	T "github.com/IBM/fp-go/tuple"
+	Contract "github.com/ibm-hyper-protect/contract-go/contract"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	Types "github.com/ibm-hyper-protect/contract-go/types"
+)
+
+// noSigningKey leaves the contract unchanged
+var noSigningKey = E.Of[error](F.Identity[*Types.Contract])
+
+// selectSection returns the part of a contract that is encrypted as the given section. The `attestationPublicKey` is
+// provided by the deployer, so it goes with the `env` section
+func selectSection(section string) func(ctr *Types.Contract) E.Either[error, *Types.Contract] {
+	return func(ctr *Types.Contract) E.Either[error, *Types.Contract] {
+		switch {
+		case section == Contract.KeyWorkload && ctr.Workload != nil:
+			return E.Of[error](&Types.Contract{Workload: ctr.Workload})
+		case section == Contract.KeyEnv && ctr.Env != nil:
+			return E.Of[error](&Types.Contract{Env: ctr.Env, AttestationPublicKey: ctr.AttestationPublicKey})
+		case section == Contract.KeyWorkload || section == Contract.KeyEnv:
+			return E.Left[*Types.Contract](fmt.Errorf("the contract is missing the [%s] section", section))
+		}
+		return E.Left[*Types.Contract](fmt.Errorf("unable to encrypt section [%s], expected [%s] or [%s]", section, Contract.KeyWorkload, Contract.KeyEnv))
+	}
+}
+
+// EncryptContractSection returns a function that encrypts a single section of a contract, so workload provider and
+// deployer can encrypt their parts independently. If a private signing key is given, its public key is inserted into
+// the `env` section, so [SignContract] can sign the encrypted env later on.
+//
+// - enc encrypts a piece of data
+// - pubKey extracts the public key from the private key
+func EncryptContractSection(
+	enc func(data []byte) IOE.IOEither[error, string],
+	pubKey func([]byte) E.Either[error, []byte],
+) func(section string) func(privKey O.Option[[]byte]) ContractEncrypter {
+	encStrg := F.Flow2(
+		S.ToBytes,
+		enc,
+	)
+	return func(section string) func(privKey O.Option[[]byte]) ContractEncrypter {
+		sel := selectSection(section)
+		return func(privKey O.Option[[]byte]) ContractEncrypter {
+			// insert the public key into the env, if any
+			addSigningKey := noSigningKey
+			if section == Contract.KeyEnv {
+				addSigningKey = F.Pipe1(
+					privKey,
+					O.Fold(F.Constant(noSigningKey), F.Flow2(
+						pubKey,
+						E.Map[error](upsertPubKey),
+					)),
+				)
+			}
+			return func(ctr *Types.Contract) IOE.IOEither[error, SC.EncryptedContract] {
+				return F.Pipe3(
+					E.SequenceT2(addSigningKey, sel(ctr)),
+					E.Map[error](T.Tupled2(applySigningKey)),
+					IOE.FromEither[error, SC.EncryptedContract],
+					IOE.Chain(IOE.TraverseRecord[string](encStrg)),
+				)
+			}
+		}
+	}
+}
+
+// applySigningKey inserts the signing key into the contract and serializes its sections
+func applySigningKey(addKey func(*Types.Contract) *Types.Contract, ctr *Types.Contract) SC.EncryptedContract {
+	return SC.SerializeContract(addKey(ctr))
+}
+
+// splitSections separates the sections that are already encrypted from the plaintext sections, an existing signature
+// is dropped since it is recomputed
+func splitSections(raw Types.AnyMap) (SC.EncryptedContract, Types.AnyMap) {
+	encrypted, plain := make(SC.EncryptedContract), make(Types.AnyMap)
+	for key, value := range raw {
+		switch {
+		case key == Contract.KeyEnvWorkloadSignature:
+		case SC.IsToken(value):
+			encrypted[key] = value.(string)
+		default:
+			plain[key] = value
+		}
+	}
+	return encrypted, plain
+}
+
+// SignContract returns a function that signs a contract assembled from separately encrypted sections. The workload
+// must already be encrypted, so the signer never sees its plaintext. A plaintext env is encrypted after the public
+// signing key has been inserted, an encrypted env must already carry the public key of the signing key, see
+// [EncryptContractSection]. The `envWorkloadSignature` is computed over the encrypted sections.
+//
+// - enc encrypts a piece of data
+// - signer signs a piece of data
+// - pubKey extracts the public key from the private key
+func SignContract(
+	enc func(data []byte) IOE.IOEither[error, string],
+	signer func([]byte) func([]byte) IOE.IOEither[error, []byte],
+	pubKey func([]byte) E.Either[error, []byte],
+) func(privKey []byte) func(ctr Types.AnyMap) IOE.IOEither[error, SC.EncryptedContract] {
+	encStrg := F.Flow2(
+		S.ToBytes,
+		enc,
+	)
+	upsertSig := upsertEnvWorkloadSignature(enc, signer)
+	return func(privKey []byte) func(ctr Types.AnyMap) IOE.IOEither[error, SC.EncryptedContract] {
+		addSigningKey := F.Pipe1(
+			pubKey(privKey),
+			E.Map[error](upsertPubKey),
+		)
+		addSignature := upsertSig(privKey)
+
+		return func(ctr Types.AnyMap) IOE.IOEither[error, SC.EncryptedContract] {
+			encrypted, plain := splitSections(ctr)
+			if _, ok := plain[Contract.KeyWorkload]; ok {
+				return IOE.Left[SC.EncryptedContract](fmt.Errorf("the [%s] section must be encrypted before signing, use encrypt with the [%s] section", Contract.KeyWorkload, Contract.KeyWorkload))
+			}
+			// insert the signing key only if the env is in plaintext
+			addKey := noSigningKey
+			if _, ok := plain[Contract.KeyEnv]; ok {
+				addKey = addSigningKey
+			}
+			return F.Pipe4(
+				E.SequenceT2(addKey, F.Pipe1(
+					Types.ParseContractSections(plain),
+					E.Chain(Types.ValidateContract),
+				)),
+				E.Map[error](T.Tupled2(applySigningKey)),
+				IOE.FromEither[error, SC.EncryptedContract],
+				IOE.Chain(IOE.TraverseRecord[string](encStrg)),
+				IOE.Chain(F.Flow2(
+					F.Bind2nd(R.UnionLastMonoid[string, string]().Concat, encrypted),
+					addSignature,
+				)),
+			)
+		}
+	}
+}
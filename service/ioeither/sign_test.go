@@ -0,0 +1,103 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"testing"
+
+	B "github.com/IBM/fp-go/bytes"
+	E "github.com/IBM/fp-go/either"
+	O "github.com/IBM/fp-go/option"
+	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	T "github.com/ibm-hyper-protect/contract-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignSeparatelyEncryptedContract(t *testing.T) {
+	contract := &T.Contract{
+		Env: &T.Env{
+			Type: "env",
+		},
+		Workload: &T.Workload{
+			Type: "workload",
+		},
+	}
+
+	privKey, err := E.UnwrapError(privKeyE)
+	require.NoError(t, err)
+	pubKey, err := E.UnwrapError(pubKeyE)
+	require.NoError(t, err)
+
+	// the public key doubles as the encryption key
+	enc := Encrypt.CryptoEncryptBasic(pubKey)
+	encryptSection := EncryptContractSection(enc, Encrypt.CryptoPublicKey)
+	sign := SignContract(enc, Encrypt.CryptoSignDigest, Encrypt.CryptoPublicKey)(privKey)
+	verify := VerifyContract(Encrypt.CryptoVerifyDigest)(pubKey)
+	decrypt := DecryptContract(Encrypt.CryptoDecryptBasic)(privKey)
+
+	// the workload provider encrypts the workload only
+	workload, err := E.UnwrapError(encryptSection(SC.KeyWorkload)(O.None[[]byte]())(contract)())
+	require.NoError(t, err)
+	assert.Len(t, workload, 1)
+	assert.Contains(t, workload, SC.KeyWorkload)
+
+	t.Run("encrypted env", func(t *testing.T) {
+		env, err := E.UnwrapError(encryptSection(SC.KeyEnv)(O.Of(privKey))(contract)())
+		require.NoError(t, err)
+		assert.Len(t, env, 1)
+
+		signed, err := E.UnwrapError(sign(T.AnyMap{
+			SC.KeyWorkload: workload[SC.KeyWorkload],
+			SC.KeyEnv:      env[SC.KeyEnv],
+		})())
+		require.NoError(t, err)
+
+		// the encrypted sections are kept as they are
+		assert.Equal(t, workload[SC.KeyWorkload], signed[SC.KeyWorkload])
+		assert.Equal(t, env[SC.KeyEnv], signed[SC.KeyEnv])
+		assert.Equal(t, E.Of[error](signed), verify(signed)())
+
+		decrypted, err := E.UnwrapError(decrypt(signed)())
+		require.NoError(t, err)
+		assert.Equal(t, B.ToString(pubKey), decrypted[SC.KeyEnv].(T.AnyMap)["signingKey"])
+	})
+
+	t.Run("plaintext env", func(t *testing.T) {
+		signed, err := E.UnwrapError(sign(T.AnyMap{
+			SC.KeyWorkload: workload[SC.KeyWorkload],
+			SC.KeyEnv:      T.AnyMap{"type": "env", "logging": T.AnyMap{}},
+		})())
+		require.NoError(t, err)
+		assert.Equal(t, E.Of[error](signed), verify(signed)())
+
+		decrypted, err := E.UnwrapError(decrypt(signed)())
+		require.NoError(t, err)
+		assert.Equal(t, B.ToString(pubKey), decrypted[SC.KeyEnv].(T.AnyMap)["signingKey"])
+	})
+
+	t.Run("plaintext workload", func(t *testing.T) {
+		assert.True(t, E.IsLeft(sign(T.AnyMap{
+			SC.KeyWorkload: T.AnyMap{"type": "workload"},
+			SC.KeyEnv:      T.AnyMap{"type": "env", "logging": T.AnyMap{}},
+		})()))
+	})
+
+	t.Run("invalid sections", func(t *testing.T) {
+		assert.True(t, E.IsLeft(encryptSection("unknown")(O.None[[]byte]())(contract)()))
+		assert.True(t, E.IsLeft(encryptSection(SC.KeyEnv)(O.None[[]byte]())(&T.Contract{Workload: contract.Workload})()))
+	})
+}
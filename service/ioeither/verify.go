@@ -0,0 +1,74 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	E "github.com/IBM/fp-go/either"
+	"github.com/IBM/fp-go/errors"
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	IOO "github.com/IBM/fp-go/iooption"
+	R "github.com/IBM/fp-go/record"
+	T "github.com/IBM/fp-go/tuple"
+	Common "github.com/ibm-hyper-protect/contract-go/common"
+	Contract "github.com/ibm-hyper-protect/contract-go/contract"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+)
+
+type (
+	// ContractVerifier is the type of a function that verifies the signature of an encrypted contract
+	ContractVerifier = func(ctr SC.EncryptedContract) IOE.IOEither[error, SC.EncryptedContract]
+)
+
+var (
+	getEnvWorkloadSignature = R.Lookup[string](Contract.KeyEnvWorkloadSignature)
+
+	// envWorkloadSignature decodes the `envWorkloadSignature` of an encrypted contract
+	envWorkloadSignature = F.Flow3(
+		getEnvWorkloadSignature,
+		E.FromOption[string](errors.OnNone("the contract is missing [%s]", Contract.KeyEnvWorkloadSignature)),
+		E.Chain(Common.Base64DecodeE),
+	)
+
+	// onInvalidSignature decorates the error of a failed validation
+	onInvalidSignature = errors.OnError("the [%s] of the contract is invalid", Contract.KeyEnvWorkloadSignature)
+)
+
+// VerifyContract returns a function that verifies the `envWorkloadSignature` of an encrypted contract. The digest is computed
+// across the encrypted workload and env sections, in the same way as HPCR does it.
+//
+// - verifier verifies the sha256 signature of a piece of data given the public key
+func VerifyContract(verifier func([]byte) func([]byte) func([]byte) IOO.IOOption[error]) func(pubKey []byte) ContractVerifier {
+	return func(pubKey []byte) ContractVerifier {
+		// callback to validate the digest
+		verify := verifier(pubKey)
+
+		return func(contract SC.EncryptedContract) IOE.IOEither[error, SC.EncryptedContract] {
+			return F.Pipe3(
+				E.SequenceT2(envWorkloadData(contract), envWorkloadSignature(contract)),
+				E.Map[error](T.Tupled2(func(data, signature []byte) IOO.IOOption[error] {
+					return verify(data)(signature)
+				})),
+				IOE.FromEither[error, IOO.IOOption[error]],
+				IOE.Chain(F.Flow3(
+					// a validation error fails the verification, its absence passes it
+					IOE.FromIOOption[error](F.Constant(contract)),
+					IOE.Swap[SC.EncryptedContract, error],
+					IOE.MapLeft[SC.EncryptedContract](onInvalidSignature),
+				)),
+			)
+		}
+	}
+}
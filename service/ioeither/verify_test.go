@@ -0,0 +1,78 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ioeither
+
+import (
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	I "github.com/IBM/fp-go/identity"
+	IOE "github.com/IBM/fp-go/ioeither"
+	R "github.com/IBM/fp-go/record"
+	Encrypt "github.com/ibm-hyper-protect/contract-go/encrypt/ioeither"
+	SC "github.com/ibm-hyper-protect/contract-go/service/common"
+	T "github.com/ibm-hyper-protect/contract-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyContract(t *testing.T) {
+	// let's create a contract from scratch
+	contract := &T.Contract{
+		Env: &T.Env{
+			Type: "env",
+		},
+		Workload: &T.Workload{
+			Type: "workload",
+		},
+	}
+
+	// encrypt and sign with the test key pair, the public key doubles as the encryption key
+	encryptedIOE := F.Pipe4(
+		pubKeyE,
+		E.Map[error](func(pubKey []byte) func(privKey []byte) ContractEncrypter {
+			return EncryptAndSignContract(Encrypt.CryptoEncryptBasic(pubKey), Encrypt.CryptoSignDigest, Encrypt.CryptoPublicKey)
+		}),
+		E.Ap[ContractEncrypter](privKeyE),
+		IOE.FromEither[error, ContractEncrypter],
+		IOE.Chain(I.Ap[IOE.IOEither[error, SC.EncryptedContract]](contract)),
+	)
+
+	encrypted, err := E.UnwrapError(encryptedIOE())
+	require.NoError(t, err)
+
+	pubKey, err := E.UnwrapError(pubKeyE)
+	require.NoError(t, err)
+
+	// tamper with the env section by swapping in the workload
+	tampered := F.Pipe1(
+		encrypted,
+		R.UpsertAt(SC.KeyEnv, encrypted[SC.KeyWorkload]),
+	)
+
+	for name, verifier := range map[string]Encrypt.VerifyDigestFunc{
+		"crypto":  Encrypt.CryptoVerifyDigest,
+		"openssl": Encrypt.OpenSSLVerifyDigest,
+	} {
+		t.Run(name, func(t *testing.T) {
+			verify := VerifyContract(verifier)(pubKey)
+
+			assert.Equal(t, E.Of[error](encrypted), verify(encrypted)())
+			assert.True(t, E.IsLeft(verify(tampered)()))
+			assert.True(t, E.IsLeft(verify(R.DeleteAt[string, string](SC.KeyEnvWorkloadSignature)(encrypted))()))
+		})
+	}
+}
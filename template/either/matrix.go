@@ -0,0 +1,82 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package datasource
+
+package either
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	T "github.com/ibm-hyper-protect/contract-go/template"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+)
+
+// checkHeader validates the column names of a CSV matrix
+func checkHeader(header []string) E.Either[error, []string] {
+	seen := make(map[string]bool)
+	for idx, name := range header {
+		if name == "" {
+			return E.Left[[]string](fmt.Errorf("column %d of the matrix has no name", idx+1))
+		}
+		if seen[name] {
+			return E.Left[[]string](fmt.Errorf("column [%s] of the matrix is not unique", name))
+		}
+		seen[name] = true
+	}
+	return E.Of[error](header)
+}
+
+// ParseMatrixCSV parses a CSV matrix, the first record names the variables and every further record is one row
+func ParseMatrixCSV(data []byte) E.Either[error, T.Matrix] {
+	return F.Pipe1(
+		E.TryCatchError(csv.NewReader(bytes.NewReader(data)).ReadAll()),
+		E.Chain(func(records [][]string) E.Either[error, T.Matrix] {
+			if len(records) < 2 {
+				return E.Left[T.Matrix](fmt.Errorf("the matrix needs a header and at least one row"))
+			}
+			return F.Pipe1(
+				checkHeader(records[0]),
+				E.Map[error](func(header []string) T.Matrix {
+					return F.Pipe1(
+						records[1:],
+						A.Map(func(record []string) T.Vars {
+							row := make(T.Vars)
+							for idx, name := range header {
+								row[name] = record[idx]
+							}
+							return row
+						}),
+					)
+				}),
+			)
+		}),
+	)
+}
+
+// ParseMatrix parses a YAML or JSON matrix, a list of maps of scalar values
+func ParseMatrix(data []byte) E.Either[error, T.Matrix] {
+	return F.Pipe1(
+		Y.Parse[[]map[string]any](data),
+		E.Chain(func(rows []map[string]any) E.Either[error, T.Matrix] {
+			if A.IsEmpty(rows) {
+				return E.Left[T.Matrix](fmt.Errorf("the matrix needs at least one row"))
+			}
+			return E.TraverseArray(VarsFromMap)(rows)
+		}),
+	)
+}
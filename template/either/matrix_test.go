@@ -0,0 +1,51 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package datasource
+
+package either
+
+import (
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	T "github.com/ibm-hyper-protect/contract-go/template"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMatrixCSV(t *testing.T) {
+	matrix := ParseMatrixCSV([]byte("name,LOG_HOSTNAME\nacme,logs.acme.com\nglobex,\"logs.globex.com\"\n"))
+	assert.Equal(t, E.Of[error](T.Matrix{
+		{"name": "acme", "LOG_HOSTNAME": "logs.acme.com"},
+		{"name": "globex", "LOG_HOSTNAME": "logs.globex.com"},
+	}), matrix)
+
+	// rows must match the header
+	assert.True(t, E.IsLeft(ParseMatrixCSV([]byte("name,LOG_HOSTNAME\nacme\n"))))
+	// columns must be named and unique
+	assert.True(t, E.IsLeft(ParseMatrixCSV([]byte("name,\nacme,x\n"))))
+	assert.True(t, E.IsLeft(ParseMatrixCSV([]byte("name,name\nacme,x\n"))))
+	// a header alone is not a matrix
+	assert.True(t, E.IsLeft(ParseMatrixCSV([]byte("name\n"))))
+}
+
+func TestParseMatrix(t *testing.T) {
+	matrix := ParseMatrix([]byte(`[{"name": "acme", "LOG_PORT": 6514}, {"name": "globex"}]`))
+	assert.Equal(t, E.Of[error](T.Matrix{
+		{"name": "acme", "LOG_PORT": "6514"},
+		{"name": "globex"},
+	}), matrix)
+
+	// values must be scalars
+	assert.True(t, E.IsLeft(ParseMatrix([]byte("- name: acme\n  env:\n    A: B\n"))))
+	assert.True(t, E.IsLeft(ParseMatrix([]byte("[]"))))
+}
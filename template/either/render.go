@@ -0,0 +1,385 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package datasource
+
+package either
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	TT "text/template"
+	"text/template/parse"
+
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	R "github.com/IBM/fp-go/record"
+	T "github.com/ibm-hyper-protect/contract-go/template"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// header is the leading document of a template that declares its parameters
+	header struct {
+		Parameters T.Parameters `yaml:"parameters"`
+	}
+
+	// syntax describes how placeholders are found and resolved
+	syntax struct {
+		placeholders func(body []byte) E.Either[error, []string]
+		render       func(vars T.Vars) func(body []byte) E.Either[error, []byte]
+	}
+)
+
+var (
+	// shellPlaceholder matches `${NAME}` placeholders and the `$$` escape
+	shellPlaceholder = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+	// separator matches the line that ends the parameter declarations
+	separator = regexp.MustCompile(`(?m)^---[ \t]*\r?(?:\n|\z)`)
+
+	// syntaxes maps the supported syntax identifiers to their implementation
+	syntaxes = map[string]syntax{
+		T.SyntaxShell: {shellPlaceholders, shellRender},
+		T.SyntaxGo:    {goPlaceholders, goRender},
+	}
+)
+
+// ParseTemplate splits a source into the declaration of its parameters and the body. A source without a leading
+// parameters document is a template without declared parameters.
+func ParseTemplate(source []byte) E.Either[error, *T.Template] {
+	loc := separator.FindIndex(source)
+	if loc == nil {
+		return E.Of[error](&T.Template{Body: source})
+	}
+	// only a document that consists of the parameters key is a declaration
+	doc, err := E.UnwrapError(Y.Parse[map[string]any](source[:loc[0]]))
+	if _, ok := doc[T.KeyParameters]; err != nil || len(doc) != 1 || !ok {
+		return E.Of[error](&T.Template{Body: source})
+	}
+	return F.Pipe1(
+		Y.Parse[header](source[:loc[0]]),
+		E.BiMap(
+			func(err error) error {
+				return fmt.Errorf("invalid declaration of the template parameters: %w", err)
+			},
+			func(h header) *T.Template {
+				return &T.Template{
+					Parameters: R.UnionLastMonoid[string, T.Parameter]().Concat(T.Parameters{}, h.Parameters),
+					Body:       source[loc[1]:],
+					Offset:     bytes.Count(source[:loc[1]], []byte("\n")),
+				}
+			},
+		),
+	)
+}
+
+// ParseVar parses a variable given as `NAME=VALUE`
+func ParseVar(value string) E.Either[error, T.Vars] {
+	name, val, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return E.Left[T.Vars](fmt.Errorf("invalid variable [%s], expected NAME=VALUE", value))
+	}
+	return E.Of[error](T.Vars{name: val})
+}
+
+// VarsFromMap converts the scalar values of a map into variables, e.g. the content of a variables file
+func VarsFromMap(values map[string]any) E.Either[error, T.Vars] {
+	vars := make(T.Vars)
+	for name, value := range values {
+		switch v := value.(type) {
+		case nil:
+			vars[name] = ""
+		case string, bool, int, int64, uint64, float64:
+			vars[name] = fmt.Sprint(v)
+		default:
+			return E.Left[T.Vars](fmt.Errorf("the value of variable [%s] must be a scalar but is [%T]", name, value))
+		}
+	}
+	return E.Of[error](vars)
+}
+
+// shellPlaceholders returns the names of the `${NAME}` placeholders of a body
+func shellPlaceholders(body []byte) E.Either[error, []string] {
+	var names []string
+	for _, match := range shellPlaceholder.FindAllSubmatch(body, -1) {
+		if len(match[1]) > 0 {
+			names = append(names, string(match[1]))
+		}
+	}
+	return E.Of[error](names)
+}
+
+// shellToken marks the position of a placeholder when the structure of a body is verified
+func shellToken(name string) string {
+	return "__contract_go_" + name + "__"
+}
+
+// shellSubstitute replaces the `${NAME}` placeholders of a body with the value for their name
+func shellSubstitute(body []byte, value func(name string) string) []byte {
+	return shellPlaceholder.ReplaceAllFunc(body, func(match []byte) []byte {
+		if len(match) == 2 {
+			return match[:1]
+		}
+		return []byte(value(string(match[2 : len(match)-1])))
+	})
+}
+
+// parseNodes parses all YAML documents of a body
+func parseNodes(body []byte) ([]*yaml.Node, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(body))
+	var docs []*yaml.Node
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+}
+
+// sameStructure tests if a rendered node has the structure of the node with the placeholder tokens and if its scalars
+// are the scalars of the marked node with the tokens replaced by the values
+func sameStructure(marked, rendered *yaml.Node, values *strings.Replacer) bool {
+	if marked.Kind != rendered.Kind || marked.Anchor != rendered.Anchor || len(marked.Content) != len(rendered.Content) {
+		return false
+	}
+	if (marked.Kind == yaml.ScalarNode || marked.Kind == yaml.AliasNode) && values.Replace(marked.Value) != rendered.Value {
+		return false
+	}
+	for i := range marked.Content {
+		if !sameStructure(marked.Content[i], rendered.Content[i], values) {
+			return false
+		}
+	}
+	return true
+}
+
+// keepsStructure tests if the values of the variables keep the structure of the documents with the placeholder tokens
+func keepsStructure(marked []*yaml.Node, vars T.Vars, rendered []byte) bool {
+	docs, err := parseNodes(rendered)
+	if err != nil || len(docs) != len(marked) {
+		return false
+	}
+	names := R.Keys[string, string](vars)
+	// longer tokens first, so a token is never replaced by the token of a prefix of its name
+	sort.Slice(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j])
+	})
+	pairs := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		pairs = append(pairs, shellToken(name), vars[name])
+	}
+	values := strings.NewReplacer(pairs...)
+	for i := range docs {
+		if !sameStructure(marked[i], docs[i], values) {
+			return false
+		}
+	}
+	return true
+}
+
+// shellRender replaces the `${NAME}` placeholders of a body. The values are inserted verbatim, values that would change
+// the structure of a YAML body, e.g. by adding keys or starting a comment, are rejected. Bodies that are no valid YAML
+// are not verified.
+func shellRender(vars T.Vars) func(body []byte) E.Either[error, []byte] {
+	return func(body []byte) E.Either[error, []byte] {
+		rendered := shellSubstitute(body, func(name string) string {
+			return vars[name]
+		})
+		marked, err := parseNodes(shellSubstitute(body, shellToken))
+		if err != nil || keepsStructure(marked, vars, rendered) {
+			return E.Of[error](rendered)
+		}
+		// report the variables that change the structure on their own
+		var names []string
+		for name, value := range vars {
+			single := shellSubstitute(body, func(n string) string {
+				if n == name {
+					return value
+				}
+				return shellToken(n)
+			})
+			if !keepsStructure(marked, T.Vars{name: value}, single) {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			names = R.Keys[string, string](vars)
+		}
+		sort.Strings(names)
+		return E.Left[[]byte](fmt.Errorf("the value(s) of the template parameter(s) [%s] change the structure of the contract, quote the placeholder(s) in the template or escape the value(s)", strings.Join(names, ", ")))
+	}
+}
+
+// parseGoTemplate parses a body as a Go template
+func parseGoTemplate(body []byte) E.Either[error, *TT.Template] {
+	return E.TryCatchError(TT.New("contract").Option("missingkey=error").Parse(string(body)))
+}
+
+// goFields collects the fields of the top level data that a node references. The bodies of `range` and `with`
+// refer to a different data and are skipped.
+func goFields(node parse.Node, names []string) []string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, child := range n.Nodes {
+				names = goFields(child, names)
+			}
+		}
+	case *parse.ActionNode:
+		names = goFields(n.Pipe, names)
+	case *parse.PipeNode:
+		if n != nil {
+			for _, cmd := range n.Cmds {
+				names = goFields(cmd, names)
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			names = goFields(arg, names)
+		}
+	case *parse.FieldNode:
+		names = append(names, n.Ident[0])
+	case *parse.IfNode:
+		names = goFields(n.ElseList, goFields(n.List, goFields(n.Pipe, names)))
+	case *parse.RangeNode:
+		names = goFields(n.ElseList, goFields(n.Pipe, names))
+	case *parse.WithNode:
+		names = goFields(n.ElseList, goFields(n.Pipe, names))
+	case *parse.TemplateNode:
+		names = goFields(n.Pipe, names)
+	}
+	return names
+}
+
+// goPlaceholders returns the names of the variables that a Go template references as `{{ .NAME }}`
+func goPlaceholders(body []byte) E.Either[error, []string] {
+	return F.Pipe1(
+		parseGoTemplate(body),
+		E.Map[error](func(tmpl *TT.Template) []string {
+			return goFields(tmpl.Tree.Root, nil)
+		}),
+	)
+}
+
+// goRender executes a body as a Go template
+func goRender(vars T.Vars) func(body []byte) E.Either[error, []byte] {
+	return F.Flow2(
+		parseGoTemplate,
+		E.Chain(func(tmpl *TT.Template) E.Either[error, []byte] {
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, vars); err != nil {
+				return E.Left[[]byte](err)
+			}
+			return E.Of[error](buf.Bytes())
+		}),
+	)
+}
+
+// getSyntax returns the implementation of a syntax, shell is the default
+func getSyntax(name string) E.Either[error, syntax] {
+	if name == "" {
+		name = T.SyntaxShell
+	}
+	return F.Pipe1(
+		R.Lookup[syntax](name)(syntaxes),
+		E.FromOption[syntax](func() error {
+			return fmt.Errorf("unsupported template syntax [%s], expected [%s] or [%s]", name, T.SyntaxShell, T.SyntaxGo)
+		}),
+	)
+}
+
+// ResolveVars combines the defaults of the declared parameters with the given variables. All referenced or declared
+// parameters without a value are reported together.
+func ResolveVars(params T.Parameters, referenced []string) func(vars T.Vars) E.Either[error, T.Vars] {
+	return func(vars T.Vars) E.Either[error, T.Vars] {
+		resolved := make(T.Vars)
+		for name, param := range params {
+			if param.Default != nil {
+				resolved[name] = *param.Default
+			}
+		}
+		for name, value := range vars {
+			resolved[name] = value
+		}
+		missing := make(map[string]bool)
+		for _, name := range append(referenced, R.Keys[string, T.Parameter](params)...) {
+			if _, ok := resolved[name]; !ok {
+				missing[name] = true
+			}
+		}
+		if len(missing) > 0 {
+			names := R.Keys[string, bool](missing)
+			sort.Strings(names)
+			return E.Left[T.Vars](fmt.Errorf("missing value(s) for the template parameter(s) [%s]", strings.Join(names, ", ")))
+		}
+		return E.Of[error](resolved)
+	}
+}
+
+// BodyOffset returns the number of lines that [RenderTemplate] removes from the start of a source, so positions in the
+// rendered contract can be mapped back to the source
+func BodyOffset(source []byte) int {
+	return F.Pipe2(
+		ParseTemplate(source),
+		E.Map[error](func(tmpl *T.Template) int {
+			return tmpl.Offset
+		}),
+		E.GetOrElse(F.Constant1[error](0)),
+	)
+}
+
+// RenderTemplate renders the source of a contract with the given variables. The declaration of the parameters is
+// removed from the result. Sources that neither declare parameters nor receive variables are returned unchanged, so
+// plain contracts pass through.
+//
+// - name is the syntax of the placeholders, see [T.SyntaxShell] and [T.SyntaxGo]
+func RenderTemplate(name string) func(vars T.Vars) func(source []byte) E.Either[error, []byte] {
+	syntaxE := getSyntax(name)
+	return func(vars T.Vars) func(source []byte) E.Either[error, []byte] {
+		return func(source []byte) E.Either[error, []byte] {
+			return F.Pipe1(
+				ParseTemplate(source),
+				E.Chain(func(tmpl *T.Template) E.Either[error, []byte] {
+					if tmpl.Parameters == nil && len(vars) == 0 {
+						return E.Of[error](source)
+					}
+					return F.Pipe1(
+						syntaxE,
+						E.Chain(func(syn syntax) E.Either[error, []byte] {
+							return F.Pipe2(
+								syn.placeholders(tmpl.Body),
+								E.Chain(func(referenced []string) E.Either[error, T.Vars] {
+									return ResolveVars(tmpl.Parameters, referenced)(vars)
+								}),
+								E.Chain(func(resolved T.Vars) E.Either[error, []byte] {
+									return syn.render(resolved)(tmpl.Body)
+								}),
+							)
+						}),
+					)
+				}),
+			)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package datasource
+
+package either
+
+import (
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	T "github.com/ibm-hyper-protect/contract-go/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const shellTemplate = `parameters:
+  HOSTNAME:
+    description: hostname of the log server
+  INGESTION_KEY: {}
+  PORT:
+    default: 8080
+---
+env:
+  type: env
+  logging:
+    logDNA:
+      hostname: ${HOSTNAME}
+      ingestionKey: ${INGESTION_KEY}
+      port: ${PORT}
+      price: $$5
+`
+
+func TestParseTemplate(t *testing.T) {
+	tmpl, err := E.UnwrapError(ParseTemplate([]byte(shellTemplate)))
+	require.NoError(t, err)
+
+	assert.Len(t, tmpl.Parameters, 3)
+	assert.Nil(t, tmpl.Parameters["HOSTNAME"].Default)
+	require.NotNil(t, tmpl.Parameters["PORT"].Default)
+	assert.Equal(t, "8080", *tmpl.Parameters["PORT"].Default)
+	assert.Equal(t, "hostname of the log server", tmpl.Parameters["HOSTNAME"].Description)
+	assert.Contains(t, string(tmpl.Body), "env:")
+	assert.NotContains(t, string(tmpl.Body), "parameters")
+
+	// a plain contract does not declare parameters
+	plain, err := E.UnwrapError(ParseTemplate([]byte("env:\n  type: env\n---\nworkload: {}\n")))
+	require.NoError(t, err)
+	assert.Nil(t, plain.Parameters)
+	assert.Equal(t, 0, plain.Offset)
+
+	// an empty declaration still declares parameters
+	empty, err := E.UnwrapError(ParseTemplate([]byte("parameters: {}\n---\nenv: {}\n")))
+	require.NoError(t, err)
+	assert.NotNil(t, empty.Parameters)
+	assert.Equal(t, "env: {}\n", string(empty.Body))
+	assert.Equal(t, 2, empty.Offset)
+}
+
+func TestRenderShellTemplate(t *testing.T) {
+	render := RenderTemplate(T.SyntaxShell)
+
+	res, err := E.UnwrapError(render(T.Vars{"HOSTNAME": "logs.example.com", "INGESTION_KEY": "secret"})([]byte(shellTemplate)))
+	require.NoError(t, err)
+
+	assert.Equal(t, `env:
+  type: env
+  logging:
+    logDNA:
+      hostname: logs.example.com
+      ingestionKey: secret
+      port: 8080
+      price: $5
+`, string(res))
+
+	// all missing values are reported together
+	_, err = E.UnwrapError(render(T.Vars{})([]byte(shellTemplate)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[HOSTNAME, INGESTION_KEY]")
+
+	// undeclared placeholders are required, too
+	_, err = E.UnwrapError(render(T.Vars{"A": "a"})([]byte("a: ${A}\nb: ${B}\n")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[B]")
+}
+
+func TestRenderShellTemplateValues(t *testing.T) {
+	render := RenderTemplate(T.SyntaxShell)
+	source := []byte("env:\n  seed: ${SEED}\n  quoted: \"${QUOTED}\"\n")
+
+	res, err := E.UnwrapError(render(T.Vars{"SEED": "abc#def", "QUOTED": "key: value # no comment"})(source))
+	require.NoError(t, err)
+	assert.Equal(t, "env:\n  seed: abc#def\n  quoted: \"key: value # no comment\"\n", string(res))
+
+	for _, seed := range []string{"abc #def", "key: value", "line\nnext: line", "\"quoted\" value", "*alias"} {
+		_, err = E.UnwrapError(render(T.Vars{"SEED": seed, "QUOTED": "a"})(source))
+		require.Error(t, err, seed)
+		assert.Contains(t, err.Error(), "[SEED]", seed)
+	}
+
+	// quotes end the quoted scalar
+	_, err = E.UnwrapError(render(T.Vars{"SEED": "a", "QUOTED": `a" b`})(source))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[QUOTED]")
+
+	// bodies that are no YAML are not verified
+	res, err = E.UnwrapError(render(T.Vars{"A": "a: b"})([]byte("[${A}\n")))
+	require.NoError(t, err)
+	assert.Equal(t, "[a: b\n", string(res))
+}
+
+func TestRenderPassThrough(t *testing.T) {
+	source := []byte("env:\n  value: ${NOT_A_PARAMETER}\n")
+
+	assert.Equal(t, E.Of[error](source), RenderTemplate(T.SyntaxShell)(nil)(source))
+}
+
+func TestRenderGoTemplate(t *testing.T) {
+	source := []byte(`parameters:
+  HOSTNAME: {}
+  DEBUG:
+    default: "false"
+---
+hostname: {{ .HOSTNAME }}
+{{- if eq .DEBUG "true" }}
+debug: {{ .DEBUG }}
+{{- end }}
+{{ with .LIST }}{{ . }}{{ end }}
+`)
+	render := RenderTemplate(T.SyntaxGo)
+
+	_, err := E.UnwrapError(render(T.Vars{})(source))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[HOSTNAME, LIST]")
+
+	res, err := E.UnwrapError(render(T.Vars{"HOSTNAME": "example.com", "DEBUG": "true", "LIST": "x"})(source))
+	require.NoError(t, err)
+	assert.Equal(t, "hostname: example.com\ndebug: true\nx\n", string(res))
+
+	_, err = E.UnwrapError(RenderTemplate("unknown")(T.Vars{"A": "a"})(source))
+	assert.Error(t, err)
+}
+
+func TestVarsFromMap(t *testing.T) {
+	assert.Equal(t, E.Of[error](T.Vars{"a": "1", "b": "true", "c": "c", "d": ""}), VarsFromMap(map[string]any{"a": 1, "b": true, "c": "c", "d": nil}))
+	assert.True(t, E.IsLeft(VarsFromMap(map[string]any{"a": []any{1}})))
+}
+
+func TestParseVar(t *testing.T) {
+	assert.Equal(t, E.Of[error](T.Vars{"A": "b=c"}), ParseVar("A=b=c"))
+	assert.True(t, E.IsLeft(ParseVar("A")))
+	assert.True(t, E.IsLeft(ParseVar("=b")))
+}
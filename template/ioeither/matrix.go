@@ -0,0 +1,39 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package datasource
+
+package ioeither
+
+import (
+	"path/filepath"
+	"strings"
+
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	IOEF "github.com/IBM/fp-go/ioeither/file"
+	T "github.com/ibm-hyper-protect/contract-go/template"
+	TE "github.com/ibm-hyper-protect/contract-go/template/either"
+)
+
+// MatrixFromFile reads a matrix of template variables, files with the `.csv` extension are parsed as CSV, all others
+// as YAML or JSON
+func MatrixFromFile(name string) IOE.IOEither[error, T.Matrix] {
+	parse := TE.ParseMatrix
+	if strings.EqualFold(filepath.Ext(name), ".csv") {
+		parse = TE.ParseMatrixCSV
+	}
+	return F.Pipe1(
+		IOEF.ReadFile(name),
+		IOE.ChainEitherK(parse),
+	)
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package datasource
+
+package ioeither
+
+import (
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	IOE "github.com/IBM/fp-go/ioeither"
+	IOEF "github.com/IBM/fp-go/ioeither/file"
+	ENVIOE "github.com/ibm-hyper-protect/contract-go/environment/ioeither"
+	TE "github.com/ibm-hyper-protect/contract-go/template/either"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+)
+
+var (
+	// VarsFromFile reads template variables from a YAML or JSON file that holds a map of scalar values
+	VarsFromFile = F.Flow2(
+		IOEF.ReadFile,
+		IOE.ChainEitherK(F.Flow2(
+			Y.Parse[map[string]any],
+			E.Chain(TE.VarsFromMap),
+		)),
+	)
+
+	// VarsFromDotEnv reads template variables from the `.env` file in a folder
+	VarsFromDotEnv = ENVIOE.EnvFromDotEnv
+)
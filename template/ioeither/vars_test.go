@@ -0,0 +1,51 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package datasource
+
+package ioeither
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	T "github.com/ibm-hyper-protect/contract-go/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarsFromFile(t *testing.T) {
+	dir := t.TempDir()
+
+	name := filepath.Join(dir, "vars.yaml")
+	require.NoError(t, os.WriteFile(name, []byte("HOSTNAME: example.com\nPORT: 8080\n"), 0600))
+	assert.Equal(t, E.Of[error](T.Vars{"HOSTNAME": "example.com", "PORT": "8080"}), VarsFromFile(name)())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("HOSTNAME=example.org\n"), 0600))
+	assert.Equal(t, E.Of[error](T.Vars{"HOSTNAME": "example.org"}), VarsFromDotEnv(dir)())
+
+	assert.True(t, E.IsLeft(VarsFromFile(filepath.Join(dir, "missing.yaml"))()))
+}
+
+func TestMatrixFromFile(t *testing.T) {
+	dir := t.TempDir()
+
+	csvName := filepath.Join(dir, "tenants.csv")
+	require.NoError(t, os.WriteFile(csvName, []byte("name,PORT\nacme,8080\n"), 0600))
+	assert.Equal(t, E.Of[error](T.Matrix{{"name": "acme", "PORT": "8080"}}), MatrixFromFile(csvName)())
+
+	jsonName := filepath.Join(dir, "tenants.json")
+	require.NoError(t, os.WriteFile(jsonName, []byte(`[{"name": "acme", "PORT": 8080}]`), 0600))
+	assert.Equal(t, E.Of[error](T.Matrix{{"name": "acme", "PORT": "8080"}}), MatrixFromFile(jsonName)())
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package datasource
+
+package template
+
+const (
+	// SyntaxShell resolves `${NAME}` placeholders, `$$` escapes a literal `$`
+	SyntaxShell = "shell"
+	// SyntaxGo renders the source as a Go template, variables are referenced as `{{ .NAME }}`
+	SyntaxGo = "go"
+
+	// KeyParameters is the key of the document that declares the parameters of a template
+	KeyParameters = "parameters"
+)
+
+type (
+	// Vars are the values of template variables keyed by name
+	Vars = map[string]string
+
+	// Matrix holds one set of variables per row, e.g. one per tenant
+	Matrix = []Vars
+
+	// Parameter declares a parameter of a template, a parameter without default is required
+	Parameter struct {
+		Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+		Default     *string `json:"default,omitempty" yaml:"default,omitempty"`
+	}
+
+	// Parameters are the declared parameters of a template keyed by name
+	Parameters = map[string]Parameter
+
+	// Template is the source of a contract together with the declaration of its parameters. The parameters are declared
+	// in an optional leading YAML document that only carries the [KeyParameters] key, e.g.
+	//
+	//	parameters:
+	//	  HOSTNAME: {}
+	//	  LOG_LEVEL:
+	//	    default: info
+	//	---
+	//	env:
+	//	  ...
+	Template struct {
+		Parameters Parameters // nil if the source does not declare parameters
+		Body       []byte
+		Offset     int // number of lines of the source that precede the body, i.e. the lines of the declaration
+	}
+)
@@ -0,0 +1,242 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	M "github.com/IBM/fp-go/monoid"
+	R "github.com/IBM/fp-go/record"
+	S "github.com/IBM/fp-go/string"
+)
+
+const (
+	// StrategyLastWins replaces each field of a section by the value of the later contract
+	StrategyLastWins = "last-wins"
+	// StrategyDeepMerge merges env maps, volumes, auths and image trust entries key by key, the later contract wins per key
+	StrategyDeepMerge = "deep-merge"
+	// StrategyErrorOnConflict merges like [StrategyDeepMerge] but fails if a value would be overridden
+	StrategyErrorOnConflict = "error-on-conflict"
+)
+
+type (
+	// Override records a value that has been replaced by a later contract during a merge
+	Override struct {
+		Path   string `json:"path" yaml:"path"`                         // JSON pointer to the overridden value
+		Source string `json:"source,omitempty" yaml:"source,omitempty"` // origin of the contract that replaced the value
+	}
+
+	// Overrides is the error of a merge with [StrategyErrorOnConflict] that encountered conflicts
+	Overrides []Override
+
+	// Merge is a contract that results from merging contracts, together with the values that have been overridden
+	Merge struct {
+		Contract  *Contract  // the merged contract
+		Origin    string     // origin of the latest contract that contributed to the merge, e.g. a filename
+		Overrides []Override // values replaced while merging
+	}
+
+	// merger merges the sections of two contracts and records the overrides
+	merger struct {
+		deep      bool
+		source    string
+		overrides []Override
+	}
+)
+
+// AllStrategies lists the supported merge strategies
+var AllStrategies = A.From(StrategyLastWins, StrategyDeepMerge, StrategyErrorOnConflict)
+
+// mergePath appends a key to a JSON pointer
+func mergePath(parent, key string) string {
+	return fmt.Sprintf("%s/%s", parent, strings.NewReplacer("~", "~0", "/", "~1").Replace(key))
+}
+
+func (m *merger) override(path string) {
+	m.overrides = append(m.overrides, Override{Path: path, Source: m.source})
+}
+
+// track records an override if a value of the earlier contract differs from the merged value
+func track[T any](m *merger, path string, left, merged T) T {
+	var zero T
+	if !reflect.DeepEqual(left, zero) && !reflect.DeepEqual(left, merged) {
+		m.override(path)
+	}
+	return merged
+}
+
+// mergeValue combines two values with a monoid and records an override if the value of the earlier contract has been
+// replaced
+func mergeValue[T any](m *merger, path string, monoid M.Monoid[T], left, right T) T {
+	return track(m, path, left, monoid.Concat(left, right))
+}
+
+// mergeMap merges a map with a monoid and records the keys whose values have been replaced. Unless the merge is deep,
+// the map of the later contract replaces the map as a whole
+func mergeMap[V any](m *merger, path string, monoid M.Monoid[map[string]V], left, right map[string]V) map[string]V {
+	if !m.deep {
+		return mergeValue(m, path, lastMonoid[map[string]V](), left, right)
+	}
+	result := monoid.Concat(left, right)
+	for _, key := range A.Sort(S.Ord)(R.Keys[string, V](left)) {
+		if !reflect.DeepEqual(left[key], result[key]) {
+			m.override(mergePath(path, key))
+		}
+	}
+	return result
+}
+
+func (m *merger) images(path string, left, right *Images) *Images {
+	if !m.deep || left == nil || right == nil {
+		return mergeValue(m, path, MonoidWorkload.Images, left, right)
+	}
+	return &Images{
+		DockerContentTrust: mergeMap(m, mergePath(path, "dct"), MonoidImages.DockerContentTrust, left.DockerContentTrust, right.DockerContentTrust),
+		RedHatSigning:      mergeMap(m, mergePath(path, "rhs"), MonoidImages.RedHatSigning, left.RedHatSigning, right.RedHatSigning),
+	}
+}
+
+func (m *merger) workload(left, right *Workload) *Workload {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	const path = "/workload"
+	result := &Workload{
+		Type:                   mergeValue(m, mergePath(path, "type"), MonoidWorkload.Type, left.Type, right.Type),
+		Volumes:                mergeMap(m, mergePath(path, "volumes"), MonoidWorkload.Volumes, left.Volumes, right.Volumes),
+		Auths:                  mergeMap(m, mergePath(path, "auths"), MonoidWorkload.Auths, left.Auths, right.Auths),
+		Images:                 m.images(mergePath(path, "images"), left.Images, right.Images),
+		Env:                    mergeMap(m, mergePath(path, "env"), MonoidWorkload.Env, left.Env, right.Env),
+		ConfidentialContainers: mergeValue(m, mergePath(path, "confidential-containers"), MonoidWorkload.ConfidentialContainers, left.ConfidentialContainers, right.ConfidentialContainers),
+	}
+	// compose and play are alternatives that the workload monoid replaces together
+	merged := MonoidContract.Workload.Concat(&Workload{Compose: left.Compose, Play: left.Play}, &Workload{Compose: right.Compose, Play: right.Play})
+	key := "compose"
+	if right.Compose == nil {
+		key = "play"
+	}
+	track(m, mergePath(path, key), Workload{Compose: left.Compose, Play: left.Play}, Workload{Compose: merged.Compose, Play: merged.Play})
+	result.Compose, result.Play = merged.Compose, merged.Play
+	return result
+}
+
+func (m *merger) env(left, right *Env) *Env {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	const path = "/env"
+	return &Env{
+		Type:                   mergeValue(m, mergePath(path, "type"), MonoidEnv.Type, left.Type, right.Type),
+		Logging:                mergeValue(m, mergePath(path, "logging"), MonoidEnv.Logging, left.Logging, right.Logging),
+		Volumes:                mergeMap(m, mergePath(path, "volumes"), MonoidEnv.Volumes, left.Volumes, right.Volumes),
+		Env:                    mergeMap(m, mergePath(path, "env"), MonoidEnv.Env, left.Env, right.Env),
+		SigningKey:             mergeValue(m, mergePath(path, "signingKey"), MonoidEnv.SigningKey, left.SigningKey, right.SigningKey),
+		ConfidentialContainers: mergeValue(m, mergePath(path, "confidential-containers"), MonoidEnv.ConfidentialContainers, left.ConfidentialContainers, right.ConfidentialContainers),
+	}
+}
+
+func (m *merger) contract(left, right *Contract) *Contract {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	return &Contract{
+		Workload:             m.workload(left.Workload, right.Workload),
+		Env:                  m.env(left.Env, right.Env),
+		AttestationPublicKey: mergeValue(m, "/attestationPublicKey", MonoidContract.AttestationPublicKey, left.AttestationPublicKey, right.AttestationPublicKey),
+		EnvWorkloadSignature: mergeValue(m, "/envWorkloadSignature", MonoidContract.EnvWorkloadSignature, left.EnvWorkloadSignature, right.EnvWorkloadSignature),
+	}
+}
+
+// MergeMonoid returns a monoid that merges contracts according to a strategy and records the values that have been
+// overridden. The fields are combined by the monoids of [MonoidContract], so a deep merge yields the contract of
+// [ContractMonoid]. The merged contract does not depend on the grouping of the inputs, the overrides are reported relative
+// to the grouping, i.e. from left to right for [M.ConcatAll].
+//
+// - strategy is one of [AllStrategies], unknown strategies merge like [StrategyLastWins]
+func MergeMonoid(strategy string) M.Monoid[*Merge] {
+	deep := strategy == StrategyDeepMerge || strategy == StrategyErrorOnConflict
+	return M.MakeMonoid(func(left, right *Merge) *Merge {
+		m := &merger{deep: deep, source: right.Origin}
+		origin := right.Origin
+		if origin == "" {
+			origin = left.Origin
+		}
+		contract := m.contract(left.Contract, right.Contract)
+		return &Merge{
+			Contract:  contract,
+			Origin:    origin,
+			Overrides: A.Flatten(A.From(left.Overrides, right.Overrides, m.overrides)),
+		}
+	}, &Merge{})
+}
+
+// MergeFrom lifts a contract into a [Merge], the origin is reported with the values it overrides
+func MergeFrom(origin string) func(*Contract) *Merge {
+	return func(contract *Contract) *Merge {
+		return &Merge{Contract: contract, Origin: origin}
+	}
+}
+
+// MergeResult extracts the merged contract, for [StrategyErrorOnConflict] it fails with [Overrides] if values have
+// been overridden
+func MergeResult(strategy string) func(*Merge) E.Either[error, *Contract] {
+	return func(merge *Merge) E.Either[error, *Contract] {
+		if strategy == StrategyErrorOnConflict && A.IsNonEmpty(merge.Overrides) {
+			return E.Left[*Contract](error(Overrides(merge.Overrides)))
+		}
+		return E.Of[error](merge.Contract)
+	}
+}
+
+// MergeContracts merges contracts from left to right according to a strategy
+func MergeContracts(strategy string) func([]*Merge) E.Either[error, *Merge] {
+	monoid := MergeMonoid(strategy)
+	result := MergeResult(strategy)
+	return func(merges []*Merge) E.Either[error, *Merge] {
+		merged := M.ConcatAll(monoid)(merges)
+		return F.Pipe2(
+			merged,
+			result,
+			E.Map[error](F.Constant1[*Contract](merged)),
+		)
+	}
+}
+
+// String returns a one line representation of the override
+func (o Override) String() string {
+	if o.Source == "" {
+		return o.Path
+	}
+	return fmt.Sprintf("%s (overridden by %s)", o.Path, o.Source)
+}
+
+// Error implements the error interface, the message lists all conflicts
+func (ovs Overrides) Error() string {
+	return fmt.Sprintf("the contracts have %d conflict(s): %s", len(ovs), strings.Join(A.Map(Override.String)(ovs), "; "))
+}
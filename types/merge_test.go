@@ -0,0 +1,125 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	M "github.com/IBM/fp-go/monoid"
+	S "github.com/IBM/fp-go/string"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mergeInputs() []*Merge {
+	base := &Contract{
+		Workload: &Workload{
+			Type:    TypeWorkload,
+			Compose: &Compose{Archive: "base"},
+			Env:     map[string]string{"LOG_LEVEL": "info", "REGION": "eu-de"},
+			Auths:   Auths{"us.icr.io": {Username: "iamapikey", Password: "base"}},
+		},
+	}
+	prod := &Contract{
+		Workload: &Workload{
+			Env:   map[string]string{"LOG_LEVEL": "warn"},
+			Auths: Auths{"docker.io": {Username: "user", Password: "prod"}},
+		},
+		Env: &Env{
+			Type: TypeEnv,
+		},
+	}
+	return A.From(MergeFrom("base.yaml")(base), MergeFrom("prod.yaml")(prod))
+}
+
+func TestMergeLastWins(t *testing.T) {
+	merged, err := E.UnwrapError(MergeContracts(StrategyLastWins)(mergeInputs()))
+	require.NoError(t, err)
+
+	workload := merged.Contract.Workload
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "warn"}, workload.Env)
+	assert.Equal(t, []string{"docker.io"}, keys(workload.Auths))
+	assert.Equal(t, "base", workload.Compose.Archive)
+	assert.Equal(t, TypeEnv, merged.Contract.Env.Type)
+
+	assert.Equal(t, A.From(
+		Override{Path: "/workload/auths", Source: "prod.yaml"},
+		Override{Path: "/workload/env", Source: "prod.yaml"},
+	), merged.Overrides)
+}
+
+func TestMergeDeep(t *testing.T) {
+	merged, err := E.UnwrapError(MergeContracts(StrategyDeepMerge)(mergeInputs()))
+	require.NoError(t, err)
+
+	workload := merged.Contract.Workload
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "warn", "REGION": "eu-de"}, workload.Env)
+	assert.Equal(t, []string{"docker.io", "us.icr.io"}, keys(workload.Auths))
+
+	assert.Equal(t, A.Of(Override{Path: "/workload/env/LOG_LEVEL", Source: "prod.yaml"}), merged.Overrides)
+}
+
+func TestMergeErrorOnConflict(t *testing.T) {
+	res := MergeContracts(StrategyErrorOnConflict)(mergeInputs())
+	require.True(t, E.IsLeft(res))
+
+	_, err := E.UnwrapError(res)
+	assert.Equal(t, Overrides{{Path: "/workload/env/LOG_LEVEL", Source: "prod.yaml"}}, err)
+
+	// no conflicts without overlapping keys
+	inputs := mergeInputs()
+	delete(inputs[1].Contract.Workload.Env, "LOG_LEVEL")
+	assert.True(t, E.IsRight(MergeContracts(StrategyErrorOnConflict)(inputs)))
+}
+
+func TestMergeDeepLikeContractMonoid(t *testing.T) {
+	inputs := mergeInputs()
+	merged, err := E.UnwrapError(MergeContracts(StrategyDeepMerge)(inputs))
+	require.NoError(t, err)
+
+	assert.Equal(t, ContractMonoid.Concat(inputs[0].Contract, inputs[1].Contract), merged.Contract)
+	assert.Equal(t, "base", merged.Contract.Workload.Compose.Archive)
+	assert.Equal(t, TypeWorkload, merged.Contract.Workload.Type)
+}
+
+func TestMergeComposeReplacedByPlay(t *testing.T) {
+	inputs := A.From(
+		MergeFrom("compose.yaml")(&Contract{Workload: &Workload{Type: TypeWorkload, Compose: &Compose{Archive: "compose"}}}),
+		MergeFrom("play.yaml")(&Contract{Workload: &Workload{Play: &Play{Archive: "play"}}}),
+	)
+	merged := M.ConcatAll(MergeMonoid(StrategyDeepMerge))(inputs)
+
+	assert.Nil(t, merged.Contract.Workload.Compose)
+	assert.Equal(t, "play", merged.Contract.Workload.Play.Archive)
+	assert.Equal(t, A.Of(Override{Path: "/workload/play", Source: "play.yaml"}), merged.Overrides)
+}
+
+func TestMergeMonoidIdentity(t *testing.T) {
+	monoid := MergeMonoid(StrategyDeepMerge)
+	input := mergeInputs()[0]
+
+	assert.Equal(t, input.Contract, monoid.Concat(monoid.Empty(), input).Contract)
+	assert.Equal(t, input.Contract, monoid.Concat(input, monoid.Empty()).Contract)
+}
+
+func keys[V any](m map[string]V) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	return A.Sort(S.Ord)(result)
+}
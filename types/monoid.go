@@ -1,10 +1,11 @@
 package types
 
 import (
-	F "github.com/IBM/fp-go/function"
+	"reflect"
+
 	M "github.com/IBM/fp-go/monoid"
 	R "github.com/IBM/fp-go/record"
-	S "github.com/IBM/fp-go/string"
+	ENV "github.com/ibm-hyper-protect/contract-go/environment"
 )
 
 type (
@@ -26,14 +27,29 @@ type (
 		Volume M.Monoid[EnvVolume]
 	}
 
+	TypeMonoidImages struct {
+		DockerContentTrust M.Monoid[DockerContentTrusts]
+		RedHatSigning      M.Monoid[RedHatSignings]
+	}
+
 	TypeMonoidWorkload struct {
-		Type    M.Monoid[string]
-		Volumes M.Monoid[WorkloadVolumes]
+		Type                   M.Monoid[string]
+		Volumes                M.Monoid[WorkloadVolumes]
+		Auths                  M.Monoid[Auths]
+		Images                 M.Monoid[*Images]
+		Env                    M.Monoid[ENV.Env]
+		Compose                M.Monoid[*Compose]
+		Play                   M.Monoid[*Play]
+		ConfidentialContainers M.Monoid[any]
 	}
 
 	TypeMonoidEnv struct {
-		Type    M.Monoid[string]
-		Volumes M.Monoid[EnvVolumes]
+		Type                   M.Monoid[string]
+		Logging                M.Monoid[*Logging]
+		Volumes                M.Monoid[EnvVolumes]
+		Env                    M.Monoid[ENV.Env]
+		SigningKey             M.Monoid[*string]
+		ConfidentialContainers M.Monoid[any]
 	}
 
 	TypeMonoidContract struct {
@@ -44,14 +60,21 @@ type (
 	}
 )
 
-var (
-	stringMonoid    = M.MakeMonoid(F.Second[string, string], S.Monoid.Empty())
-	stringRefMonoid = M.MakeMonoid(func(left, right *string) *string {
-		if right == nil {
+// lastMonoid returns a monoid that replaces a value by the later one, unless the later value is the zero value
+func lastMonoid[T any]() M.Monoid[T] {
+	var zero T
+	return M.MakeMonoid(func(left, right T) T {
+		if reflect.DeepEqual(right, zero) {
 			return left
 		}
 		return right
-	}, nil)
+	}, zero)
+}
+
+var (
+	stringMonoid    = lastMonoid[string]()
+	stringRefMonoid = lastMonoid[*string]()
+	anyMonoid       = lastMonoid[any]()
 
 	MonoidEnvVolume = TypeMonoidEnvVolume{
 		Seed: stringMonoid,
@@ -87,16 +110,43 @@ var (
 		}),
 	}
 
+	// MonoidImages contains the monoids for the image trust entries, later entries replace earlier ones per key
+	MonoidImages = TypeMonoidImages{
+		DockerContentTrust: R.UnionLastMonoid[string, *DockerContentTrust](),
+		RedHatSigning:      R.UnionLastMonoid[string, *RedHatSigning](),
+	}
+
 	// MonoidWorkload contains the monoids for the fields in the workload
 	MonoidWorkload = TypeMonoidWorkload{
-		Type:    M.MakeMonoid(F.Second[string, string], TypeWorkload),
+		Type:    M.MakeMonoid(stringMonoid.Concat, TypeWorkload),
 		Volumes: R.UnionMonoid[string, WorkloadVolume](MonoidWorkloadVolumes.Volume),
+		Auths:   R.UnionLastMonoid[string, Credential](),
+		Images: M.MakeMonoid(func(left, right *Images) *Images {
+			if left == nil {
+				return right
+			}
+			if right == nil {
+				return left
+			}
+			return &Images{
+				DockerContentTrust: MonoidImages.DockerContentTrust.Concat(left.DockerContentTrust, right.DockerContentTrust),
+				RedHatSigning:      MonoidImages.RedHatSigning.Concat(left.RedHatSigning, right.RedHatSigning),
+			}
+		}, nil),
+		Env:                    R.UnionLastMonoid[string, string](),
+		Compose:                lastMonoid[*Compose](),
+		Play:                   lastMonoid[*Play](),
+		ConfidentialContainers: anyMonoid,
 	}
 
 	// MonoidEnv contains the monoids for the fields in the env type
 	MonoidEnv = TypeMonoidEnv{
-		Type:    M.MakeMonoid(F.Second[string, string], TypeEnv),
-		Volumes: R.UnionMonoid[string, EnvVolume](MonoidEnvVolumes.Volume),
+		Type:                   M.MakeMonoid(stringMonoid.Concat, TypeEnv),
+		Logging:                lastMonoid[*Logging](),
+		Volumes:                R.UnionMonoid[string, EnvVolume](MonoidEnvVolumes.Volume),
+		Env:                    R.UnionLastMonoid[string, string](),
+		SigningKey:             stringRefMonoid,
+		ConfidentialContainers: anyMonoid,
 	}
 
 	MonoidContract = TypeMonoidContract{
@@ -107,10 +157,21 @@ var (
 			if right == nil {
 				return left
 			}
-			return &Workload{
-				Type:    MonoidWorkload.Type.Concat(left.Type, right.Type),
-				Volumes: MonoidWorkload.Volumes.Concat(left.Volumes, right.Volumes),
+			result := &Workload{
+				Type:                   MonoidWorkload.Type.Concat(left.Type, right.Type),
+				Volumes:                MonoidWorkload.Volumes.Concat(left.Volumes, right.Volumes),
+				Auths:                  MonoidWorkload.Auths.Concat(left.Auths, right.Auths),
+				Images:                 MonoidWorkload.Images.Concat(left.Images, right.Images),
+				Env:                    MonoidWorkload.Env.Concat(left.Env, right.Env),
+				Compose:                left.Compose,
+				Play:                   left.Play,
+				ConfidentialContainers: MonoidWorkload.ConfidentialContainers.Concat(left.ConfidentialContainers, right.ConfidentialContainers),
+			}
+			// compose and play are alternatives, so a later contract replaces both
+			if right.Compose != nil || right.Play != nil {
+				result.Compose, result.Play = right.Compose, right.Play
 			}
+			return result
 		}, &Workload{
 			Type:    MonoidWorkload.Type.Empty(),
 			Volumes: MonoidWorkload.Volumes.Empty(),
@@ -123,8 +184,12 @@ var (
 				return left
 			}
 			return &Env{
-				Type:    MonoidEnv.Type.Concat(left.Type, right.Type),
-				Volumes: MonoidEnv.Volumes.Concat(left.Volumes, right.Volumes),
+				Type:                   MonoidEnv.Type.Concat(left.Type, right.Type),
+				Logging:                MonoidEnv.Logging.Concat(left.Logging, right.Logging),
+				Volumes:                MonoidEnv.Volumes.Concat(left.Volumes, right.Volumes),
+				Env:                    MonoidEnv.Env.Concat(left.Env, right.Env),
+				SigningKey:             MonoidEnv.SigningKey.Concat(left.SigningKey, right.SigningKey),
+				ConfidentialContainers: MonoidEnv.ConfidentialContainers.Concat(left.ConfidentialContainers, right.ConfidentialContainers),
 			}
 		}, &Env{
 			Type:    MonoidEnv.Type.Empty(),
@@ -134,7 +199,8 @@ var (
 		EnvWorkloadSignature: stringRefMonoid,
 	}
 
-	// ContractMonoid is a monoid that allows to merge contracts
+	// ContractMonoid is a monoid that allows to merge contracts. Maps are merged key by key and the later contract wins
+	// per key, see [MergeMonoid] to track the values that are overridden
 	ContractMonoid = M.MakeMonoid(func(left, right *Contract) *Contract {
 		if left == nil {
 			return right
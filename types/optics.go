@@ -62,9 +62,10 @@ type (
 	}
 
 	TypeOpticEnv struct {
-		Type    L.Lens[*Env, string]
-		Volumes L.Lens[*Env, O.Option[EnvVolumes]]
-		Env     L.Lens[*Env, O.Option[ENV.Env]]
+		Type       L.Lens[*Env, string]
+		Volumes    L.Lens[*Env, O.Option[EnvVolumes]]
+		Env        L.Lens[*Env, O.Option[ENV.Env]]
+		SigningKey L.Lens[*Env, O.Option[string]]
 	}
 
 	TypeOpticContract struct {
@@ -160,9 +161,10 @@ var (
 
 	// OpticEnv contains the optical elements to access fields in the env section
 	OpticEnv = TypeOpticEnv{
-		Type:    L.MakeLensRef((*Env).GetType, (*Env).SetType),
-		Volumes: LI.Compose[*Env](fromNillableMap[EnvVolumes]())(L.MakeLensRef((*Env).GetVolumes, (*Env).SetVolumes)),
-		Env:     LI.Compose[*Env](fromNillableMap[ENV.Env]())(L.MakeLensRef((*Env).GetEnv, (*Env).SetEnv)),
+		Type:       L.MakeLensRef((*Env).GetType, (*Env).SetType),
+		Volumes:    LI.Compose[*Env](fromNillableMap[EnvVolumes]())(L.MakeLensRef((*Env).GetVolumes, (*Env).SetVolumes)),
+		Env:        LI.Compose[*Env](fromNillableMap[ENV.Env]())(L.MakeLensRef((*Env).GetEnv, (*Env).SetEnv)),
+		SigningKey: LI.Compose[*Env](stringIso)(L.MakeLensRef((*Env).GetSigningKey, (*Env).SetSigningKey)),
 	}
 
 	fromNillableContractString = LI.Compose[*Contract](stringIso)
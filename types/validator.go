@@ -46,13 +46,18 @@ var schemaE = F.Pipe2(
 	J.Unmarshal[*jsonschema.Schema],
 )
 
+// violationsToError converts a non empty list of violations into an error
+func violationsToError(vs []Violation) error {
+	return Violations(vs)
+}
+
+// handleValidationErrors reports all validation errors as [Violations] or returns the contract if there are none
 func handleValidationErrors[T any](contract T, errs []jsonschema.KeyError) E.Either[error, T] {
-	return F.Pipe4(
+	return F.Pipe3(
 		errs,
-		A.Head[jsonschema.KeyError],
-		O.Map(F.ToAny[jsonschema.KeyError]),
-		O.Chain(O.ToType[error]),
-		O.Fold(F.Nullary2(F.Constant(contract), E.Right[error, T]), E.Left[T, error]),
+		keyErrorsToViolations,
+		O.FromPredicate(A.IsNonEmpty[Violation]),
+		O.Fold(F.Nullary2(F.Constant(contract), E.Right[error, T]), F.Flow2(violationsToError, E.Left[T, error])),
 	)
 }
 
@@ -71,6 +76,12 @@ func ValidateAndDecode[T any](schema *jsonschema.Schema) func(data any) E.Either
 	}
 }
 
+// DecodeContract decodes a contract without validating it against the schema, e.g. for partial contracts that are
+// merged before validation
+func DecodeContract(raw AnyMap) E.Either[error, *Contract] {
+	return transcode[AnyMap, *Contract](raw)
+}
+
 // ValidateContract validates the given contract against the contract schema
 func ValidateContract(raw AnyMap) E.Either[error, *Contract] {
 
@@ -81,15 +92,19 @@ func ValidateContract(raw AnyMap) E.Either[error, *Contract] {
 		E.Ap[[]jsonschema.KeyError](E.Of[error](raw)),
 	)
 
-	// parse the data
-	parsedE := F.Pipe2(
-		raw,
-		J.Marshal[AnyMap],
-		E.Chain(J.Unmarshal[*Contract]),
-	)
-
 	return F.Pipe1(
-		E.SequenceT2(parsedE, validatedE),
+		E.SequenceT2(DecodeContract(raw), validatedE),
 		E.Chain(T.Tupled2(handleValidationErrors[*Contract])),
 	)
 }
+
+// ContractViolations validates the given contract against the contract schema and returns all violations sorted by
+// their path. The result is empty for a valid contract, the error is reserved for failures of the validation itself.
+func ContractViolations(raw AnyMap) E.Either[error, []Violation] {
+	return F.Pipe3(
+		schemaE,
+		E.Map[error](validate[AnyMap]),
+		E.Ap[[]jsonschema.KeyError](E.Of[error](raw)),
+		E.Map[error](keyErrorsToViolations),
+	)
+}
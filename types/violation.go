@@ -0,0 +1,222 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	A "github.com/IBM/fp-go/array"
+	E "github.com/IBM/fp-go/either"
+	F "github.com/IBM/fp-go/function"
+	O "github.com/IBM/fp-go/option"
+	ORD "github.com/IBM/fp-go/ord"
+	R "github.com/IBM/fp-go/record"
+	S "github.com/IBM/fp-go/string"
+	T "github.com/IBM/fp-go/tuple"
+	Y "github.com/ibm-hyper-protect/contract-go/yaml"
+	"github.com/qri-io/jsonschema"
+)
+
+const (
+	// KeywordUnknown is reported if the failing keyword of a violation cannot be determined
+	KeywordUnknown = "unknown"
+
+	// sections of a contract that may be embedded as YAML strings
+	keyEnv      = "env"
+	keyWorkload = "workload"
+)
+
+type (
+	// Violation describes a single violation of the contract schema
+	Violation struct {
+		Path    string `json:"path" yaml:"path"`                         // JSON pointer to the offending value
+		Keyword string `json:"keyword" yaml:"keyword"`                   // schema keyword that failed, e.g. `required` or `type`
+		Message string `json:"message" yaml:"message"`                   // human readable description of the violation
+		File    string `json:"file,omitempty" yaml:"file,omitempty"`     // name of the source file, if known
+		Line    int    `json:"line,omitempty" yaml:"line,omitempty"`     // 1-based line in the source file, if known
+		Column  int    `json:"column,omitempty" yaml:"column,omitempty"` // 1-based column in the source file, if known
+	}
+
+	// Violations is the error of a contract that fails the schema validation, it carries all violations
+	Violations []Violation
+
+	// keywordPattern maps the message of a [jsonschema.KeyError] to the keyword that produced it
+	keywordPattern struct {
+		pattern *regexp.Regexp
+		keyword string
+	}
+)
+
+var (
+	// keywordPatterns identifies the failing keyword from the error message, since [jsonschema.KeyError] does not
+	// carry it. Order matters, more specific patterns come first.
+	keywordPatterns = A.From(
+		keywordPattern{regexp.MustCompile(`value is required$`), "required"},
+		keywordPattern{regexp.MustCompile(`property is required$`), "dependentRequired"},
+		keywordPattern{regexp.MustCompile(`^type should be`), "type"},
+		keywordPattern{regexp.MustCompile(`(?i)oneof schemas`), "oneOf"},
+		keywordPattern{regexp.MustCompile(`(?i)anyof schemas`), "anyOf"},
+		keywordPattern{regexp.MustCompile(`^additional properties`), "additionalProperties"},
+		keywordPattern{regexp.MustCompile(`^additional items`), "additionalItems"},
+		keywordPattern{regexp.MustCompile(`^unevaluated properties`), "unevaluatedProperties"},
+		keywordPattern{regexp.MustCompile(`^unevaluated items`), "unevaluatedItems"},
+		keywordPattern{regexp.MustCompile(`^should be one of`), "enum"},
+		keywordPattern{regexp.MustCompile(`^must equal`), "const"},
+		keywordPattern{regexp.MustCompile(`\('not'\) expected invalid`), "not"},
+		keywordPattern{regexp.MustCompile(`^regexp pattern`), "pattern"},
+		keywordPattern{regexp.MustCompile(`^max length`), "maxLength"},
+		keywordPattern{regexp.MustCompile(`^min length`), "minLength"},
+		keywordPattern{regexp.MustCompile(`must be a multiple of`), "multipleOf"},
+		keywordPattern{regexp.MustCompile(`must be greater than or equal to`), "minimum"},
+		keywordPattern{regexp.MustCompile(`must be less than or equal to`), "maximum"},
+		keywordPattern{regexp.MustCompile(`must be greater than`), "exclusiveMinimum"},
+		keywordPattern{regexp.MustCompile(`must be less than`), "exclusiveMaximum"},
+		keywordPattern{regexp.MustCompile(`^array length .* exceeds`), "maxItems"},
+		keywordPattern{regexp.MustCompile(`^array length .* below`), "minItems"},
+		keywordPattern{regexp.MustCompile(`^array items must be unique`), "uniqueItems"},
+		keywordPattern{regexp.MustCompile(`^object Properties .* exceed`), "maxProperties"},
+		keywordPattern{regexp.MustCompile(`^object Properties .* below`), "minProperties"},
+		keywordPattern{regexp.MustCompile(`^must contain at least one`), "contains"},
+		keywordPattern{regexp.MustCompile(`^failed to resolve schema`), "$ref"},
+		keywordPattern{regexp.MustCompile(`^invalid .*:`), "format"},
+	)
+
+	// ordViolation sorts violations by their path
+	ordViolation = F.Pipe1(
+		S.Ord,
+		ORD.Contramap(func(v Violation) string {
+			return v.Path
+		}),
+	)
+
+	// keyErrorsToViolations converts the errors of the schema validation into sorted violations
+	keyErrorsToViolations = F.Flow2(
+		A.Map(violationFromKeyError),
+		A.Sort(ordViolation),
+	)
+)
+
+// keywordFromMessage derives the failing keyword from the message of a [jsonschema.KeyError]
+func keywordFromMessage(msg string) string {
+	return F.Pipe3(
+		keywordPatterns,
+		A.FindFirst(func(p keywordPattern) bool {
+			return p.pattern.MatchString(msg)
+		}),
+		O.Map(func(p keywordPattern) string {
+			return p.keyword
+		}),
+		O.GetOrElse(F.Constant(KeywordUnknown)),
+	)
+}
+
+// violationFromKeyError converts a [jsonschema.KeyError] into a [Violation]
+func violationFromKeyError(err jsonschema.KeyError) Violation {
+	path := err.PropertyPath
+	if path == "" {
+		path = "/"
+	}
+	return Violation{
+		Path:    path,
+		Keyword: keywordFromMessage(err.Message),
+		Message: err.Message,
+	}
+}
+
+// locateViolation attaches the position of the violation in the source file
+func locateViolation(file string, locate Y.Locator) func(Violation) Violation {
+	return func(v Violation) Violation {
+		v.File = file
+		return F.Pipe2(
+			locate(v.Path),
+			O.Map(func(pos Y.Position) Violation {
+				v.Line = pos.Line
+				v.Column = pos.Column
+				return v
+			}),
+			O.GetOrElse(F.Constant(v)),
+		)
+	}
+}
+
+// LocateViolations attaches file, line and column to violations, based on a [Y.Locator] for the source of the contract
+func LocateViolations(file string, locate Y.Locator) func([]Violation) []Violation {
+	return A.Map(locateViolation(file, locate))
+}
+
+// parseSection parses a section of a contract that has been embedded as a YAML string
+func parseSection(raw AnyMap, key string) E.Either[error, AnyMap] {
+	value, ok := raw[key].(string)
+	if !ok {
+		return E.Of[error](raw)
+	}
+	return F.Pipe1(
+		Y.Parse[AnyMap]([]byte(value)),
+		E.Map[error](func(section AnyMap) AnyMap {
+			raw[key] = section
+			return raw
+		}),
+	)
+}
+
+// ParseContractSections parses the `env` and `workload` sections if they are embedded as YAML strings, e.g. via `env: |`
+func ParseContractSections(raw AnyMap) E.Either[error, AnyMap] {
+	return F.Pipe1(
+		parseSection(R.Copy(raw), keyEnv),
+		E.Chain(F.Bind2nd(parseSection, keyWorkload)),
+	)
+}
+
+// ContractViolationsFromYAML validates the YAML source of a contract against the schema. Sections embedded as YAML
+// strings are validated, too. The violations carry their position in the source file.
+//
+// - file is the name of the source file reported with the violations
+func ContractViolationsFromYAML(file string) func([]byte) E.Either[error, []Violation] {
+	return func(data []byte) E.Either[error, []Violation] {
+		return F.Pipe1(
+			E.SequenceT2(
+				F.Pipe3(
+					data,
+					Y.Parse[AnyMap],
+					E.Chain(ParseContractSections),
+					E.Chain(ContractViolations),
+				),
+				Y.Locate(data),
+			),
+			E.Map[error](T.Tupled2(func(vs []Violation, locate Y.Locator) []Violation {
+				return LocateViolations(file, locate)(vs)
+			})),
+		)
+	}
+}
+
+// String returns a one line representation of the violation, prefixed by its location if known
+func (v Violation) String() string {
+	msg := fmt.Sprintf("%s: [%s] %s", v.Path, v.Keyword, v.Message)
+	if v.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", v.File, v.Line, v.Column, msg)
+	}
+	if v.File != "" {
+		return fmt.Sprintf("%s: %s", v.File, msg)
+	}
+	return msg
+}
+
+// Error implements the error interface, the message lists all violations
+func (vs Violations) Error() string {
+	return fmt.Sprintf("the contract has %d schema violation(s): %s", len(vs), strings.Join(A.Map(Violation.String)(vs), "; "))
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContractViolations(t *testing.T) {
+	raw := AnyMap{
+		"env": AnyMap{
+			"type":    "environment",
+			"logging": AnyMap{},
+		},
+		"workload": AnyMap{
+			"type": "workload",
+			"compose": AnyMap{
+				"archive": 42,
+			},
+		},
+	}
+
+	violations, err := E.UnwrapError(ContractViolations(raw))
+	require.NoError(t, err)
+
+	// all violations are reported, not just the first one
+	assert.Equal(t, []Violation{
+		{Path: "/env/type", Keyword: "const", Message: `must equal "env"`},
+		{Path: "/workload/compose/archive", Keyword: "type", Message: "type should be string, got integer"},
+	}, violations)
+}
+
+func TestContractViolationsValid(t *testing.T) {
+	raw := AnyMap{
+		"env": AnyMap{
+			"type":    "env",
+			"logging": AnyMap{},
+		},
+		"workload": AnyMap{
+			"type": "workload",
+			"compose": AnyMap{
+				"archive": "MA==",
+			},
+		},
+	}
+
+	violations, err := E.UnwrapError(ContractViolations(raw))
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestKeywordFromMessage(t *testing.T) {
+	assert.Equal(t, "required", keywordFromMessage(`"type" value is required`))
+	assert.Equal(t, "oneOf", keywordFromMessage("did not match any of the specified OneOf schemas"))
+	assert.Equal(t, "anyOf", keywordFromMessage("did Not match any specified AnyOf schemas"))
+	assert.Equal(t, "minimum", keywordFromMessage("must be greater than or equal to 1"))
+	assert.Equal(t, "exclusiveMinimum", keywordFromMessage("must be greater than 1"))
+	assert.Equal(t, KeywordUnknown, keywordFromMessage("something unexpected"))
+}
+
+func TestValidateContractReportsAll(t *testing.T) {
+	raw := AnyMap{
+		"env": AnyMap{
+			"type": "environment",
+		},
+		"workload": AnyMap{
+			"type": "load",
+		},
+	}
+
+	_, err := E.UnwrapError(ValidateContract(raw))
+	require.Error(t, err)
+
+	var violations Violations
+	require.ErrorAs(t, err, &violations)
+	assert.GreaterOrEqual(t, len(violations), 2)
+}
+
+func TestContractViolationsFromYAML(t *testing.T) {
+	data := []byte(`env: |
+  type: environment
+  logging: {}
+workload:
+  type: workload
+  compose:
+    archive: 42
+`)
+
+	violations, err := E.UnwrapError(ContractViolationsFromYAML("contract.yaml")(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Violation{
+		{Path: "/env/type", Keyword: "const", Message: `must equal "env"`, File: "contract.yaml", Line: 2, Column: 3},
+		{Path: "/workload/compose/archive", Keyword: "type", Message: "type should be string, got integer", File: "contract.yaml", Line: 7, Column: 5},
+	}, violations)
+	assert.Equal(t, `contract.yaml:2:3: /env/type: [const] must equal "env"`, violations[0].String())
+}
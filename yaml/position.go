@@ -0,0 +1,140 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	E "github.com/IBM/fp-go/either"
+	O "github.com/IBM/fp-go/option"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Position is a 1-based line and column in a YAML document
+	Position struct {
+		Line   int `json:"line" yaml:"line"`
+		Column int `json:"column" yaml:"column"`
+	}
+
+	// Locator resolves a JSON pointer into the position of the corresponding node in the YAML document. Pointers
+	// without an exact match resolve to the position of their closest ancestor
+	Locator = func(pointer string) O.Option[Position]
+
+	// offset translates positions of a nested document into positions of the outer document
+	offset struct {
+		line   int
+		column int
+	}
+)
+
+// pointerEscaper escapes a key according to RFC 6901
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// translate maps the position of a node into the outer document
+func (o offset) translate(node *yaml.Node) Position {
+	return Position{
+		Line:   node.Line + o.line,
+		Column: node.Column + o.column,
+	}
+}
+
+// lines splits the document into lines, without line terminators
+func lines(data []byte) []string {
+	return strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+}
+
+// indentation returns the number of leading spaces of the first non blank line following the given 1-based line
+func indentation(src []string, line int) O.Option[int] {
+	for idx := line; idx < len(src); idx++ {
+		trimmed := strings.TrimLeft(src[idx], " ")
+		if len(trimmed) > 0 {
+			return O.Of(len(src[idx]) - len(trimmed))
+		}
+	}
+	return O.None[int]()
+}
+
+// locator collects the positions of all nodes of a document
+type locator struct {
+	positions map[string]Position
+}
+
+// nested walks a literal block scalar that holds a YAML mapping (e.g. the `env: |` section of a contract). Lines of a
+// literal block are kept verbatim, so the positions in the nested document translate into the outer document by the
+// line of the block indicator and the indentation of the block.
+func (l *locator) nested(src []string, pointer string, node *yaml.Node, off offset) {
+	if node.Kind != yaml.ScalarNode || node.Style != yaml.LiteralStyle || node.ShortTag() != "!!str" {
+		return
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(node.Value), &doc); err != nil || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return
+	}
+	indent, ok := O.Unwrap(indentation(src, node.Line+off.line))
+	if !ok {
+		return
+	}
+	l.walk(src, pointer, doc.Content[0], offset{line: node.Line + off.line, column: indent})
+}
+
+// walk records the position of each node, values of mappings are located at their key
+func (l *locator) walk(src []string, pointer string, node *yaml.Node, off offset) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			l.walk(src, pointer, child, off)
+		}
+	case yaml.MappingNode:
+		for idx := 0; idx+1 < len(node.Content); idx += 2 {
+			key, value := node.Content[idx], node.Content[idx+1]
+			child := fmt.Sprintf("%s/%s", pointer, pointerEscaper.Replace(key.Value))
+			l.positions[child] = off.translate(key)
+			l.walk(src, child, value, off)
+		}
+	case yaml.SequenceNode:
+		for idx, value := range node.Content {
+			child := fmt.Sprintf("%s/%d", pointer, idx)
+			l.positions[child] = off.translate(value)
+			l.walk(src, child, value, off)
+		}
+	case yaml.ScalarNode:
+		l.nested(src, pointer, node, off)
+	}
+}
+
+// locate resolves a pointer to its position or the position of its closest ancestor, a missing leading `/` is added
+func (l *locator) locate(pointer string) O.Option[Position] {
+	for current := "/" + strings.Trim(pointer, "/"); len(current) > 0; current = current[:strings.LastIndex(current, "/")] {
+		if pos, ok := l.positions[current]; ok {
+			return O.Of(pos)
+		}
+	}
+	return O.None[Position]()
+}
+
+// Locate parses a YAML document and returns a [Locator] for its nodes. Mappings that are embedded as literal block
+// strings are resolved as well, their positions refer to the outer document.
+func Locate(data []byte) E.Either[error, Locator] {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+		return E.Left[Locator](err)
+	}
+	l := &locator{positions: make(map[string]Position)}
+	l.walk(lines(data), "", &doc, offset{})
+	return E.Of[error](l.locate)
+}
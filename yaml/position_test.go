@@ -0,0 +1,60 @@
+// Copyright 2023 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yaml
+
+import (
+	"testing"
+
+	E "github.com/IBM/fp-go/either"
+	O "github.com/IBM/fp-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const nestedContract = `env: |
+  type: env
+  logging:
+    logDNA:
+      hostname: example.com
+workload:
+  type: workload
+  compose:
+    archive: 42
+  images:
+    - a
+    - b
+`
+
+func TestLocate(t *testing.T) {
+	locate, err := E.UnwrapError(Locate([]byte(nestedContract)))
+	require.NoError(t, err)
+
+	// plain mappings
+	assert.Equal(t, O.Of(Position{Line: 6, Column: 1}), locate("/workload"))
+	assert.Equal(t, O.Of(Position{Line: 9, Column: 5}), locate("/workload/compose/archive"))
+	// sequences
+	assert.Equal(t, O.Of(Position{Line: 12, Column: 7}), locate("/workload/images/1"))
+	// nested literal block, positions refer to the outer document
+	assert.Equal(t, O.Of(Position{Line: 2, Column: 3}), locate("/env/type"))
+	assert.Equal(t, O.Of(Position{Line: 5, Column: 7}), locate("/env/logging/logDNA/hostname"))
+	// unknown pointers resolve to the closest ancestor
+	assert.Equal(t, O.Of(Position{Line: 4, Column: 5}), locate("/env/logging/logDNA/port"))
+	assert.Equal(t, O.None[Position](), locate("/"))
+	// pointers without a leading slash are relative to the root
+	assert.Equal(t, O.Of(Position{Line: 2, Column: 3}), locate("env/type"))
+	assert.Equal(t, O.Of(Position{Line: 6, Column: 1}), locate("workload"))
+	assert.Equal(t, O.None[Position](), locate(""))
+	assert.Equal(t, O.None[Position](), locate("unknown"))
+}